@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"demo/ch01/internal/encode"
+	"github.com/spf13/cobra"
+)
+
+var isDecode bool
+var base64Variant string
+var hashAlgo string
+var hmacAlgo string
+var hmacKey string
+var jwtSecret string
+
+var encodeCmd = &cobra.Command{
+	Use:   "encode",
+	Short: "编码与摘要处理",
+	Long:  "编码与摘要处理，待处理内容依次从 --str、--file 与标准输入中读取",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+var base64Cmd = &cobra.Command{
+	Use:   "base64",
+	Short: "base64 编码/解码",
+	Long:  "base64 编码/解码，支持 std、url、raw、rawurl 四种编码类型",
+	Run: func(cmd *cobra.Command, args []string) {
+		if isDecode {
+			content, err := readTextInput(str, inputFile)
+			if err != nil {
+				log.Fatalf("readTextInput err: %v", err)
+			}
+			data, err := encode.Base64Decode(base64Variant, content)
+			if err != nil {
+				log.Fatalf("encode.Base64Decode err: %v", err)
+			}
+			_, _ = os.Stdout.Write(data)
+			return
+		}
+
+		content, err := readInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readInput err: %v", err)
+		}
+		result, err := encode.Base64Encode(base64Variant, content)
+		if err != nil {
+			log.Fatalf("encode.Base64Encode err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var hexCmd = &cobra.Command{
+	Use:   "hex",
+	Short: "十六进制编码/解码",
+	Long:  "十六进制编码/解码",
+	Run: func(cmd *cobra.Command, args []string) {
+		if isDecode {
+			content, err := readTextInput(str, inputFile)
+			if err != nil {
+				log.Fatalf("readTextInput err: %v", err)
+			}
+			data, err := encode.HexDecode(content)
+			if err != nil {
+				log.Fatalf("encode.HexDecode err: %v", err)
+			}
+			_, _ = os.Stdout.Write(data)
+			return
+		}
+
+		content, err := readInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readInput err: %v", err)
+		}
+		fmt.Println(encode.HexEncode(content))
+	},
+}
+
+var urlCmd = &cobra.Command{
+	Use:   "url",
+	Short: "URL 查询参数转义/反转义",
+	Long:  "URL 查询参数转义/反转义",
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := readTextInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readTextInput err: %v", err)
+		}
+		if !isDecode {
+			fmt.Println(encode.URLEscape(content))
+			return
+		}
+		result, err := encode.URLUnescape(content)
+		if err != nil {
+			log.Fatalf("encode.URLUnescape err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var unicodeCmd = &cobra.Command{
+	Use:   "unicode",
+	Short: "unicode 转义/反转义",
+	Long:  "将非 ASCII 字符转义为 \\uXXXX 形式，或将其还原",
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := readTextInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readTextInput err: %v", err)
+		}
+		if !isDecode {
+			fmt.Println(encode.UnicodeEscape(content))
+			return
+		}
+		result, err := encode.UnicodeUnescape(content)
+		if err != nil {
+			log.Fatalf("encode.UnicodeUnescape err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var hashCmd = &cobra.Command{
+	Use:   "hash",
+	Short: "计算摘要",
+	Long:  "计算 md5、sha1、sha256、sha512 摘要",
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := openInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("openInput err: %v", err)
+		}
+		defer r.Close()
+		result, err := encode.Digest(hashAlgo, r)
+		if err != nil {
+			log.Fatalf("encode.Digest err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var hmacCmd = &cobra.Command{
+	Use:   "hmac",
+	Short: "计算 HMAC 摘要",
+	Long:  "使用指定的密钥计算 HMAC 摘要",
+	Run: func(cmd *cobra.Command, args []string) {
+		if hmacKey == "" {
+			log.Fatalf("请通过 --key 指定 HMAC 密钥")
+		}
+		r, err := openInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("openInput err: %v", err)
+		}
+		defer r.Close()
+		result, err := encode.HMAC(hmacAlgo, []byte(hmacKey), r)
+		if err != nil {
+			log.Fatalf("encode.HMAC err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var jwtCmd = &cobra.Command{
+	Use:   "jwt",
+	Short: "JWT 解码",
+	Long:  "解码 JWT 的 header 与 payload，指定 --secret 时校验 HS256/HS384/HS512 签名",
+	Run: func(cmd *cobra.Command, args []string) {
+		token, err := readTextInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readTextInput err: %v", err)
+		}
+		result, err := encode.DecodeJWT(token, []byte(jwtSecret))
+		if result == nil {
+			log.Fatalf("encode.DecodeJWT err: %v", err)
+		}
+		content, _ := json.MarshalIndent(result, "", "    ")
+		fmt.Println(string(content))
+		if err != nil {
+			log.Fatalf("encode.DecodeJWT err: %v", err)
+		}
+		if result.Verified != nil && !*result.Verified {
+			log.Fatalf("签名校验失败")
+		}
+	},
+}
+
+func init() {
+	encodeCmd.AddCommand(base64Cmd)
+	encodeCmd.AddCommand(hexCmd)
+	encodeCmd.AddCommand(urlCmd)
+	encodeCmd.AddCommand(unicodeCmd)
+	encodeCmd.AddCommand(hashCmd)
+	encodeCmd.AddCommand(hmacCmd)
+	encodeCmd.AddCommand(jwtCmd)
+
+	// 所有子命令共用的输入参数
+	encodeCmd.PersistentFlags().StringVarP(&str, "str", "s", "", "请输入待处理的内容")
+	encodeCmd.PersistentFlags().StringVarP(&inputFile, "file", "f", "", "请输入待处理的文件路径，为 - 时读取标准输入")
+
+	for _, c := range []*cobra.Command{base64Cmd, hexCmd, urlCmd, unicodeCmd} {
+		c.Flags().BoolVarP(&isDecode, "decode", "d", false, "进行解码")
+	}
+	base64Cmd.Flags().StringVarP(&base64Variant, "type", "t", encode.Base64Std, "请输入 base64 编码类型：std、url、raw、rawurl")
+	hashCmd.Flags().StringVarP(&hashAlgo, "algo", "a", encode.AlgoMD5, "请输入摘要算法：md5、sha1、sha256、sha512")
+	hmacCmd.Flags().StringVarP(&hmacAlgo, "algo", "a", encode.AlgoSHA256, "请输入摘要算法：md5、sha1、sha256、sha512")
+	hmacCmd.Flags().StringVarP(&hmacKey, "key", "k", "", "请输入 HMAC 密钥")
+	jwtCmd.Flags().StringVarP(&jwtSecret, "secret", "", "", "请输入用于校验签名的密钥")
+}
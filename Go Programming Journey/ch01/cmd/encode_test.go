@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"demo/ch01/internal/encode"
+)
+
+// hash 与 hmac 的 --algo 默认值不同，两者不能共用同一个变量
+func TestEncodeAlgoDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		algo *string
+		want string
+	}{
+		{"hash", &hashAlgo, encode.AlgoMD5},
+		{"hmac", &hmacAlgo, encode.AlgoSHA256},
+	}
+	for _, tt := range tests {
+		if *tt.algo != tt.want {
+			t.Errorf("%s --algo default = %q, want %q", tt.name, *tt.algo, tt.want)
+		}
+	}
+	if got := hashCmd.Flags().Lookup("algo").DefValue; got != encode.AlgoMD5 {
+		t.Errorf("hash --algo DefValue = %q, want %q", got, encode.AlgoMD5)
+	}
+}
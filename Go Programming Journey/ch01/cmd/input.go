@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// 输入文件路径，为 - 时读取标准输入
+var inputFile string
+
+// 按 --str > --file > 标准输入 的顺序获取待处理的内容
+func readInput(s, file string) ([]byte, error) {
+	if s != "" {
+		return []byte(s), nil
+	}
+	if file != "" && file != "-" {
+		return ioutil.ReadFile(file)
+	}
+	return ioutil.ReadAll(os.Stdin)
+}
+
+// 以流的方式打开输入，用于计算较大文件的摘要
+func openInput(s, file string) (io.ReadCloser, error) {
+	if s != "" {
+		return ioutil.NopCloser(strings.NewReader(s)), nil
+	}
+	if file != "" && file != "-" {
+		return os.Open(file)
+	}
+	return ioutil.NopCloser(os.Stdin), nil
+}
+
+// 读取文本输入并去除末尾的换行，e.g. echo 输出的内容
+func readTextInput(s, file string) (string, error) {
+	content, err := readInput(s, file)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(content, "\r\n")), nil
+}
@@ -2,10 +2,17 @@ package cmd
 
 import (
 	"demo/ch01/internal/json2struct"
+	"demo/ch01/internal/jsonutil"
+	"fmt"
 	"github.com/spf13/cobra"
+	"io/ioutil"
 	"log"
+	"os"
+	"strings"
 )
 
+var indent int
+
 var jsonCmd = &cobra.Command{
 	Use:   "json",
 	Short: "json转换和处理",
@@ -28,8 +35,105 @@ var json2structCmd = &cobra.Command{
 	},
 }
 
+var jsonFmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "json格式化",
+	Long:  "json格式化，保留原有的字段顺序",
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := readInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readInput err: %v", err)
+		}
+		result, err := jsonutil.Format(content, strings.Repeat(" ", indent))
+		if err != nil {
+			log.Fatalf("jsonutil.Format err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var jsonMinCmd = &cobra.Command{
+	Use:   "min",
+	Short: "json压缩",
+	Long:  "json压缩，去除所有无意义的空白字符",
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := readInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readInput err: %v", err)
+		}
+		result, err := jsonutil.Minify(content)
+		if err != nil {
+			log.Fatalf("jsonutil.Minify err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var jsonGetCmd = &cobra.Command{
+	Use:   "get <path>",
+	Short: "json路径查询",
+	Long:  "json路径查询，路径以 . 分隔，数组下标直接使用数字，e.g. json get 'list.0.name'",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := readInput(str, inputFile)
+		if err != nil {
+			log.Fatalf("readInput err: %v", err)
+		}
+		value, err := jsonutil.Get(content, args[0])
+		if err != nil {
+			log.Fatalf("jsonutil.Get err: %v", err)
+		}
+		result, err := jsonutil.Stringify(value, strings.Repeat(" ", indent))
+		if err != nil {
+			log.Fatalf("jsonutil.Stringify err: %v", err)
+		}
+		fmt.Println(result)
+	},
+}
+
+var jsonDiffCmd = &cobra.Command{
+	Use:   "diff <a.json> <b.json>",
+	Short: "json差异比较",
+	Long:  "json语义差异比较，对象不区分字段顺序，数字按数值比较，存在差异时退出码为 1",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		a, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			log.Fatalf("ioutil.ReadFile err: %v", err)
+		}
+		b, err := ioutil.ReadFile(args[1])
+		if err != nil {
+			log.Fatalf("ioutil.ReadFile err: %v", err)
+		}
+		diffs, err := jsonutil.Diff(a, b)
+		if err != nil {
+			log.Fatalf("jsonutil.Diff err: %v", err)
+		}
+		if len(diffs) == 0 {
+			log.Printf("输出结果: 无差异")
+			return
+		}
+		for _, d := range diffs {
+			fmt.Println(d.String())
+		}
+		os.Exit(1)
+	},
+}
+
 func init() {
 	// 为 json 配置子命令
 	jsonCmd.AddCommand(json2structCmd)
+	jsonCmd.AddCommand(jsonFmtCmd)
+	jsonCmd.AddCommand(jsonMinCmd)
+	jsonCmd.AddCommand(jsonGetCmd)
+	jsonCmd.AddCommand(jsonDiffCmd)
 	json2structCmd.Flags().StringVarP(&str, "str", "s", "", "请输入json字符串")
+
+	// fmt、min、get 依次从 --str、--file 与标准输入中读取 json
+	for _, c := range []*cobra.Command{jsonFmtCmd, jsonMinCmd, jsonGetCmd} {
+		c.Flags().StringVarP(&str, "str", "s", "", "请输入json字符串")
+		c.Flags().StringVarP(&inputFile, "file", "f", "", "请输入json文件路径，为 - 时读取标准输入")
+	}
+	jsonFmtCmd.Flags().IntVarP(&indent, "indent", "i", 4, "请输入缩进的空格数")
+	jsonGetCmd.Flags().IntVarP(&indent, "indent", "i", 4, "请输入缩进的空格数")
 }
@@ -2,6 +2,9 @@ package cmd
 
 import "github.com/spf13/cobra"
 
+// 配置文件路径，默认为 ~/.tour.yaml
+var configFile string
+
 var rootCmd = &cobra.Command{}
 
 func Execute() error {
@@ -15,4 +18,9 @@ func init() {
 
 	rootCmd.AddCommand(jsonCmd)
 	rootCmd.AddCommand(sqlCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(encodeCmd)
+
+	// 全局参数，所有子命令均可使用
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "", "", "请输入配置文件路径(默认为 ~/.tour.yaml)")
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"log"
+	"net/http"
+
+	"demo/ch01/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var addr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "以 HTTP 接口与网页的形式提供各项转换工具",
+	Long:  "以 HTTP 接口与网页的形式提供 word、time、json struct 与 sql struct(DDL 模式) 转换",
+	Run: func(cmd *cobra.Command, args []string) {
+		s := server.NewServer(addr)
+		log.Printf("服务已启动，请访问 http://%s", displayAddr(addr))
+		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("s.ListenAndServe err: %v", err)
+		}
+	},
+}
+
+// 监听地址省略主机时，展示为本地地址
+func displayAddr(addr string) string {
+	if len(addr) > 0 && addr[0] == ':' {
+		return "127.0.0.1" + addr
+	}
+	return addr
+}
+
+func init() {
+	serveCmd.Flags().StringVarP(&addr, "addr", "a", ":8080", "请输入服务监听地址")
+}
@@ -3,12 +3,13 @@ package cmd
 import (
 	"log"
 
+	"demo/ch01/internal/config"
 	"demo/ch01/internal/sql2struct"
 	"github.com/spf13/cobra"
 )
 
 // cmd 全局变量，用于结构外部的命令行参数
-// 分别对应用户名、密码、主机地址、编码类型、数据库类型、数据库名称和表名称
+// 分别对应用户名、密码、主机地址、编码类型、数据库类型、数据库名称、表名称和配置文件中的 profile 名称
 var username string
 var password string
 var host string
@@ -16,6 +17,7 @@ var charset string
 var dbType string
 var dbName string
 var tableName string
+var profileName string
 
 // 声明 sql 子命令
 var sqlCmd = &cobra.Command{
@@ -31,22 +33,26 @@ var sql2structCmd = &cobra.Command{
 	Short: "sql转换",
 	Long:  "sql转换",
 	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := loadSQLProfile(cmd)
+		if err != nil {
+			log.Fatalf("loadSQLProfile err: %v", err)
+		}
 		dbInfo := &sql2struct.DBInfo{
-			DBType:   dbType,
-			Host:     host,
-			UserName: username,
-			Password: password,
-			Charset:  charset,
+			DBType:   profile.Type,
+			Host:     profile.Host,
+			UserName: profile.Username,
+			Password: profile.Password,
+			Charset:  profile.Charset,
 		}
 		dbModel := sql2struct.NewDBModel(dbInfo)
 
 		// 连接数据库
-		err := dbModel.Connect()
+		err = dbModel.Connect()
 		if err != nil {
 			log.Fatalf("dbModel.Connect err: %v", err)
 		}
 		// 查询 COLUMNS 表信息
-		columns, err := dbModel.GetColumns(dbName, tableName)
+		columns, err := dbModel.GetColumns(profile.DB, tableName)
 		if err != nil {
 			log.Fatalf("dbModel.GetColumns err: %v", err)
 		}
@@ -61,15 +67,61 @@ var sql2structCmd = &cobra.Command{
 	},
 }
 
+// 按 命令行参数 > 环境变量 > 配置文件 profile > 内置默认值 的优先级确定连接参数
+// 若最终仍未得到密码，则在终端中交互式输入
+func loadSQLProfile(cmd *cobra.Command) (*config.Profile, error) {
+	c, err := config.Load(configFile)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := c.Profile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	profile.ApplyEnv()
+
+	// 仅覆盖显式传入的命令行参数
+	flags := cmd.Flags()
+	if flags.Changed("type") {
+		profile.Type = dbType
+	}
+	if flags.Changed("host") {
+		profile.Host = host
+	}
+	if flags.Changed("username") {
+		profile.Username = username
+	}
+	if flags.Changed("password") {
+		profile.Password = password
+	}
+	if flags.Changed("charset") {
+		profile.Charset = charset
+	}
+	if flags.Changed("db") {
+		profile.DB = dbName
+	}
+
+	if profile.Password == "" {
+		profile.Password, err = config.PromptPassword("请输入数据库 " + profile.Username + "@" + profile.Host + " 的密码: ")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return profile, nil
+}
+
 // 进行默认的 cmd 初始化动作和命令行参数的绑定
 func init() {
 	sqlCmd.AddCommand(sql2structCmd)
-	// 绑定子命令以便设置 Mysql 连接参数
-	sql2structCmd.Flags().StringVarP(&username, "username", "", "root", "请输入数据库的账号")
-	sql2structCmd.Flags().StringVarP(&password, "password", "", "root", "请输入数据库的密码")
-	sql2structCmd.Flags().StringVarP(&host, "host", "", "127.0.0.1:3306", "请输入数据库的HOST")
-	sql2structCmd.Flags().StringVarP(&charset, "charset", "", "utf8mb4", "请输入数据库的编码")
-	sql2structCmd.Flags().StringVarP(&dbType, "type", "", "mysql", "请输入数据库实例类型")
-	sql2structCmd.Flags().StringVarP(&dbName, "db", "", "test", "请输入数据库名称")
+	// 绑定子命令以便设置 Mysql 连接参数，未传入时使用配置文件中的值
+	sql2structCmd.Flags().StringVarP(&profileName, "profile", "", "", "请输入配置文件中的 profile 名称")
+	sql2structCmd.Flags().StringVarP(&username, "username", "", "", "请输入数据库的账号(默认为 root)")
+	sql2structCmd.Flags().StringVarP(&password, "password", "", "", "请输入数据库的密码")
+	sql2structCmd.Flags().StringVarP(&host, "host", "", "", "请输入数据库的HOST(默认为 127.0.0.1:3306)")
+	sql2structCmd.Flags().StringVarP(&charset, "charset", "", "", "请输入数据库的编码(默认为 utf8mb4)")
+	sql2structCmd.Flags().StringVarP(&dbType, "type", "", "", "请输入数据库实例类型(默认为 mysql)")
+	sql2structCmd.Flags().StringVarP(&dbName, "db", "", "", "请输入数据库名称(默认为 test)")
 	sql2structCmd.Flags().StringVarP(&tableName, "table", "", "test", "请输入表名称")
+	// 明文密码会留在 shell 历史中，保留该参数以兼容旧用法，但不再推荐使用
+	_ = sql2structCmd.Flags().MarkDeprecated("password", "请改用配置文件、"+config.EnvPrefix+"PASSWORD 环境变量或交互输入")
 }
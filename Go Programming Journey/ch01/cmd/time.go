@@ -5,9 +5,6 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"log"
-	"strconv"
-	"strings"
-	"time"
 )
 
 var calculateTime string
@@ -27,7 +24,7 @@ var nowTimeCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		nowTime := timer.GetNowTime()
 		// nowTime.Format()第一个参数为时间标准格式化，第二个参数为时间戳
-		log.Printf("输出结果: %s, %d", nowTime.Format("2006-01-02 15:04:05"), nowTime.Unix())
+		log.Printf("输出结果: %s, %d", nowTime.Format(timer.DefaultLayout), nowTime.Unix())
 	},
 }
 
@@ -36,25 +33,10 @@ var calculateTimeCmd = &cobra.Command{
 	Short: "计算所需时间",
 	Long:  "计算所需时间",
 	Run: func(cmd *cobra.Command, args []string) {
-		var currentTimer time.Time
-		var layout = "2006-01-02 15:04:05"
 		fmt.Println(calculateTime)
-		if calculateTime == "" {
-			currentTimer = timer.GetNowTime()
-		} else {
-			var err error
-			space := strings.Count(calculateTime, " ")
-			if space == 0 {
-				layout = "2006-01-02"
-			}
-			if space == 1 {
-				layout = "2006-01-02 15:04:05"
-			}
-			currentTimer, err = time.Parse(layout, calculateTime)
-			if err != nil {
-				t, _ := strconv.Atoi(calculateTime)
-				currentTimer = time.Unix(int64(t), 0)
-			}
+		currentTimer, layout, err := timer.ParseCalculateTime(calculateTime)
+		if err != nil {
+			log.Fatalf("timer.ParseCalculateTime err: %v", err)
 		}
 		t, err := timer.GetCalculateTime(currentTimer, duration)
 		if err != nil {
@@ -7,14 +7,6 @@ import (
 	"strings"
 )
 
-const (
-	ModeUpper                      = iota + 1 // 全部转大写
-	ModeLower                                 // 全部转小写
-	ModeUnderscoreToUpperCamelCase            // 下划线转大写驼峰
-	ModeUnderscoreToLowerCamelCase            // 下线线转小写驼峰
-	ModeCamelCaseToUnderscore                 // 驼峰转下划线
-)
-
 var desc = strings.Join([]string{
 	"该子命令支持各种单词格式转换，模式如下：",
 	"1：全部转大写",
@@ -33,19 +25,8 @@ var wordCmd = &cobra.Command{
 	Long: desc,
 	// 根据模式转换字符串
 	Run: func(cmd *cobra.Command, args []string) {
-		var content string
-		switch mode {
-		case ModeUpper:
-			content = word.ToUpper(str)
-		case ModeLower:
-			content = word.ToLower(str)
-		case ModeUnderscoreToUpperCamelCase:
-			content = word.UnderscoreToUpperCamelCase(str)
-		case ModeUnderscoreToLowerCamelCase:
-			content = word.UnderscoreToLowerCamelCase(str)
-		case ModeCamelCaseToUnderscore:
-			content = word.CamelCaseToUnderscore(str)
-		default:
+		content, err := word.Convert(mode, str)
+		if err != nil {
 			log.Fatalf("暂不支持该转换模式，请执行 help word 查看帮助文档")
 		}
 		log.Printf("输出结果: %s", content)
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 默认配置文件名称，位于用户主目录下
+const DefaultFileName = ".tour.yaml"
+
+// 环境变量前缀，e.g. TOUR_SQL_PASSWORD
+const EnvPrefix = "TOUR_SQL_"
+
+// 未指定 profile 时使用的名称
+const DefaultProfileName = "default"
+
+// Config 对应 ~/.tour.yaml 的整体结构
+/*
+	default_profile: local
+	profiles:
+	  local:
+	    username: root
+	    host: 127.0.0.1:3306
+	  staging:
+	    username: reader
+	    password: xxx
+	    host: 10.0.0.8:3306
+*/
+type Config struct {
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile 存储一组数据库连接参数
+type Profile struct {
+	Type     string `yaml:"type"`
+	Host     string `yaml:"host"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Charset  string `yaml:"charset"`
+	DB       string `yaml:"db"`
+}
+
+// 内置的默认连接参数，密码不再提供默认值
+var defaultProfile = Profile{
+	Type:     "mysql",
+	Host:     "127.0.0.1:3306",
+	Username: "root",
+	Charset:  "utf8mb4",
+	DB:       "test",
+}
+
+// 返回默认配置文件路径 ~/.tour.yaml
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return DefaultFileName
+	}
+	return filepath.Join(home, DefaultFileName)
+}
+
+// 读取配置文件，path 为空时读取默认路径
+// 默认路径下的配置文件不存在时返回空配置，显式指定的路径不存在时返回错误
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	return &c, nil
+}
+
+// 获取指定名称的 profile，name 为空时依次使用 default_profile 与 default
+// 未配置任何 profile 时返回内置默认值
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+
+	p := defaultProfile
+	if profile, ok := c.Profiles[name]; ok {
+		p.merge(profile)
+		return &p, nil
+	}
+	// 仅在使用隐式的 default 时允许 profile 缺失
+	if name == DefaultProfileName && name != c.DefaultProfile {
+		return &p, nil
+	}
+	return nil, fmt.Errorf("profile %q 不存在，可用的 profile: %s", name, strings.Join(c.profileNames(), ", "))
+}
+
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 使用环境变量覆盖 profile 中的值，e.g. TOUR_SQL_HOST、TOUR_SQL_PASSWORD
+func (p *Profile) ApplyEnv() {
+	p.merge(&Profile{
+		Type:     os.Getenv(EnvPrefix + "TYPE"),
+		Host:     os.Getenv(EnvPrefix + "HOST"),
+		Username: os.Getenv(EnvPrefix + "USERNAME"),
+		Password: os.Getenv(EnvPrefix + "PASSWORD"),
+		Charset:  os.Getenv(EnvPrefix + "CHARSET"),
+		DB:       os.Getenv(EnvPrefix + "DB"),
+	})
+}
+
+// 将 o 中的非空值覆盖到 p 中
+func (p *Profile) merge(o *Profile) {
+	if o.Type != "" {
+		p.Type = o.Type
+	}
+	if o.Host != "" {
+		p.Host = o.Host
+	}
+	if o.Username != "" {
+		p.Username = o.Username
+	}
+	if o.Password != "" {
+		p.Password = o.Password
+	}
+	if o.Charset != "" {
+		p.Charset = o.Charset
+	}
+	if o.DB != "" {
+		p.DB = o.DB
+	}
+}
+
+var ErrNoPassword = errors.New("未提供数据库密码，请在配置文件、" + EnvPrefix + "PASSWORD 环境变量中设置或在终端中交互输入")
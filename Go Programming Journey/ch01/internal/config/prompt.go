@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// 在终端中交互式读取密码，输入内容不回显，也不会留在 shell 历史中
+// 标准输入不是终端时（如管道、CI 环境）返回 ErrNoPassword
+func PromptPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", ErrNoPassword
+	}
+
+	// 提示信息写入 stderr，避免污染 stdout 中的转换结果
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
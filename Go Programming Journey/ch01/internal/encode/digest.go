@@ -0,0 +1,62 @@
+package encode
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// 支持的摘要算法
+const (
+	AlgoMD5    = "md5"
+	AlgoSHA1   = "sha1"
+	AlgoSHA256 = "sha256"
+	AlgoSHA512 = "sha512"
+)
+
+func newHash(algo string) (func() hash.Hash, error) {
+	switch algo {
+	case AlgoMD5:
+		return md5.New, nil
+	case AlgoSHA1:
+		return sha1.New, nil
+	case AlgoSHA256:
+		return sha256.New, nil
+	case AlgoSHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("暂不支持该摘要算法: %s", algo)
+}
+
+// 计算 r 中内容的摘要，以十六进制字符串返回
+// 以流的方式读取，可用于较大的文件
+// 对字符串计算 md5 时与 ch02 中的 util.EncodeMD5 结果一致
+func Digest(algo string, r io.Reader) (string, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return "", err
+	}
+	return sum(h(), r)
+}
+
+// 使用 key 计算 r 中内容的 HMAC 摘要
+func HMAC(algo string, key []byte, r io.Reader) (string, error) {
+	h, err := newHash(algo)
+	if err != nil {
+		return "", err
+	}
+	return sum(hmac.New(h, key), r)
+}
+
+func sum(h hash.Hash, r io.Reader) (string, error) {
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,123 @@
+package encode
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// base64 编码的变体
+const (
+	Base64Std    = "std"    // 标准编码，带填充
+	Base64URL    = "url"    // URL 安全编码，带填充
+	Base64Raw    = "raw"    // 标准编码，不带填充
+	Base64RawURL = "rawurl" // URL 安全编码，不带填充，常见于 JWT
+)
+
+func base64Encoding(variant string) (*base64.Encoding, error) {
+	switch variant {
+	case Base64Std, "":
+		return base64.StdEncoding, nil
+	case Base64URL:
+		return base64.URLEncoding, nil
+	case Base64Raw:
+		return base64.RawStdEncoding, nil
+	case Base64RawURL:
+		return base64.RawURLEncoding, nil
+	}
+	return nil, fmt.Errorf("暂不支持该 base64 编码类型: %s", variant)
+}
+
+func Base64Encode(variant string, data []byte) (string, error) {
+	enc, err := base64Encoding(variant)
+	if err != nil {
+		return "", err
+	}
+	return enc.EncodeToString(data), nil
+}
+
+func Base64Decode(variant string, s string) ([]byte, error) {
+	enc, err := base64Encoding(variant)
+	if err != nil {
+		return nil, err
+	}
+	return enc.DecodeString(strings.TrimSpace(s))
+}
+
+func HexEncode(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+func HexDecode(s string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimSpace(s))
+}
+
+// URL 查询参数转义，空格转义为 +
+func URLEscape(s string) string {
+	return url.QueryEscape(s)
+}
+
+func URLUnescape(s string) (string, error) {
+	return url.QueryUnescape(s)
+}
+
+// 将非 ASCII 字符转义为 \uXXXX，超出 BMP 的字符使用 UTF-16 代理对表示，与 JSON、Java 中的写法一致
+func UnicodeEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			continue
+		}
+		if r > 0xFFFF {
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&b, "\\u%04x\\u%04x", r1, r2)
+			continue
+		}
+		fmt.Fprintf(&b, "\\u%04x", r)
+	}
+	return b.String()
+}
+
+// 将 \uXXXX 与 \UXXXXXXXX 还原为对应字符，其余内容保持不变
+func UnicodeUnescape(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '\\' || i+1 >= len(s) || (s[i+1] != 'u' && s[i+1] != 'U') {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		size := 4
+		if s[i+1] == 'U' {
+			size = 8
+		}
+		if i+2+size > len(s) {
+			return "", fmt.Errorf("不完整的转义序列: %s", s[i:])
+		}
+		v, err := strconv.ParseUint(s[i+2:i+2+size], 16, 32)
+		if err != nil {
+			return "", fmt.Errorf("无效的转义序列: %s", s[i:i+2+size])
+		}
+		r := rune(v)
+		i += 2 + size
+
+		// 代理对需要与下一个 \uXXXX 组合成一个字符
+		if utf16.IsSurrogate(r) && i+6 <= len(s) && s[i] == '\\' && s[i+1] == 'u' {
+			if v2, err := strconv.ParseUint(s[i+2:i+6], 16, 32); err == nil {
+				if combined := utf16.DecodeRune(r, rune(v2)); combined != utf8.RuneError {
+					r = combined
+					i += 6
+				}
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
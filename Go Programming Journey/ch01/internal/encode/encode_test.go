@@ -0,0 +1,116 @@
+package encode
+
+import (
+	"testing"
+)
+
+func TestBase64(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant string
+		data    string
+		want    string
+	}{
+		{"std", Base64Std, "go?>", "Z28/Pg=="},
+		{"default", "", "go?>", "Z28/Pg=="},
+		{"url", Base64URL, "go?>", "Z28_Pg=="},
+		{"raw", Base64Raw, "go?>", "Z28/Pg"},
+		{"rawurl", Base64RawURL, "go?>", "Z28_Pg"},
+		{"unicode", Base64Std, "你好", "5L2g5aW9"},
+		{"empty", Base64Std, "", ""},
+	}
+	for _, tt := range tests {
+		got, err := Base64Encode(tt.variant, []byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: Base64Encode err: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Base64Encode = %q, want %q", tt.name, got, tt.want)
+		}
+		// 解码时忽略首尾空白
+		data, err := Base64Decode(tt.variant, " "+tt.want+"\n")
+		if err != nil {
+			t.Errorf("%s: Base64Decode err: %v", tt.name, err)
+			continue
+		}
+		if string(data) != tt.data {
+			t.Errorf("%s: Base64Decode = %q, want %q", tt.name, data, tt.data)
+		}
+	}
+}
+
+func TestBase64Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant string
+		s       string
+	}{
+		{"unknown variant", "base32", "Z28"},
+		{"missing padding", Base64Std, "Z28/Pg"},
+		{"url alphabet in std", Base64Std, "Z28_Pg=="},
+		{"invalid char", Base64RawURL, "Z28*Pg"},
+	}
+	for _, tt := range tests {
+		if _, err := Base64Decode(tt.variant, tt.s); err == nil {
+			t.Errorf("%s: Base64Decode(%q) err = nil, want error", tt.name, tt.s)
+		}
+	}
+	if _, err := Base64Encode("base32", []byte("go")); err == nil {
+		t.Errorf("Base64Encode with unknown variant err = nil, want error")
+	}
+}
+
+func TestUnicodeEscape(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"ascii", "go 1.17", "go 1.17"},
+		{"chinese", "Go 语言", "Go \\u8bed\\u8a00"},
+		{"latin", "café", "caf\\u00e9"},
+		{"surrogate pair", "😀", "\\ud83d\\ude00"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got := UnicodeEscape(tt.s); got != tt.want {
+			t.Errorf("%s: UnicodeEscape(%q) = %q, want %q", tt.name, tt.s, got, tt.want)
+		}
+		got, err := UnicodeUnescape(tt.want)
+		if err != nil {
+			t.Errorf("%s: UnicodeUnescape err: %v", tt.name, err)
+			continue
+		}
+		if got != tt.s {
+			t.Errorf("%s: UnicodeUnescape(%q) = %q, want %q", tt.name, tt.want, got, tt.s)
+		}
+	}
+}
+
+func TestUnicodeUnescape(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"upper case hex", "\\u8BED", "语", false},
+		{"long form", "\\U0001F600", "😀", false},
+		{"other escapes kept", "a\\nb\\\\", "a\\nb\\\\", false},
+		{"trailing backslash", "go\\", "go\\", false},
+		{"lone surrogate", "\\ud83d", "�", false},
+		{"incomplete", "\\u8b", "", true},
+		{"invalid hex", "\\uzzzz", "", true},
+	}
+	for _, tt := range tests {
+		got, err := UnicodeUnescape(tt.s)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: UnicodeUnescape(%q) err = %v, wantErr %v", tt.name, tt.s, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: UnicodeUnescape(%q) = %q, want %q", tt.name, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package encode
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+// JWT 解码结果
+type JWT struct {
+	Header    map[string]interface{} `json:"header"`
+	Claims    map[string]interface{} `json:"claims"`
+	Signature string                 `json:"signature"`
+	// 签名校验结果，未提供密钥时为 nil
+	Verified *bool `json:"verified,omitempty"`
+	// exp、iat、nbf 对应的可读时间
+	Times map[string]string `json:"times,omitempty"`
+	// 根据 exp 判断的是否过期
+	Expired bool `json:"expired"`
+}
+
+// 解码 JWT，secret 不为空时使用 HMAC 算法校验签名
+// 签名不匹配或算法不支持校验时仍会返回解码内容，便于排查问题
+func DecodeJWT(token string, secret []byte) (*JWT, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token 格式错误，应由 header.payload.signature 三部分组成")
+	}
+
+	jwt := &JWT{Signature: parts[2]}
+	if err := decodeSegment(parts[0], &jwt.Header); err != nil {
+		return nil, fmt.Errorf("解码 header 失败: %v", err)
+	}
+	if err := decodeSegment(parts[1], &jwt.Claims); err != nil {
+		return nil, fmt.Errorf("解码 payload 失败: %v", err)
+	}
+
+	jwt.Times = make(map[string]string)
+	for _, key := range []string{"exp", "iat", "nbf"} {
+		if v, ok := jwt.Claims[key].(json.Number); ok {
+			if ts, err := v.Int64(); err == nil {
+				jwt.Times[key] = time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+				if key == "exp" {
+					jwt.Expired = time.Now().Unix() > ts
+				}
+			}
+		}
+	}
+
+	if len(secret) > 0 {
+		alg, _ := jwt.Header["alg"].(string)
+		ok, err := verifyHMAC(alg, parts[0]+"."+parts[1], parts[2], secret)
+		if err != nil {
+			return jwt, err
+		}
+		jwt.Verified = &ok
+	}
+	return jwt, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	// JWT 使用不带填充的 URL 安全 base64 编码
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// 使用 json.Number 避免时间戳被转换为浮点数
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func verifyHMAC(alg, signingString, signature string, secret []byte) (bool, error) {
+	var h func() hash.Hash
+	switch alg {
+	case "HS256":
+		h = sha256.New
+	case "HS384":
+		h = sha512.New384
+	case "HS512":
+		h = sha512.New
+	default:
+		return false, fmt.Errorf("暂不支持校验 %s 算法的签名，仅支持 HS256、HS384、HS512", alg)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(signature, "="))
+	if err != nil {
+		return false, nil
+	}
+	mac := hmac.New(h, secret)
+	mac.Write([]byte(signingString))
+	return hmac.Equal(sig, mac.Sum(nil)), nil
+}
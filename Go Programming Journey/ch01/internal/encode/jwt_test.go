@@ -0,0 +1,123 @@
+package encode
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"testing"
+	"time"
+)
+
+// 使用 HMAC 签名生成测试用的 token
+func signJWT(h func() hash.Hash, header, payload string, secret []byte) string {
+	signingString := base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(h, secret)
+	mac.Write([]byte(signingString))
+	return signingString + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestDecodeJWT(t *testing.T) {
+	secret := []byte("go-learning")
+	exp := time.Now().Add(time.Hour).Unix()
+	hs256 := signJWT(sha256.New, `{"alg":"HS256","typ":"JWT"}`, `{"sub":"admin","iat":1516239022,"exp":1516242622}`, secret)
+	hs384 := signJWT(sha512.New384, `{"alg":"HS384","typ":"JWT"}`, fmt.Sprintf(`{"sub":"admin","exp":%d}`, exp), secret)
+	hs512 := signJWT(sha512.New, `{"alg":"HS512","typ":"JWT"}`, fmt.Sprintf(`{"sub":"admin","exp":%d}`, exp), secret)
+	verified, failed := true, false
+
+	tests := []struct {
+		name         string
+		token        string
+		secret       []byte
+		wantAlg      string
+		wantVerified *bool
+		wantExpired  bool
+	}{
+		{"no secret", hs256, nil, "HS256", nil, true},
+		{"hs256", hs256, secret, "HS256", &verified, true},
+		{"hs384", hs384, secret, "HS384", &verified, false},
+		{"hs512", hs512, secret, "HS512", &verified, false},
+		{"wrong secret", hs256, []byte("other"), "HS256", &failed, true},
+		{"surrounding spaces", " " + hs256 + "\n", secret, "HS256", &verified, true},
+		{"padded signature", hs512 + "==", secret, "HS512", &verified, false},
+	}
+	for _, tt := range tests {
+		got, err := DecodeJWT(tt.token, tt.secret)
+		if err != nil {
+			t.Errorf("%s: DecodeJWT err: %v", tt.name, err)
+			continue
+		}
+		if got.Header["alg"] != tt.wantAlg || got.Claims["sub"] != "admin" {
+			t.Errorf("%s: header = %v, claims = %v", tt.name, got.Header, got.Claims)
+		}
+		if (got.Verified == nil) != (tt.wantVerified == nil) ||
+			(got.Verified != nil && *got.Verified != *tt.wantVerified) {
+			t.Errorf("%s: Verified = %v, want %v", tt.name, got.Verified, tt.wantVerified)
+		}
+		if got.Expired != tt.wantExpired {
+			t.Errorf("%s: Expired = %v, want %v", tt.name, got.Expired, tt.wantExpired)
+		}
+		if _, ok := got.Times["exp"]; !ok {
+			t.Errorf("%s: Times = %v, want exp", tt.name, got.Times)
+		}
+	}
+}
+
+func TestDecodeJWTTimes(t *testing.T) {
+	token := signJWT(sha256.New, `{"alg":"HS256"}`, `{"iat":1516239022,"nbf":1516239022,"exp":"never"}`, nil)
+	got, err := DecodeJWT(token, nil)
+	if err != nil {
+		t.Fatalf("DecodeJWT err: %v", err)
+	}
+	want := time.Unix(1516239022, 0).Format("2006-01-02 15:04:05")
+	if got.Times["iat"] != want || got.Times["nbf"] != want {
+		t.Errorf("Times = %v, want iat and nbf %q", got.Times, want)
+	}
+	// 非数字的 exp 不解析，也不视为过期
+	if _, ok := got.Times["exp"]; ok || got.Expired {
+		t.Errorf("Times = %v, Expired = %v, want no exp", got.Times, got.Expired)
+	}
+}
+
+func TestDecodeJWTErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"two parts", "eyJhbGciOiJIUzI1NiJ9.e30"},
+		{"four parts", "a.b.c.d"},
+		{"invalid header base64", "eyJ*.e30.sig"},
+		{"invalid header json", base64.RawURLEncoding.EncodeToString([]byte("alg")) + ".e30.sig"},
+		{"invalid payload", "eyJhbGciOiJIUzI1NiJ9.bm90LWpzb24.sig"},
+	}
+	for _, tt := range tests {
+		got, err := DecodeJWT(tt.token, nil)
+		if err == nil {
+			t.Errorf("%s: DecodeJWT err = nil, want error", tt.name)
+		}
+		if got != nil {
+			t.Errorf("%s: DecodeJWT = %+v, want nil", tt.name, got)
+		}
+	}
+}
+
+// 无法校验签名时仍然返回解码后的 header 与 payload
+func TestDecodeJWTUnsupportedAlg(t *testing.T) {
+	token := signJWT(sha256.New, `{"alg":"RS256","kid":"k1"}`, `{"sub":"admin"}`, nil)
+	got, err := DecodeJWT(token, []byte("go-learning"))
+	if err == nil {
+		t.Fatalf("DecodeJWT err = nil, want unsupported algorithm error")
+	}
+	if got == nil {
+		t.Fatalf("DecodeJWT = nil, want decoded token")
+	}
+	if got.Header["kid"] != "k1" || got.Claims["sub"] != "admin" {
+		t.Errorf("header = %v, claims = %v", got.Header, got.Claims)
+	}
+	if got.Verified != nil {
+		t.Errorf("Verified = %v, want nil", *got.Verified)
+	}
+}
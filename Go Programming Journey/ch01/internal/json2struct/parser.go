@@ -12,8 +12,17 @@ import (
 const (
 	TYPE_MAP_STRING_INTERFACE = "map[string]interface {}"
 	TYPE_INTERFACE            = "[]interface {}"
+	TYPE_EMPTY_INTERFACE      = "interface {}"
 )
 
+// 值的类型名称，JSON 中的 null 无法推断类型，使用 interface{}
+func typeOf(v interface{}) string {
+	if v == nil {
+		return TYPE_EMPTY_INTERFACE
+	}
+	return reflect.TypeOf(v).String()
+}
+
 type Parser struct {
 	Source     map[string]interface{}
 	Output     Output
@@ -53,7 +62,7 @@ func NewParser(s string) (*Parser, error) {
 func (p *Parser) Json2Struct() string {
 	p.Output.appendSegment(p.StructTag, p.StructName)
 	for parentName, parentValues := range p.Source {
-		valueType := reflect.TypeOf(parentValues).String()
+		valueType := typeOf(parentValues)
 		if valueType == TYPE_INTERFACE {
 			p.toParentList(parentName, parentValues.([]interface{}), true)
 		} else {
@@ -74,7 +83,7 @@ func (p *Parser) Json2Struct() string {
 func (p *Parser) toChildrenStruct(parentName string, values map[string]interface{}) {
 	p.Children.appendSegment(p.StructTag, parentName)
 	for fieldName, fieldValue := range values {
-		p.Children.appendSegment("%s %s", fieldName, reflect.TypeOf(fieldValue).String())
+		p.Children.appendSegment("%s %s", fieldName, typeOf(fieldValue))
 	}
 	p.Children.appendSuffix()
 }
@@ -82,7 +91,7 @@ func (p *Parser) toChildrenStruct(parentName string, values map[string]interface
 func (p *Parser) toParentList(parentName string, parentValues []interface{}, isTop bool) {
 	var fields Fields
 	for _, v := range parentValues {
-		valueType := reflect.TypeOf(v).String()
+		valueType := typeOf(v)
 		if valueType == TYPE_MAP_STRING_INTERFACE {
 			fields = append(fields, p.handleParentTypeMapIface(v.(map[string]interface{}))...)
 			p.Children.appendSegment(p.StructTag, parentName)
@@ -105,10 +114,10 @@ func (p *Parser) toParentList(parentName string, parentValues []interface{}, isT
 func (p *Parser) handleParentTypeMapIface(values map[string]interface{}) Fields {
 	var fields Fields
 	for fieldName, fieldValues := range values {
-		var fieldValueType = reflect.TypeOf(fieldValues).String()
+		var fieldValueType = typeOf(fieldValues)
 		var fieldSegment = FieldSegment{
 			Format:      "%s",
-			FieldValues: []FieldValue{{CamelCase: true, Value: fieldValueType}},
+			FieldValues: []FieldValue{{CamelCase: false, Value: fieldValueType}},
 		}
 		switch fieldValueType {
 		case TYPE_INTERFACE:
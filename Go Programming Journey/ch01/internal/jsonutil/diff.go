@@ -0,0 +1,146 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// 差异类型
+const (
+	DiffAdded   = "added"   // b 中新增
+	DiffRemoved = "removed" // b 中删除
+	DiffChanged = "changed" // 值或类型发生变化
+)
+
+// 单个差异项
+type Difference struct {
+	Path string      `json:"path"`
+	Type string      `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+func (d *Difference) String() string {
+	switch d.Type {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", d.Path, compact(d.New))
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", d.Path, compact(d.Old))
+	}
+	return fmt.Sprintf("~ %s: %s => %s", d.Path, compact(d.Old), compact(d.New))
+}
+
+// 对两个 JSON 进行语义比较
+// 对象不区分字段顺序，数字按数值比较（1 与 1.0 相等），数组按下标逐个比较
+func Diff(a, b []byte) ([]*Difference, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return nil, fmt.Errorf("解析第一个 JSON 失败: %v", err)
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("解析第二个 JSON 失败: %v", err)
+	}
+
+	var diffs []*Difference
+	diffValue(".", va, vb, &diffs)
+	return diffs, nil
+}
+
+func diffValue(path string, a, b interface{}, diffs *[]*Difference) {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		if vb, ok := b.(map[string]interface{}); ok {
+			diffObject(path, va, vb, diffs)
+			return
+		}
+	case []interface{}:
+		if vb, ok := b.([]interface{}); ok {
+			diffArray(path, va, vb, diffs)
+			return
+		}
+	case json.Number:
+		if vb, ok := b.(json.Number); ok && numberEqual(va, vb) {
+			return
+		}
+	default:
+		// string、bool 与 nil 可直接比较
+		if a == b {
+			return
+		}
+	}
+	*diffs = append(*diffs, &Difference{Path: path, Type: DiffChanged, Old: a, New: b})
+}
+
+func diffObject(path string, a, b map[string]interface{}, diffs *[]*Difference) {
+	// 对字段名排序，保证输出结果稳定
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := childPath(path, k)
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inB:
+			*diffs = append(*diffs, &Difference{Path: childPath, Type: DiffRemoved, Old: va})
+		case !inA:
+			*diffs = append(*diffs, &Difference{Path: childPath, Type: DiffAdded, New: vb})
+		default:
+			diffValue(childPath, va, vb, diffs)
+		}
+	}
+}
+
+func diffArray(path string, a, b []interface{}, diffs *[]*Difference) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		childPath := childPath(path, strconv.Itoa(i))
+		switch {
+		case i >= len(b):
+			*diffs = append(*diffs, &Difference{Path: childPath, Type: DiffRemoved, Old: a[i]})
+		case i >= len(a):
+			*diffs = append(*diffs, &Difference{Path: childPath, Type: DiffAdded, New: b[i]})
+		default:
+			diffValue(childPath, a[i], b[i], diffs)
+		}
+	}
+}
+
+func numberEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	fa, _, errA := big.ParseFloat(a.String(), 10, 256, big.ToNearestEven)
+	fb, _, errB := big.ParseFloat(b.String(), 10, 256, big.ToNearestEven)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return fa.Cmp(fb) == 0
+}
+
+// 路径格式与 Get 保持一致，可直接用于 json get 查询
+func childPath(path, key string) string {
+	if path == "." {
+		return key
+	}
+	return path + "." + key
+}
+
+func compact(v interface{}) string {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(content)
+}
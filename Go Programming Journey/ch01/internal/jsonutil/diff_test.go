@@ -0,0 +1,47 @@
+package jsonutil
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []string
+	}{
+		{name: "equal with different key order", a: `{"a":1,"b":[1,2]}`, b: `{"b":[1,2],"a":1}`},
+		{name: "numbers compared by value", a: `{"n":1,"m":1e2}`, b: `{"n":1.0,"m":100}`},
+		{name: "changed", a: `{"a":1}`, b: `{"a":2}`, want: []string{"~ a: 1 => 2"}},
+		{name: "type changed", a: `{"a":"1"}`, b: `{"a":1}`, want: []string{`~ a: "1" => 1`}},
+		{name: "added and removed", a: `{"a":1,"c":true}`, b: `{"a":1,"b":null}`, want: []string{"+ b: null", "- c: true"}},
+		{name: "nested array", a: `{"l":[{"x":1},2]}`, b: `{"l":[{"x":3}]}`, want: []string{"~ l.0.x: 1 => 3", "- l.1: 2"}},
+		{name: "array grows", a: `[1]`, b: `[1,[2]]`, want: []string{"+ 1: [2]"}},
+		{name: "root changed", a: `1`, b: `"x"`, want: []string{`~ .: 1 => "x"`}},
+	}
+	for _, tt := range tests {
+		diffs, err := Diff([]byte(tt.a), []byte(tt.b))
+		if err != nil {
+			t.Errorf("%s: Diff err: %v", tt.name, err)
+			continue
+		}
+		if len(diffs) != len(tt.want) {
+			t.Errorf("%s: Diff = %v, want %v", tt.name, diffs, tt.want)
+			continue
+		}
+		for i, d := range diffs {
+			if got := d.String(); got != tt.want[i] {
+				t.Errorf("%s: diffs[%d] = %q, want %q", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestDiffInvalid(t *testing.T) {
+	if _, err := Diff([]byte(`{}`), []byte(`{}]`)); err == nil {
+		t.Error("Diff with trailing ] err = nil, want error")
+	}
+	if _, err := Diff([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("Diff with invalid JSON err = nil, want error")
+	}
+}
@@ -0,0 +1,105 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 格式化 JSON，保留原有的字段顺序
+func Format(data []byte, indent string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimSpace(data), "", indent); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// 压缩 JSON，去除所有无意义的空白字符
+func Minify(data []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, bytes.TrimSpace(data)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// 解析 JSON，数字使用 json.Number 表示以避免精度丢失
+func Parse(data []byte) (interface{}, error) {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	// 不允许在 JSON 值之后出现其他内容，decoder.More() 无法发现多余的 } 与 ]
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("JSON 值之后存在多余的内容")
+	}
+	return v, nil
+}
+
+// 按路径查询 JSON 中的值，路径以 . 分隔，数组下标直接使用数字，e.g. list.0.name
+// 路径为空或为 . 时返回整个 JSON
+func Get(data []byte, path string) (interface{}, error) {
+	v, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	path = strings.Trim(path, ".")
+	if path == "" {
+		return v, nil
+	}
+
+	current := v
+	var walked []string
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("路径 %s 不存在", joinPath(append(walked, key)))
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil {
+				return nil, fmt.Errorf("%s 为数组，下标 %s 必须为数字", joinPath(walked), key)
+			}
+			// 支持负数下标，-1 表示最后一个元素
+			if index < 0 {
+				index += len(node)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("路径 %s 的下标超出数组长度 %d", joinPath(append(walked, key)), len(node))
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("%s 不是对象或数组，无法继续查询 %s", joinPath(walked), key)
+		}
+		walked = append(walked, key)
+	}
+	return current, nil
+}
+
+// 将查询结果转换为输出内容，字符串直接输出原始内容，其余类型输出格式化后的 JSON
+func Stringify(v interface{}, indent string) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	content, err := json.MarshalIndent(v, "", indent)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func joinPath(keys []string) string {
+	if len(keys) == 0 {
+		return "."
+	}
+	return strings.Join(keys, ".")
+}
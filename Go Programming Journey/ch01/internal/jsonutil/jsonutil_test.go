@@ -0,0 +1,94 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    interface{}
+		wantErr bool
+	}{
+		{input: `{"a":1}`, want: map[string]interface{}{"a": json.Number("1")}},
+		{input: ` [1, "x", null] `, want: []interface{}{json.Number("1"), "x", nil}},
+		{input: `12345678901234567890`, want: json.Number("12345678901234567890")},
+		{input: `{"a":1}]`, wantErr: true},
+		{input: `{"a":1}}`, wantErr: true},
+		{input: `[1]]`, wantErr: true},
+		{input: `{"a":1} {"b":2}`, wantErr: true},
+		{input: `{"a":1} x`, wantErr: true},
+		{input: `{"a":`, wantErr: true},
+		{input: ``, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse([]byte(tt.input))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) err = nil, want error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) err: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	data := []byte(`{"list":[{"name":"a"},{"name":"b"}],"n":1.5,"s":"x"}`)
+	tests := []struct {
+		path    string
+		want    interface{}
+		wantErr bool
+	}{
+		{path: "list.0.name", want: "a"},
+		{path: "list.-1.name", want: "b"},
+		{path: "n", want: json.Number("1.5")},
+		{path: ".s.", want: "x"},
+		{path: "list.2", wantErr: true},
+		{path: "list.x", wantErr: true},
+		{path: "missing", wantErr: true},
+		{path: "s.x", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Get(data, tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Get(%q) err = nil, want error", tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Get(%q) err: %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Get(%q) = %#v, want %#v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAndMinify(t *testing.T) {
+	input := []byte(" {\"b\": 1, \"a\": [1, 2]} \n")
+	formatted, err := Format(input, "  ")
+	if err != nil {
+		t.Fatalf("Format err: %v", err)
+	}
+	if want := "{\n  \"b\": 1,\n  \"a\": [\n    1,\n    2\n  ]\n}"; formatted != want {
+		t.Errorf("Format = %q, want %q", formatted, want)
+	}
+	minified, err := Minify([]byte(formatted))
+	if err != nil {
+		t.Fatalf("Minify err: %v", err)
+	}
+	if want := `{"b":1,"a":[1,2]}`; minified != want {
+		t.Errorf("Minify = %q, want %q", minified, want)
+	}
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"demo/ch01/internal/json2struct"
+	"demo/ch01/internal/sql2struct"
+	"demo/ch01/internal/timer"
+	"demo/ch01/internal/word"
+)
+
+// 内嵌的 Web 页面，无需安装 Go 即可在浏览器中使用各项转换工具
+var (
+	//go:embed static
+	staticFS embed.FS
+)
+
+// 请求体最大字节数
+const maxBodyBytes = 1 << 20
+
+type WordRequest struct {
+	Str  string `json:"str"`
+	Mode int8   `json:"mode"`
+}
+
+type TimeCalcRequest struct {
+	Calculate string `json:"calculate"`
+	Duration  string `json:"duration"`
+}
+
+type JSONStructRequest struct {
+	Str string `json:"str"`
+}
+
+type SQLStructRequest struct {
+	DDL string `json:"ddl"`
+}
+
+type TimeResponse struct {
+	Time      string `json:"time"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type Response struct {
+	Result string `json:"result"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// 注册所有接口与页面的路由
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/word", post(handleWord))
+	mux.HandleFunc("/api/time/now", handleTimeNow)
+	mux.HandleFunc("/api/time/calc", post(handleTimeCalc))
+	mux.HandleFunc("/api/json/struct", post(handleJSONStruct))
+	mux.HandleFunc("/api/sql/struct", post(handleSQLStruct))
+	mux.HandleFunc("/", handleIndex)
+	return mux
+}
+
+// 创建 http.Server，超时时间与 ch02 中的服务保持一致
+func NewServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:           addr,
+		Handler:        NewHandler(),
+		ReadTimeout:    60 * time.Second,
+		WriteTimeout:   60 * time.Second,
+		MaxHeaderBytes: 1 << 20,
+	}
+}
+
+func handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	page, err := staticFS.ReadFile("static/index.html")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(page)
+}
+
+func handleWord(w http.ResponseWriter, r *http.Request) {
+	var req WordRequest
+	if err := decode(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	content, err := word.Convert(req.Mode, req.Str)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Result: content})
+}
+
+func handleTimeNow(w http.ResponseWriter, r *http.Request) {
+	nowTime := timer.GetNowTime()
+	writeJSON(w, http.StatusOK, TimeResponse{Time: nowTime.Format(timer.DefaultLayout), Timestamp: nowTime.Unix()})
+}
+
+func handleTimeCalc(w http.ResponseWriter, r *http.Request) {
+	var req TimeCalcRequest
+	if err := decode(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	currentTimer, layout, err := timer.ParseCalculateTime(req.Calculate)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	t, err := timer.GetCalculateTime(currentTimer, req.Duration)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, TimeResponse{Time: t.Format(layout), Timestamp: t.Unix()})
+}
+
+func handleJSONStruct(w http.ResponseWriter, r *http.Request) {
+	var req JSONStructRequest
+	if err := decode(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	parser, err := json2struct.NewParser(req.Str)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Result: parser.Json2Struct()})
+}
+
+// DDL 模式：直接解析建表语句，不连接数据库
+func handleSQLStruct(w http.ResponseWriter, r *http.Request) {
+	var req SQLStructRequest
+	if err := decode(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	tableName, columns, err := sql2struct.ParseDDL(req.DDL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	template := sql2struct.NewStructTemplate()
+	if err := template.GenerateTo(&buf, tableName, template.AssemblyColumns(columns)); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Result: buf.String()})
+}
+
+// 限制接口仅接受 POST 请求
+func post(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("仅支持 POST 请求"))
+			return
+		}
+		h(w, r)
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
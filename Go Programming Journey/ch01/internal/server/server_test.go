@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleTimeCalc(t *testing.T) {
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantTime   string
+	}{
+		{`{"calculate":"2021-06-01 12:00:00","duration":"90m"}`, http.StatusOK, "2021-06-01 13:30:00"},
+		{`{"calculate":"2021-06-01","duration":"-24h"}`, http.StatusOK, "2021-05-31"},
+		{`{"calculate":"garbage","duration":"1h"}`, http.StatusBadRequest, ""},
+		{`{"calculate":"2021-06-01","duration":"1x"}`, http.StatusBadRequest, ""},
+	}
+	handler := NewHandler()
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/time/calc", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d, body %s", tt.body, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp TimeResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("json.Unmarshal err: %v", err)
+		}
+		if resp.Time != tt.wantTime {
+			t.Errorf("%s: time = %q, want %q", tt.body, resp.Time, tt.wantTime)
+		}
+	}
+}
+
+func TestHandleJSONStruct(t *testing.T) {
+	tests := []struct {
+		str        string
+		wantStatus int
+		wantField  string
+	}{
+		{`{"a":1}`, http.StatusOK, "A float64"},
+		{`{"a":null}`, http.StatusOK, "A interface {}"},
+		{`{"a":[null]}`, http.StatusOK, "A []interface {}"},
+		{`{"a":[{"b":null}]}`, http.StatusOK, "B interface {}"},
+		{`{"a":{"b":null}}`, http.StatusOK, "A map[string]interface {}"},
+		{`{"a":[{"b":1}]}`, http.StatusOK, "B float64"},
+		{`[1]`, http.StatusBadRequest, ""},
+	}
+	handler := NewHandler()
+	for _, tt := range tests {
+		body, _ := json.Marshal(JSONStructRequest{Str: tt.str})
+		req := httptest.NewRequest(http.MethodPost, "/api/json/struct", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d, body %s", tt.str, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		if tt.wantStatus != http.StatusOK {
+			continue
+		}
+		var resp Response
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("json.Unmarshal err: %v", err)
+		}
+		if !strings.Contains(resp.Result, tt.wantField) {
+			t.Errorf("%s: result %q does not contain %q", tt.str, resp.Result, tt.wantField)
+		}
+	}
+}
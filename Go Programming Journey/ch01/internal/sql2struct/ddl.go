@@ -0,0 +1,240 @@
+package sql2struct
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// 匹配建表语句中的表名，支持 IF NOT EXISTS 与 `db`.`table` 写法
+var createTableRegexp = regexp.MustCompile("(?is)^\\s*create\\s+(?:temporary\\s+)?table\\s+(?:if\\s+not\\s+exists\\s+)?((?:[`\"]?\\w+[`\"]?\\.)?[`\"]?\\w+[`\"]?)\\s*\\(")
+
+// 匹配 COMMENT '...' 子句，注释内容中连续的两个单引号表示转义后的单引号
+var columnCommentRegexp = regexp.MustCompile(`(?is)\bcomment\s+'((?:[^']|'')*)'`)
+
+// 匹配单引号字符串，连续的两个单引号或反斜杠转义的单引号不会结束字符串
+var stringLiteralRegexp = regexp.MustCompile(`'(?:[^'\\]|''|\\.)*'`)
+
+// 第一个单词为这些关键字的定义为索引或约束而不是字段
+var constraintKeywords = map[string]bool{
+	"PRIMARY":    true,
+	"KEY":        true,
+	"INDEX":      true,
+	"UNIQUE":     true,
+	"CONSTRAINT": true,
+	"FOREIGN":    true,
+	"FULLTEXT":   true,
+	"SPATIAL":    true,
+	"CHECK":      true,
+}
+
+// 解析 CREATE TABLE 语句（DDL 模式），得到表名与字段信息
+// 不需要连接数据库，得到的 TableColumn 与查询 COLUMNS 表得到的结果一致，可直接用于模板渲染
+func ParseDDL(ddl string) (string, []*TableColumn, error) {
+	matches := createTableRegexp.FindStringSubmatchIndex(ddl)
+	if matches == nil {
+		return "", nil, errors.New("未找到 CREATE TABLE 语句")
+	}
+	tableName := trimIdentifier(ddl[matches[2]:matches[3]])
+	if index := strings.LastIndex(tableName, "."); index != -1 {
+		tableName = trimIdentifier(tableName[index+1:])
+	}
+
+	// matches[1] 为左括号之后的位置，截取到与之匹配的右括号为止
+	body, err := enclosedBody(ddl[matches[1]:])
+	if err != nil {
+		return "", nil, err
+	}
+
+	var columns []*TableColumn
+	primaryKeys := make(map[string]bool)
+	for _, definition := range splitTopLevel(body) {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+		upper := strings.ToUpper(definition)
+		if isConstraint(upper) {
+			if strings.HasPrefix(upper, "PRIMARY") {
+				for _, name := range keyColumns(definition) {
+					primaryKeys[name] = true
+				}
+			}
+			continue
+		}
+
+		column, err := parseColumn(definition)
+		if err != nil {
+			return "", nil, err
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return "", nil, errors.New("建表语句中没有字段定义")
+	}
+
+	for _, column := range columns {
+		if primaryKeys[column.ColumnName] {
+			column.ColumnKey = "PRI"
+		}
+	}
+	return tableName, columns, nil
+}
+
+// 解析单个字段定义，e.g. `name` varchar(100) NOT NULL COMMENT '名称'
+func parseColumn(definition string) (*TableColumn, error) {
+	fields := strings.Fields(definition)
+	if len(fields) < 2 {
+		return nil, errors.New("无法解析字段定义: " + definition)
+	}
+
+	column := &TableColumn{
+		ColumnName: trimIdentifier(fields[0]),
+		IsNullable: "YES",
+	}
+	// 字段类型中可能包含空格，e.g. decimal(10, 2)、enum('a', 'b')
+	rest := strings.TrimSpace(definition[strings.Index(definition, fields[0])+len(fields[0]):])
+	columnType := rest
+	if index := strings.Index(rest, "("); index != -1 && index < len(strings.Fields(rest)[0]) {
+		end := matchingParen(rest, index)
+		if end == -1 {
+			return nil, errors.New("字段类型括号不匹配: " + definition)
+		}
+		columnType = rest[:end+1]
+	} else {
+		columnType = strings.Fields(rest)[0]
+	}
+	column.DataType = strings.ToLower(columnType)
+	if index := strings.Index(column.DataType, "("); index != -1 {
+		column.DataType = column.DataType[:index]
+	}
+
+	// 追加 unsigned/zerofill 等类型修饰
+	for _, modifier := range strings.Fields(strings.ToLower(rest[len(columnType):])) {
+		if modifier != "unsigned" && modifier != "zerofill" {
+			break
+		}
+		columnType += " " + modifier
+	}
+	column.ColumnType = strings.ToLower(columnType)
+
+	// 去除 COMMENT、DEFAULT 等子句中的字符串，避免将其中的内容当作字段属性
+	upper := strings.ToUpper(stringLiteralRegexp.ReplaceAllString(definition, "''"))
+	if strings.Contains(upper, "NOT NULL") {
+		column.IsNullable = "NO"
+	}
+	if strings.Contains(upper, "PRIMARY KEY") {
+		column.ColumnKey = "PRI"
+	} else if strings.Contains(upper, " UNIQUE") {
+		column.ColumnKey = "UNI"
+	}
+	if m := columnCommentRegexp.FindStringSubmatch(definition); m != nil {
+		column.ColumnComment = strings.Replace(m[1], "''", "'", -1)
+	}
+	return column, nil
+}
+
+// 仅比较第一个单词，keyword、index_no 等以关键字开头的字段名不是约束
+// 使用反引号或双引号包含的第一个单词为字段名，e.g. `key` varchar(10)
+func isConstraint(upper string) bool {
+	if strings.HasPrefix(upper, "`") || strings.HasPrefix(upper, `"`) {
+		return false
+	}
+	word := upper
+	if index := strings.IndexAny(upper, " \t\r\n("); index != -1 {
+		word = upper[:index]
+	}
+	return constraintKeywords[word]
+}
+
+// 获取 PRIMARY KEY (`a`, `b`) 中的字段名
+func keyColumns(definition string) []string {
+	start := strings.Index(definition, "(")
+	if start == -1 {
+		return nil
+	}
+	end := matchingParen(definition, start)
+	if end == -1 {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(definition[start+1:end], ",") {
+		name = strings.TrimSpace(name)
+		// 去除前缀索引长度，e.g. `name`(10)
+		if index := strings.Index(name, "("); index != -1 {
+			name = name[:index]
+		}
+		names = append(names, trimIdentifier(name))
+	}
+	return names
+}
+
+// 获取左括号之后到与之匹配的右括号之前的内容
+func enclosedBody(s string) (string, error) {
+	end := matchingParen("("+s, 0)
+	if end == -1 {
+		return "", errors.New("建表语句括号不匹配")
+	}
+	return s[:end-1], nil
+}
+
+// 返回与 s[start] 处左括号相匹配的右括号位置，忽略引号中的内容
+func matchingParen(s string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// 按最外层的逗号切分字段定义，忽略括号与引号中的逗号
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, last := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
+
+func trimIdentifier(s string) string {
+	return strings.Trim(strings.TrimSpace(s), "`\"")
+}
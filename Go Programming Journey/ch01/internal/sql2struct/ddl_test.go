@@ -0,0 +1,90 @@
+package sql2struct
+
+import (
+	"testing"
+)
+
+func TestParseDDL(t *testing.T) {
+	ddl := "CREATE TABLE IF NOT EXISTS `blog`.`blog_tag` (\n" +
+		"  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `name` varchar(100) DEFAULT '' COMMENT '标签名称',\n" +
+		"  keyword varchar(50) NOT NULL,\n" +
+		"  index_no int(11) DEFAULT NULL,\n" +
+		"  primary_email varchar(255) COMMENT 'NOT NULL UNIQUE',\n" +
+		"  check_time datetime,\n" +
+		"  `key` varchar(10) UNIQUE,\n" +
+		"  price decimal(10, 2) NOT NULL COMMENT 'it''s a price',\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_name` (`name`(10)),\n" +
+		"  KEY(`keyword`),\n" +
+		"  CHECK (price > 0)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;"
+
+	tableName, columns, err := ParseDDL(ddl)
+	if err != nil {
+		t.Fatalf("ParseDDL err: %v", err)
+	}
+	if tableName != "blog_tag" {
+		t.Errorf("tableName = %q, want blog_tag", tableName)
+	}
+
+	want := []TableColumn{
+		{ColumnName: "id", DataType: "int", ColumnType: "int(10) unsigned", IsNullable: "NO", ColumnKey: "PRI"},
+		{ColumnName: "name", DataType: "varchar", ColumnType: "varchar(100)", IsNullable: "YES", ColumnComment: "标签名称"},
+		{ColumnName: "keyword", DataType: "varchar", ColumnType: "varchar(50)", IsNullable: "NO"},
+		{ColumnName: "index_no", DataType: "int", ColumnType: "int(11)", IsNullable: "YES"},
+		{ColumnName: "primary_email", DataType: "varchar", ColumnType: "varchar(255)", IsNullable: "YES", ColumnComment: "NOT NULL UNIQUE"},
+		{ColumnName: "check_time", DataType: "datetime", ColumnType: "datetime", IsNullable: "YES"},
+		{ColumnName: "key", DataType: "varchar", ColumnType: "varchar(10)", IsNullable: "YES", ColumnKey: "UNI"},
+		{ColumnName: "price", DataType: "decimal", ColumnType: "decimal(10, 2)", IsNullable: "NO", ColumnComment: "it's a price"},
+	}
+	if len(columns) != len(want) {
+		t.Fatalf("len(columns) = %d, want %d", len(columns), len(want))
+	}
+	for i, column := range columns {
+		if *column != want[i] {
+			t.Errorf("columns[%d] = %+v, want %+v", i, *column, want[i])
+		}
+	}
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ddl  string
+	}{
+		{"not create table", "SELECT * FROM blog_tag"},
+		{"unbalanced", "CREATE TABLE t (id int(10)"},
+		{"no columns", "CREATE TABLE t (PRIMARY KEY (id))"},
+	}
+	for _, tt := range tests {
+		if _, _, err := ParseDDL(tt.ddl); err == nil {
+			t.Errorf("%s: ParseDDL err = nil, want error", tt.name)
+		}
+	}
+}
+
+func TestIsConstraint(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       bool
+	}{
+		{"PRIMARY KEY (`id`)", true},
+		{"KEY(`name`)", true},
+		{"INDEX idx_name (name)", true},
+		{"UNIQUE KEY uk (name)", true},
+		{"CONSTRAINT fk FOREIGN KEY (tag_id) REFERENCES blog_tag (id)", true},
+		{"FULLTEXT KEY ft (title)", true},
+		{"CHECK (price > 0)", true},
+		{"KEYWORD VARCHAR(50)", false},
+		{"INDEX_NO INT", false},
+		{"PRIMARY_EMAIL VARCHAR(255)", false},
+		{"CHECK_TIME DATETIME", false},
+		{"`KEY` VARCHAR(10)", false},
+	}
+	for _, tt := range tests {
+		if got := isConstraint(tt.definition); got != tt.want {
+			t.Errorf("isConstraint(%q) = %v, want %v", tt.definition, got, tt.want)
+		}
+	}
+}
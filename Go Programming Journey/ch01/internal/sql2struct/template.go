@@ -2,6 +2,7 @@ package sql2struct
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"text/template"
 
@@ -65,8 +66,13 @@ func (t *StructTemplate) AssemblyColumns(tbColumns []*TableColumn) []*StructColu
 	return tplColumns
 }
 
-//
+// 渲染结果输出到标准输出
 func (t *StructTemplate) Generate(tableName string, tplColumns []*StructColumn) error {
+	return t.GenerateTo(os.Stdout, tableName, tplColumns)
+}
+
+// 渲染结果输出到指定的 io.Writer 中，e.g. HTTP 响应
+func (t *StructTemplate) GenerateTo(w io.Writer, tableName string, tplColumns []*StructColumn) error {
 	// template.Must 包装对返回 (*Template, error) 的函数的调用，并在 error 为非 nil 时发生 panic
 	// 声明了一个名为 sql2struct 的新模板对象
 	// 定义了自定义函数 ToCamelCase，并与 word.UnderscoreToUpperCamelCase 方法进行绑定
@@ -81,7 +87,7 @@ func (t *StructTemplate) Generate(tableName string, tplColumns []*StructColumn)
 		Columns:   tplColumns,
 	}
 	// 进行渲染
-	err := tpl.Execute(os.Stdout, tplDB)
+	err := tpl.Execute(w, tplDB)
 	if err != nil {
 		return err
 	}
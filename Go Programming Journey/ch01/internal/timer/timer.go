@@ -1,9 +1,15 @@
 package timer
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// 默认的时间格式
+const DefaultLayout = "2006-01-02 15:04:05"
+
 // 封装返回当前本地时间的 Time 对象
 func GetNowTime() time.Time {
 	//return time.Now()
@@ -20,3 +26,24 @@ func GetCalculateTime(currentTime time.Time, d string) (time.Time, error) {
 	}
 	return currentTime.Add(duration), nil
 }
+
+// 解析需要计算的时间，支持时间戳、日期与日期时间三种格式，为空时使用当前时间
+// 返回解析后的时间与输出时应使用的时间格式，三种格式均无法解析时返回错误
+func ParseCalculateTime(s string) (time.Time, string, error) {
+	layout := DefaultLayout
+	if s == "" {
+		return GetNowTime(), layout, nil
+	}
+	if strings.Count(s, " ") == 0 {
+		layout = "2006-01-02"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		timestamp, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, "", fmt.Errorf("无法解析时间 %q，有效格式为时间戳、%s 或 %s", s, "2006-01-02", DefaultLayout)
+		}
+		t = time.Unix(timestamp, 0)
+	}
+	return t, layout, nil
+}
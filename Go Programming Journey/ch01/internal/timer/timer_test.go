@@ -0,0 +1,48 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCalculateTime(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantUnix   int64
+		wantLayout string
+		wantErr    bool
+	}{
+		{input: "2021-06-01 12:30:00", wantUnix: time.Date(2021, 6, 1, 12, 30, 0, 0, time.UTC).Unix(), wantLayout: DefaultLayout},
+		{input: "2021-06-01", wantUnix: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC).Unix(), wantLayout: "2006-01-02"},
+		{input: "1622550600", wantUnix: 1622550600, wantLayout: "2006-01-02"},
+		{input: "abc", wantErr: true},
+		{input: "2021-13-01", wantErr: true},
+		{input: "2021-06-01 25:00:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, layout, err := ParseCalculateTime(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseCalculateTime(%q) err = nil, want error", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCalculateTime(%q) err: %v", tt.input, err)
+			continue
+		}
+		if got.Unix() != tt.wantUnix || layout != tt.wantLayout {
+			t.Errorf("ParseCalculateTime(%q) = %d, %q, want %d, %q", tt.input, got.Unix(), layout, tt.wantUnix, tt.wantLayout)
+		}
+	}
+}
+
+func TestParseCalculateTimeEmpty(t *testing.T) {
+	got, layout, err := ParseCalculateTime("")
+	if err != nil || layout != DefaultLayout {
+		t.Fatalf("ParseCalculateTime(\"\") = %v, %q, %v", got, layout, err)
+	}
+	if d := time.Since(got); d < 0 || d > time.Minute {
+		t.Errorf("ParseCalculateTime(\"\") = %v, want now", got)
+	}
+}
@@ -1,6 +1,8 @@
 package word
 
 import (
+	"errors"
+
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	"strings"
@@ -53,3 +55,34 @@ func CamelCaseToUnderscore(s string) string {
 	}
 	return string(output)
 }
+
+// 单词转换模式
+const (
+	ModeUpper                      = iota + 1 // 全部转大写
+	ModeLower                                 // 全部转小写
+	ModeUnderscoreToUpperCamelCase            // 下划线转大写驼峰
+	ModeUnderscoreToLowerCamelCase            // 下线线转小写驼峰
+	ModeCamelCaseToUnderscore                 // 驼峰转下划线
+)
+
+var ErrUnsupportedMode = errors.New("暂不支持该转换模式")
+
+// 根据模式转换字符串，供命令行与 HTTP 接口共用
+func Convert(mode int8, s string) (string, error) {
+	switch mode {
+	case ModeUpper:
+		return ToUpper(s), nil
+	case ModeLower:
+		return ToLower(s), nil
+	case ModeUnderscoreToUpperCamelCase:
+		return UnderscoreToUpperCamelCase(s), nil
+	case ModeUnderscoreToLowerCamelCase:
+		if s == "" {
+			return s, nil
+		}
+		return UnderscoreToLowerCamelCase(s), nil
+	case ModeCamelCaseToUnderscore:
+		return CamelCaseToUnderscore(s), nil
+	}
+	return "", ErrUnsupportedMode
+}
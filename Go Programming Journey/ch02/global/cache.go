@@ -0,0 +1,8 @@
+package global
+
+import "demo/ch02/pkg/cache"
+
+var (
+	// 未配置 Cache.Backend 时为 nil，不使用缓存
+	Cache cache.Cache
+)
@@ -0,0 +1,8 @@
+package global
+
+import "github.com/go-redis/redis/v8"
+
+var (
+	// 未配置 Redis.Addr 时为 nil
+	Redis *redis.Client
+)
@@ -3,13 +3,61 @@ package global
 import (
 	"demo/ch02/pkg/logger"
 	"demo/ch02/pkg/setting"
+	"sync"
 )
 
 var (
-	ServerSetting   *setting.ServerSettingS
-	AppSetting      *setting.AppSettingS
-	DatabaseSetting *setting.DatabaseSettingS
-	Logger          *logger.Logger
-	JWTSetting      *setting.JWTSettingS
-	EmailSetting    *setting.EmailSettingS
+	Logger *logger.Logger
 )
+
+// 全部配置节，配置文件热更新时在回调中整体替换
+// 请求中应读取一次配置节再使用其中的字段，避免前后读取到不同版本的配置
+type Settings struct {
+	Server    *setting.ServerSettingS
+	App       *setting.AppSettingS
+	Database  *setting.DatabaseSettingS
+	JWT       *setting.JWTSettingS
+	Email     *setting.EmailSettingS
+	Comment   *setting.CommentSettingS
+	RateLimit *setting.RateLimitSettingS
+	Redis     *setting.RedisSettingS
+	Cache     *setting.CacheSettingS
+	Storage   *setting.StorageSettingS
+}
+
+var (
+	settingsMu sync.RWMutex
+	settings   Settings
+)
+
+// 返回当前使用的全部配置节
+func LoadSettings() Settings {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return settings
+}
+
+// 替换全部配置节，已替换的配置节在替换后不应再修改
+func StoreSettings(s Settings) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	settings = s
+}
+
+// 在锁内修改部分配置节，用于只替换单个配置节(e.g. 测试中)
+func UpdateSettings(fn func(s *Settings)) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	fn(&settings)
+}
+
+func ServerSetting() *setting.ServerSettingS       { return LoadSettings().Server }
+func AppSetting() *setting.AppSettingS             { return LoadSettings().App }
+func DatabaseSetting() *setting.DatabaseSettingS   { return LoadSettings().Database }
+func JWTSetting() *setting.JWTSettingS             { return LoadSettings().JWT }
+func EmailSetting() *setting.EmailSettingS         { return LoadSettings().Email }
+func CommentSetting() *setting.CommentSettingS     { return LoadSettings().Comment }
+func RateLimitSetting() *setting.RateLimitSettingS { return LoadSettings().RateLimit }
+func RedisSetting() *setting.RedisSettingS         { return LoadSettings().Redis }
+func CacheSetting() *setting.CacheSettingS         { return LoadSettings().Cache }
+func StorageSetting() *setting.StorageSettingS     { return LoadSettings().Storage }
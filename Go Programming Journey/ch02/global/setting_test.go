@@ -0,0 +1,40 @@
+package global
+
+import (
+	"sync"
+	"testing"
+
+	"demo/ch02/pkg/setting"
+)
+
+// 配置热更新时替换配置节，与请求中的读取并发执行，需使用 -race 运行
+func TestSettingsConcurrentReload(t *testing.T) {
+	oldSettings := LoadSettings()
+	defer StoreSettings(oldSettings)
+	StoreSettings(Settings{App: &setting.AppSettingS{DefaultPageSize: 10}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if s := AppSetting(); s.DefaultPageSize != 10 && s.DefaultPageSize != 20 {
+					t.Errorf("DefaultPageSize = %d, want 10 or 20", s.DefaultPageSize)
+					return
+				}
+			}
+		}()
+	}
+	for j := 0; j < 1000; j++ {
+		size := 10 + j%2*10
+		UpdateSettings(func(s *Settings) { s.App = &setting.AppSettingS{DefaultPageSize: size} })
+	}
+	wg.Wait()
+
+	// UpdateSettings 只替换修改的配置节
+	UpdateSettings(func(s *Settings) { s.JWT = &setting.JWTSettingS{Secret: "secret"} })
+	if AppSetting() == nil || JWTSetting().Secret != "secret" {
+		t.Errorf("Settings = %+v", LoadSettings())
+	}
+}
@@ -0,0 +1,8 @@
+package global
+
+import "demo/ch02/pkg/storage"
+
+var (
+	// 上传文件的存储后端，由 Storage.Backend 决定
+	Storage storage.Storage
+)
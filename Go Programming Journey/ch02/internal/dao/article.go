@@ -3,12 +3,12 @@ package dao
 import (
 	"demo/ch02/internal/model"
 	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/search"
 )
 
 // 设置文章入参结构体
 type Article struct {
 	ID            uint32 `json:"id"`
-	TagID         uint32 `json:"tag_id"`
 	Title         string `json:"title"`
 	Desc          string `json:"desc"`
 	Content       string `json:"content"`
@@ -16,11 +16,12 @@ type Article struct {
 	CreatedBy     string `json:"created_by"`
 	ModifiedBy    string `json:"modified_by"`
 	State         uint8  `json:"state"`
+	PublishAt     uint32 `json:"publish_at"`
 }
 
-func (d *Dao) CountArticle(title string, state uint8) (int, error) {
-	article := model.Article{Title: title, State: state}
-	return article.CountArticle(d.engine)
+func (d *Dao) CountArticle(title, createdBy string, state uint8, spec *app.QuerySpec) (int, error) {
+	article := model.Article{Title: title, State: state, Model: &model.Model{CreatedBy: createdBy}}
+	return article.CountArticle(d.engine, spec)
 }
 
 func (d *Dao) GetArticle(id uint32, state uint8) (model.Article, error) {
@@ -29,10 +30,10 @@ func (d *Dao) GetArticle(id uint32, state uint8) (model.Article, error) {
 	return article.Get(d.engine)
 }
 
-func (d *Dao) GetArticleList(title string, state uint8, page, pageSize int) ([]*model.Article, error) {
-	article := model.Article{Title: title, State: state}
+func (d *Dao) GetArticleList(title, createdBy string, state uint8, spec *app.QuerySpec, page, pageSize int) ([]*model.Article, error) {
+	article := model.Article{Title: title, State: state, Model: &model.Model{CreatedBy: createdBy}}
 	pageOffset := app.GetPageOffset(page, pageSize)
-	return article.List(d.engine, pageOffset, pageSize)
+	return article.List(d.engine, spec, pageOffset, pageSize)
 }
 
 func (d *Dao) CreateArticle(param *Article) (*model.Article, error) {
@@ -42,6 +43,7 @@ func (d *Dao) CreateArticle(param *Article) (*model.Article, error) {
 		Content:       param.Content,
 		CoverImageUrl: param.CoverImageUrl,
 		State:         param.State,
+		PublishAt:     param.PublishAt,
 		Model:         &model.Model{CreatedBy: param.CreatedBy},
 	}
 	return article.Create(d.engine)
@@ -49,9 +51,21 @@ func (d *Dao) CreateArticle(param *Article) (*model.Article, error) {
 
 func (d *Dao) UpdateArticle(param *Article) error {
 	article := model.Article{Model: &model.Model{ID: param.ID}}
+	return article.Update(d.engine, articleValues(param))
+}
+
+// 仅当文章的版本号仍为 version 时更新，文章已被修改时返回 false
+func (d *Dao) UpdateArticleIfUnmodified(param *Article, version uint32) (bool, error) {
+	article := model.Article{Model: &model.Model{ID: param.ID}}
+	return article.UpdateIfUnmodified(d.engine, articleValues(param), version)
+}
+
+// 更新文章时写入的字段，未传入的内容字段保持不变
+func articleValues(param *Article) map[string]interface{} {
 	var values = map[string]interface{}{
 		"modified_by": param.ModifiedBy,
 		"state":       param.State,
+		"publish_at":  param.PublishAt,
 	}
 	if param.Title != "" {
 		values["title"] = param.Title
@@ -65,7 +79,7 @@ func (d *Dao) UpdateArticle(param *Article) error {
 	if param.Content != "" {
 		values["content"] = param.Content
 	}
-	return article.Update(d.engine, values)
+	return values
 }
 
 func (d *Dao) DeleteArticle(id uint32) error {
@@ -73,13 +87,50 @@ func (d *Dao) DeleteArticle(id uint32) error {
 	return article.Delete(d.engine)
 }
 
+// 定时发布新增
+func (d *Dao) GetDueArticles(publishAt uint32, limit int) ([]*model.Article, error) {
+	article := model.Article{}
+	return article.ListDue(d.engine, publishAt, limit)
+}
+
+func (d *Dao) PublishArticle(id uint32, modifiedBy string) (bool, error) {
+	article := model.Article{Model: &model.Model{ID: id, ModifiedBy: modifiedBy}}
+	return article.Publish(d.engine)
+}
+
 // 文章管理新增
-func (d *Dao) CountArticleListByTagID(id uint32, state uint8) (int, error) {
+func (d *Dao) CountArticleListByTagID(id uint32, createdBy string, state uint8, spec *app.QuerySpec) (int, error) {
+	article := model.Article{State: state, Model: &model.Model{CreatedBy: createdBy}}
+	return article.CountByTagID(d.engine, id, spec)
+}
+
+func (d *Dao) GetArticleListByTagID(id uint32, createdBy string, state uint8, spec *app.QuerySpec, page, pageSize int) ([]*model.Article, error) {
+	article := model.Article{State: state, Model: &model.Model{CreatedBy: createdBy}}
+	return article.ListByTagID(d.engine, id, spec, app.GetPageOffset(page, pageSize), pageSize)
+}
+
+// 文章搜索新增
+func (d *Dao) GetArticleListByIDs(ids []uint32) ([]*model.Article, error) {
+	article := model.Article{}
+	return article.ListByIDs(d.engine, ids)
+}
+
+func (d *Dao) GetAllArticles() ([]*model.Article, error) {
+	article := model.Article{}
+	return article.ListAll(d.engine)
+}
+
+func (d *Dao) HasArticleFullTextIndex() (bool, error) {
+	article := model.Article{}
+	return article.HasFullTextIndex(d.engine)
+}
+
+func (d *Dao) SearchArticle(query string, state uint8, page, pageSize int) ([]search.Hit, error) {
 	article := model.Article{State: state}
-	return article.CountByTagID(d.engine, id)
+	return article.Search(d.engine, query, app.GetPageOffset(page, pageSize), pageSize)
 }
 
-func (d *Dao) GetArticleListByTagID(id uint32, state uint8, page, pageSize int) ([]*model.ArticleRow, error) {
+func (d *Dao) CountSearchArticle(query string, state uint8) (int, error) {
 	article := model.Article{State: state}
-	return article.ListByTagID(d.engine, id, app.GetPageOffset(page, pageSize), pageSize)
+	return article.CountSearch(d.engine, query)
 }
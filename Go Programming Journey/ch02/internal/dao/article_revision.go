@@ -0,0 +1,33 @@
+package dao
+
+import (
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+)
+
+func (d *Dao) CreateArticleRevision(article *model.Article, createdBy string) (*model.ArticleRevision, error) {
+	revision := model.ArticleRevision{
+		Model:         &model.Model{CreatedBy: createdBy},
+		ArticleID:     article.ID,
+		Title:         article.Title,
+		Desc:          article.Desc,
+		Content:       article.Content,
+		CoverImageUrl: article.CoverImageUrl,
+	}
+	return revision.Create(d.engine)
+}
+
+func (d *Dao) GetArticleRevision(articleID, id uint32) (model.ArticleRevision, error) {
+	revision := model.ArticleRevision{Model: &model.Model{ID: id}, ArticleID: articleID}
+	return revision.Get(d.engine)
+}
+
+func (d *Dao) CountArticleRevision(articleID uint32) (int, error) {
+	revision := model.ArticleRevision{ArticleID: articleID}
+	return revision.CountByAID(d.engine)
+}
+
+func (d *Dao) GetArticleRevisionList(articleID uint32, page, pageSize int) ([]*model.ArticleRevision, error) {
+	revision := model.ArticleRevision{ArticleID: articleID}
+	return revision.ListByAID(d.engine, app.GetPageOffset(page, pageSize), pageSize)
+}
@@ -2,9 +2,9 @@ package dao
 
 import "demo/ch02/internal/model"
 
-func (d *Dao) GetArticleTagByAID(articleID uint32) (model.ArticleTag, error) {
+func (d *Dao) GetArticleTagListByAID(articleID uint32) ([]*model.ArticleTag, error) {
 	articleTag := model.ArticleTag{ArticleID: articleID}
-	return articleTag.GetByAID(d.engine)
+	return articleTag.ListByAID(d.engine)
 }
 
 func (d *Dao) GetArticleTagListByTID(tagID uint32) ([]*model.ArticleTag, error) {
@@ -28,17 +28,12 @@ func (d *Dao) CreateArticleTag(articleID, tagID uint32, createdBy string) error
 	return articleTag.Create(d.engine)
 }
 
-func (d *Dao) UpdateArticleTag(articleID, tagID uint32, modifiedBy string) error {
+func (d *Dao) DeleteArticleTagByTIDs(articleID uint32, tagIDs []uint32) error {
 	articleTag := model.ArticleTag{ArticleID: articleID}
-	values := map[string]interface{}{
-		"article_id":  articleID,
-		"tag_id":      tagID,
-		"modified_by": modifiedBy,
-	}
-	return articleTag.UpdateOne(d.engine, values)
+	return articleTag.DeleteByTIDs(d.engine, tagIDs)
 }
 
 func (d *Dao) DeleteArticleTag(articleID uint32) error {
 	articleTag := model.ArticleTag{ArticleID: articleID}
-	return articleTag.DeleteOne(d.engine)
+	return articleTag.DeleteByAID(d.engine)
 }
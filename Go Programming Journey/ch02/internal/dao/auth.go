@@ -1,8 +1,43 @@
 package dao
 
-import "demo/ch02/internal/model"
+import (
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+)
 
-func (d *Dao) GetAuth(appKey, appSecret string) (model.Auth, error) {
-	auth := model.Auth{AppKey: appKey, AppSecret: appSecret}
+func (d *Dao) GetAuth(appKey string) (model.Auth, error) {
+	auth := model.Auth{AppKey: appKey}
 	return auth.Get(d.engine)
 }
+
+func (d *Dao) GetAuthByID(id uint32) (model.Auth, error) {
+	auth := model.Auth{Model: &model.Model{ID: id}}
+	return auth.Get(d.engine)
+}
+
+func (d *Dao) CreateAuth(appKey, appSecret, scopes, createdBy string) (*model.Auth, error) {
+	auth := model.Auth{
+		AppKey:    appKey,
+		AppSecret: appSecret,
+		Scopes:    scopes,
+		State:     model.STATE_OPEN,
+		Model:     &model.Model{CreatedBy: createdBy},
+	}
+	return auth.Create(d.engine)
+}
+
+func (d *Dao) CountAuth() (int, error) {
+	auth := model.Auth{}
+	return auth.Count(d.engine)
+}
+
+func (d *Dao) GetAuthList(page, pageSize int) ([]*model.Auth, error) {
+	auth := model.Auth{}
+	return auth.List(d.engine, app.GetPageOffset(page, pageSize), pageSize)
+}
+
+// values 中仅包含需要更新的字段
+func (d *Dao) UpdateAuth(id uint32, values map[string]interface{}) error {
+	auth := model.Auth{Model: &model.Model{ID: id}}
+	return auth.Update(d.engine, values)
+}
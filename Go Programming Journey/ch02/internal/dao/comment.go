@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+)
+
+// 设置评论入参结构体
+type Comment struct {
+	ArticleID uint32
+	RootID    uint32
+	ParentID  uint32
+	Content   string
+	State     uint8
+	CreatedBy string
+}
+
+func (d *Dao) CreateComment(param *Comment) (*model.Comment, error) {
+	comment := model.Comment{
+		ArticleID: param.ArticleID,
+		RootID:    param.RootID,
+		ParentID:  param.ParentID,
+		Content:   param.Content,
+		State:     param.State,
+		Model:     &model.Model{CreatedBy: param.CreatedBy},
+	}
+	return comment.Create(d.engine)
+}
+
+func (d *Dao) GetComment(id uint32) (model.Comment, error) {
+	comment := model.Comment{Model: &model.Model{ID: id}}
+	return comment.Get(d.engine)
+}
+
+func (d *Dao) CountRootComment(articleID uint32, state uint8) (int, error) {
+	comment := model.Comment{ArticleID: articleID, State: state}
+	return comment.CountRoots(d.engine)
+}
+
+func (d *Dao) GetRootCommentList(articleID uint32, state uint8, page, pageSize int) ([]*model.Comment, error) {
+	comment := model.Comment{ArticleID: articleID, State: state}
+	return comment.ListRoots(d.engine, app.GetPageOffset(page, pageSize), pageSize)
+}
+
+func (d *Dao) GetCommentListByRootIDs(rootIDs []uint32, state uint8) ([]*model.Comment, error) {
+	comment := model.Comment{State: state}
+	return comment.ListByRootIDs(d.engine, rootIDs)
+}
+
+func (d *Dao) CountCommentByState(articleID uint32, state uint8) (int, error) {
+	comment := model.Comment{ArticleID: articleID, State: state}
+	return comment.CountByState(d.engine)
+}
+
+func (d *Dao) GetCommentListByState(articleID uint32, state uint8, page, pageSize int) ([]*model.Comment, error) {
+	comment := model.Comment{ArticleID: articleID, State: state}
+	return comment.ListByState(d.engine, app.GetPageOffset(page, pageSize), pageSize)
+}
+
+func (d *Dao) UpdateCommentState(id uint32, state uint8, modifiedBy string) error {
+	comment := model.Comment{Model: &model.Model{ID: id}}
+	values := map[string]interface{}{
+		"state":       state,
+		"modified_by": modifiedBy,
+	}
+	return comment.Update(d.engine, values)
+}
+
+func (d *Dao) DeleteComment(id uint32) error {
+	comment := model.Comment{Model: &model.Model{ID: id}}
+	return comment.Delete(d.engine)
+}
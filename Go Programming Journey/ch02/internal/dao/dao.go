@@ -9,3 +9,10 @@ type Dao struct {
 func New(engine *gorm.DB) *Dao {
 	return &Dao{engine: engine}
 }
+
+// 在事务中执行 fn，fn 返回错误时回滚，tx 中的操作均使用同一个事务
+func (d *Dao) Transaction(fn func(tx *Dao) error) error {
+	return d.engine.Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
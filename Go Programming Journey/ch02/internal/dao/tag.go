@@ -5,15 +5,15 @@ import (
 	"demo/ch02/pkg/app"
 )
 
-func (d *Dao) CountTag(name string, state uint8) (int, error) {
-	tag := model.Tag{Name: name, State: state}
-	return tag.Count(d.engine)
+func (d *Dao) CountTag(name, createdBy string, state uint8, spec *app.QuerySpec) (int, error) {
+	tag := model.Tag{Name: name, State: state, Model: &model.Model{CreatedBy: createdBy}}
+	return tag.Count(d.engine, spec)
 }
 
-func (d *Dao) GetTagList(name string, state uint8, page, pageSize int) ([]*model.Tag, error) {
-	tag := model.Tag{Name: name, State: state}
+func (d *Dao) GetTagList(name, createdBy string, state uint8, spec *app.QuerySpec, page, pageSize int) ([]*model.Tag, error) {
+	tag := model.Tag{Name: name, State: state, Model: &model.Model{CreatedBy: createdBy}}
 	pageOffset := app.GetPageOffset(page, pageSize)
-	return tag.List(d.engine, pageOffset, pageSize)
+	return tag.List(d.engine, spec, pageOffset, pageSize)
 }
 
 func (d *Dao) CreateTag(name string, state uint8, createdBy string) error {
@@ -29,6 +29,19 @@ func (d *Dao) UpdateTag(id uint32, name string, state uint8, modifiedBy string)
 	tag := model.Tag{
 		Model: &model.Model{ID: id},
 	}
+	return tag.Update(d.engine, tagValues(name, state, modifiedBy))
+}
+
+// 仅当标签的版本号仍为 version 时更新，标签已被修改时返回 false
+func (d *Dao) UpdateTagIfUnmodified(id uint32, name string, state uint8, modifiedBy string, version uint32) (bool, error) {
+	tag := model.Tag{
+		Model: &model.Model{ID: id},
+	}
+	return tag.UpdateIfUnmodified(d.engine, tagValues(name, state, modifiedBy), version)
+}
+
+// 更新标签时写入的字段，name 为空时保持不变
+func tagValues(name string, state uint8, modifiedBy string) map[string]interface{} {
 	values := map[string]interface{}{
 		"state":       state,
 		"modified_by": modifiedBy,
@@ -36,7 +49,7 @@ func (d *Dao) UpdateTag(id uint32, name string, state uint8, modifiedBy string)
 	if name != "" {
 		values["name"] = name
 	}
-	return tag.Update(d.engine, values)
+	return values
 }
 
 func (d *Dao) DeleteTag(id uint32) error {
@@ -50,6 +63,11 @@ func (d *Dao) GetTag(id uint32, state uint8) (*model.Tag, error) {
 	return tag.Get(d.engine)
 }
 
+func (d *Dao) GetTagByID(id uint32) (*model.Tag, error) {
+	tag := model.Tag{Model: &model.Model{ID: id}}
+	return tag.GetByID(d.engine)
+}
+
 func (d *Dao) GetTagListByIDs(ids []uint32, state uint8) ([]*model.Tag, error) {
 	tag := model.Tag{State: state}
 	return tag.ListByIDs(d.engine, ids)
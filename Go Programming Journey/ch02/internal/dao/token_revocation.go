@@ -0,0 +1,18 @@
+package dao
+
+import "demo/ch02/internal/model"
+
+func (d *Dao) CreateTokenRevocation(jti string, expiresOn uint32) error {
+	revocation := model.TokenRevocation{JTI: jti, ExpiresOn: expiresOn}
+	return revocation.Create(d.engine)
+}
+
+func (d *Dao) IsTokenRevoked(jti string) (bool, error) {
+	revocation := model.TokenRevocation{JTI: jti}
+	return revocation.Exists(d.engine)
+}
+
+func (d *Dao) DeleteExpiredTokenRevocation(now uint32) error {
+	revocation := model.TokenRevocation{}
+	return revocation.DeleteExpired(d.engine, now)
+}
@@ -0,0 +1,48 @@
+package dao
+
+import (
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+)
+
+func (d *Dao) CreateUser(username, password, role, createdBy string) (*model.User, error) {
+	user := model.User{
+		Username: username,
+		Password: password,
+		Role:     role,
+		State:    model.STATE_OPEN,
+		Model:    &model.Model{CreatedBy: createdBy},
+	}
+	return user.Create(d.engine)
+}
+
+func (d *Dao) GetUser(id uint32) (model.User, error) {
+	user := model.User{Model: &model.Model{ID: id}}
+	return user.Get(d.engine)
+}
+
+func (d *Dao) GetUserByUsername(username string) (model.User, error) {
+	user := model.User{Username: username}
+	return user.Get(d.engine)
+}
+
+func (d *Dao) CountUser(role string) (int, error) {
+	user := model.User{Role: role}
+	return user.Count(d.engine)
+}
+
+func (d *Dao) GetUserList(role string, page, pageSize int) ([]*model.User, error) {
+	user := model.User{Role: role}
+	return user.List(d.engine, app.GetPageOffset(page, pageSize), pageSize)
+}
+
+// values 中仅包含需要更新的字段
+func (d *Dao) UpdateUser(id uint32, values map[string]interface{}) error {
+	user := model.User{Model: &model.Model{ID: id}}
+	return user.Update(d.engine, values)
+}
+
+func (d *Dao) DeleteUser(id uint32) error {
+	user := model.User{Model: &model.Model{ID: id}}
+	return user.Delete(d.engine)
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"demo/ch02/global"
+	"demo/ch02/internal/service"
 	"demo/ch02/pkg/app"
 	"demo/ch02/pkg/errcode"
 	"github.com/dgrijalva/jwt-go"
@@ -9,21 +11,14 @@ import (
 
 func JWT() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var (
-			token string
-			ecode = errcode.Success
-		)
+		ecode := errcode.Success
 		// 获取 token
-		if s, exist := c.GetQuery("token"); exist {
-			token = s
-		} else {
-			token = c.GetHeader("token")
-		}
+		token := app.GetRequestToken(c)
 		if token == "" {
 			ecode = errcode.InvalidParams
 		} else {
 			// ParseToken() 解析 token
-			_, err := app.ParseToken(token)
+			claims, err := app.ParseToken(token)
 			if err != nil {
 				switch err.(*jwt.ValidationError).Errors {
 				case jwt.ValidationErrorExpired:
@@ -31,6 +26,19 @@ func JWT() gin.HandlerFunc {
 				default:
 					ecode = errcode.UnauthorizedTokenError
 				}
+			} else if claims.Name == "" || claims.TokenType != app.TokenTypeAccess || claims.Id == "" {
+				// 不包含调用方信息的 token 无法进行权限校验，refresh token 不能用于访问接口
+				ecode = errcode.UnauthorizedTokenError
+			} else if revoked, err := isTokenRevoked(c, claims.Id); err != nil {
+				global.Logger.Errorf(c, "svc.IsTokenRevoked err: %v", err)
+				ecode = errcode.ServerError
+			} else if revoked {
+				// 已注销的 token
+				ecode = errcode.UnauthorizedTokenError
+			} else {
+				// 将调用方写入上下文，供权限校验与后续的 Handler 使用
+				app.SetPrincipal(c, &claims.Principal)
+				app.SetClaims(c, claims)
 			}
 		}
 		if ecode != errcode.Success {
@@ -49,3 +57,8 @@ func JWT() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func isTokenRevoked(c *gin.Context, jti string) (bool, error) {
+	svc := service.New(c.Request.Context())
+	return svc.IsTokenRevoked(jti)
+}
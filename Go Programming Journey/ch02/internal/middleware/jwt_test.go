@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"demo/ch02/global"
+	"demo/ch02/internal/model"
+	"demo/ch02/internal/service"
+	"demo/ch02/migrations"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/errcode"
+	"demo/ch02/pkg/migrate"
+	"demo/ch02/pkg/setting"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+// 使用执行了全部迁移的 SQLite 数据库与 HS256 签名，测试结束后恢复全局变量
+func setupJWTTest(t *testing.T) {
+	oldSettings, oldDB := global.LoadSettings(), global.DBEngine
+	t.Cleanup(func() {
+		global.StoreSettings(oldSettings)
+		global.DBEngine = oldDB
+	})
+
+	global.UpdateSettings(func(s *global.Settings) {
+		s.Server = &setting.ServerSettingS{}
+		s.JWT = &setting.JWTSettingS{Secret: "secret", Expire: time.Hour, RefreshExpire: 2 * time.Hour}
+	})
+	db, err := model.NewDBEngine(&setting.DatabaseSettingS{
+		DBType: model.DBTypeSQLite,
+		DBName: filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("NewDBEngine err: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := migrate.NewMigrator(db.DB(), model.DBTypeSQLite, migrations.FS)
+	if err != nil {
+		t.Fatalf("NewMigrator err: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up err: %v", err)
+	}
+	global.DBEngine = db
+}
+
+func TestJWT(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupJWTTest(t)
+	principal := app.Principal{UserID: 1, Name: "root", Role: model.ROLE_ADMIN}
+	pair, err := app.GenerateTokenPair(principal)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair err: %v", err)
+	}
+	revoked, err := app.GenerateToken(principal, app.TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("GenerateToken err: %v", err)
+	}
+	claims, _ := app.ParseToken(revoked)
+	svc := service.New(context.Background())
+	if err := svc.RevokeToken(claims); err != nil {
+		t.Fatalf("RevokeToken err: %v", err)
+	}
+	sign := func(claims app.Claims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(app.GetJWTSecret())
+		if err != nil {
+			t.Fatalf("SignedString err: %v", err)
+		}
+		return s
+	}
+	expiresAt := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name      string
+		token     string
+		wantError *errcode.Error
+	}{
+		{name: "access token", token: pair.AccessToken},
+		{name: "missing token", token: "", wantError: errcode.InvalidParams},
+		{name: "malformed", token: "token", wantError: errcode.UnauthorizedTokenError},
+		// refresh token 只能用于换取新的 token
+		{name: "refresh token", token: pair.RefreshToken, wantError: errcode.UnauthorizedTokenError},
+		{name: "revoked", token: revoked, wantError: errcode.UnauthorizedTokenError},
+		{name: "expired", token: sign(app.Claims{
+			Principal: principal, TokenType: app.TokenTypeAccess,
+			StandardClaims: jwt.StandardClaims{Id: "expired", ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+		}), wantError: errcode.UnauthorizedTokenTimeout},
+		// 无法吊销或无法校验权限的 token
+		{name: "without jti", token: sign(app.Claims{
+			Principal: principal, TokenType: app.TokenTypeAccess,
+			StandardClaims: jwt.StandardClaims{ExpiresAt: expiresAt},
+		}), wantError: errcode.UnauthorizedTokenError},
+		{name: "without name", token: sign(app.Claims{
+			TokenType:      app.TokenTypeAccess,
+			StandardClaims: jwt.StandardClaims{Id: "anonymous", ExpiresAt: expiresAt},
+		}), wantError: errcode.UnauthorizedTokenError},
+		{name: "without token type", token: sign(app.Claims{
+			Principal:      principal,
+			StandardClaims: jwt.StandardClaims{Id: "untyped", ExpiresAt: expiresAt},
+		}), wantError: errcode.UnauthorizedTokenError},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		var got *app.Principal
+		r.GET("/", JWT(), func(c *gin.Context) {
+			got = app.GetPrincipal(c)
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.token != "" {
+			req.Header.Set("token", tt.token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if tt.wantError == nil {
+			if rec.Code != http.StatusOK || got == nil || got.Name != principal.Name || got.Role != principal.Role {
+				t.Errorf("%s: status = %d, principal = %+v", tt.name, rec.Code, got)
+			}
+			continue
+		}
+		if rec.Code != tt.wantError.StatusCode() {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.wantError.StatusCode())
+		}
+		var body struct {
+			Code int `json:"code"`
+		}
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		if body.Code != tt.wantError.Code() {
+			t.Errorf("%s: code = %d, want %d", tt.name, body.Code, tt.wantError.Code())
+		}
+		if got != nil {
+			t.Errorf("%s: handler called with principal %+v", tt.name, got)
+		}
+	}
+}
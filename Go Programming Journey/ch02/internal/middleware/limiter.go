@@ -5,21 +5,22 @@ import (
 	"demo/ch02/pkg/errcode"
 	"demo/ch02/pkg/limiter"
 	"github.com/gin-gonic/gin"
+	"strconv"
+	"time"
 )
 
 // 入参为 LimiterIface 接口类型，这样只要符合该接口类型的具体限流器实现都可以传入使用
+// 受限流的请求均返回 RateLimit-Limit 与 RateLimit-Remaining 响应头，被拒绝时另返回 Retry-After(秒)
 func RateLimiter(l limiter.LimiterIface) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		key := l.Key(c)
 		if bucket, ok := l.GetBucket(key); ok {
-			/*
-				TakeAvailable()
-				占用存储桶中立即可用的令牌的数量。
-				返回值为删除的令牌数，
-				如果没有可用的令牌，则返回零。它不会阻塞。
-			*/
-			count := bucket.TakeAvailable(1)
-			if count == 0 {
+			// 消耗一次请求对应的令牌，令牌不足时不会阻塞，也不会消耗部分令牌
+			result := bucket.Take(1)
+			c.Header("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+			c.Header("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+			if !result.Allowed {
+				c.Header("Retry-After", strconv.FormatInt(retryAfterSeconds(result.RetryAfter), 10))
 				response := app.NewResponse(c)
 				response.ToErrorResponse(errcode.TooManyRequests)
 				c.Abort()
@@ -29,3 +30,12 @@ func RateLimiter(l limiter.LimiterIface) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// Retry-After 只支持整数秒，向上取整且不小于 1
+func retryAfterSeconds(d time.Duration) int64 {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// 声明路由所需的权限，调用方的角色或 app_key 的权限范围需包含全部权限，需在 JWT 中间件之后注册
+func Permission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := app.GetPrincipal(c)
+		for _, perm := range perms {
+			if !model.PrincipalHasPermission(principal, perm) {
+				response := app.NewResponse(c)
+				response.ToErrorResponse(errcode.Forbidden.WithDetails("缺少权限 " + perm))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
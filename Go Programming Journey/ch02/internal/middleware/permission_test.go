@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"github.com/gin-gonic/gin"
+)
+
+func TestPermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		principal  *app.Principal
+		perms      []string
+		wantStatus int
+	}{
+		{name: "viewer read", principal: &app.Principal{UserID: 1, Role: model.ROLE_VIEWER}, perms: []string{model.PERM_TAG_READ}, wantStatus: http.StatusOK},
+		{name: "viewer write", principal: &app.Principal{UserID: 1, Role: model.ROLE_VIEWER}, perms: []string{model.PERM_TAG_WRITE}, wantStatus: http.StatusForbidden},
+		{name: "editor all of", principal: &app.Principal{UserID: 1, Role: model.ROLE_EDITOR}, perms: []string{model.PERM_TAG_READ, model.PERM_TAG_WRITE}, wantStatus: http.StatusOK},
+		{name: "editor missing one", principal: &app.Principal{UserID: 1, Role: model.ROLE_EDITOR}, perms: []string{model.PERM_TAG_WRITE, model.PERM_USER_MANAGE}, wantStatus: http.StatusForbidden},
+		{name: "admin", principal: &app.Principal{UserID: 1, Role: model.ROLE_ADMIN}, perms: []string{model.PERM_USER_MANAGE}, wantStatus: http.StatusOK},
+		{name: "app scope", principal: &app.Principal{Role: model.ROLE_APP, Scopes: []string{model.PERM_UPLOAD}}, perms: []string{model.PERM_UPLOAD}, wantStatus: http.StatusOK},
+		{name: "app missing scope", principal: &app.Principal{Role: model.ROLE_APP, Scopes: []string{model.PERM_UPLOAD}}, perms: []string{model.PERM_TAG_READ}, wantStatus: http.StatusForbidden},
+		{name: "app all", principal: &app.Principal{Role: model.ROLE_APP, Scopes: []string{model.SCOPE_ALL}}, perms: []string{model.PERM_APP_KEY_MANAGE}, wantStatus: http.StatusOK},
+		// 未经过 JWT 中间件时没有任何权限
+		{name: "anonymous", perms: []string{model.PERM_TAG_READ}, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		r.GET("/", func(c *gin.Context) {
+			if tt.principal != nil {
+				app.SetPrincipal(c, tt.principal)
+			}
+		}, Permission(tt.perms...), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.wantStatus)
+		}
+	}
+}
@@ -11,14 +11,15 @@ import (
 )
 
 func Recovery() gin.HandlerFunc {
+	s := global.EmailSetting()
 	// 转换为邮件结构体
 	defailtMailer := email.NewEmail(&email.SMTPInfo{
-		Host:     global.EmailSetting.Host,
-		Port:     global.EmailSetting.Port,
-		IsSSL:    global.EmailSetting.IsSSL,
-		UserName: global.EmailSetting.UserName,
-		Password: global.EmailSetting.Password,
-		From:     global.EmailSetting.From,
+		Host:     s.Host,
+		Port:     s.Port,
+		IsSSL:    s.IsSSL,
+		UserName: s.UserName,
+		Password: s.Password,
+		From:     s.From,
 	})
 	return func(c *gin.Context) {
 		defer func() {
@@ -26,7 +27,7 @@ func Recovery() gin.HandlerFunc {
 				global.Logger.WithCallersFrames().Errorf(c, "panic recover err: %v", err)
 				// 发送邮件
 				err := defailtMailer.SendMail(
-					global.EmailSetting.To,
+					s.To,
 					fmt.Sprintf("异常抛出，发生时间: %d", time.Now().Unix()),
 					fmt.Sprintf("错误信息: %v", err),
 				)
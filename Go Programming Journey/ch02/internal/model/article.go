@@ -2,6 +2,7 @@ package model
 
 import (
 	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/search"
 	"github.com/jinzhu/gorm"
 )
 
@@ -12,23 +13,53 @@ type Article struct {
 	Content       string `json:"content"`
 	CoverImageUrl string `json:"cover_image_url"`
 	State         uint8  `json:"state"`
+	PublishAt     uint32 `json:"publish_at"` // 发布时间，定时发布的文章为计划发布时间
+	Version       uint32 `json:"-"`          // 版本号，每次更新时由回调加 1
 }
 
+// 文章生命周期状态，已归档与已发布沿用 STATE_CLOSE、STATE_OPEN 的取值以兼容已有数据
+const (
+	ARTICLE_STATE_ARCHIVED  = STATE_CLOSE
+	ARTICLE_STATE_PUBLISHED = STATE_OPEN
+	ARTICLE_STATE_DRAFT     = 2
+	ARTICLE_STATE_IN_REVIEW = 3
+	ARTICLE_STATE_SCHEDULED = 4
+)
+
 // article.go
 type ArticleSwagger struct {
 	List  []*Article
 	Pager *app.Pager
 }
 
+// 文章列表允许的筛选、排序与返回字段
+var ArticleQueryRule = &app.QueryRule{
+	Filters: map[string][]string{
+		"id":          {app.OpEq, app.OpIn},
+		"title":       {app.OpEq, app.OpNe, app.OpLike},
+		"created_by":  {app.OpEq, app.OpNe, app.OpLike, app.OpIn},
+		"created_on":  {app.OpEq, app.OpGt, app.OpGte, app.OpLt, app.OpLte},
+		"modified_on": {app.OpEq, app.OpGt, app.OpGte, app.OpLt, app.OpLte},
+		"publish_at":  {app.OpEq, app.OpGt, app.OpGte, app.OpLt, app.OpLte},
+	},
+	Sorts:  []string{"id", "title", "created_on", "modified_on", "publish_at"},
+	Fields: []string{"id", "title", "desc", "content", "cover_image_url", "state", "publish_at", "tags", "created_by", "created_on", "modified_on"},
+	Keyset: true,
+}
+
 func (a Article) TableName() string {
 	return "blog_article"
 }
 
-func (a Article) CountArticle(db *gorm.DB) (int, error) {
+func (a Article) CountArticle(db *gorm.DB, spec *app.QuerySpec) (int, error) {
 	var count int
 	if a.Title != "" {
 		db = db.Where("title = ?", a.Title)
 	}
+	if a.Model != nil && a.CreatedBy != "" {
+		db = db.Where("created_by = ?", a.CreatedBy)
+	}
+	db = db.Scopes(spec.Where)
 	db = db.Where("state = ?", a.State)
 	if err := db.Model(&a).Where("is_del = ?", 0).Count(&count).Error; err != nil {
 		return 0, err
@@ -47,23 +78,43 @@ func (a Article) Get(db *gorm.DB) (Article, error) {
 	return article, nil
 }
 
-func (a Article) List(db *gorm.DB, pageOffset, pageSize int) ([]*Article, error) {
+func (a Article) List(db *gorm.DB, spec *app.QuerySpec, pageOffset, pageSize int) ([]*Article, error) {
 	var articles []*Article
 	var err error
 
-	if pageOffset >= 0 && pageSize >= 0 {
-		db.Offset(pageOffset).Limit(pageSize)
-	}
 	if a.Title != "" {
 		db = db.Where("title = ?", a.Title)
 	}
+	if a.Model != nil && a.CreatedBy != "" {
+		db = db.Where("created_by = ?", a.CreatedBy)
+	}
 	db = db.Where("state = ?", a.State)
+	// Paginate 根据是否使用游标选择 OFFSET 分页或游标分页
+	db = db.Scopes(spec.Where, spec.Order, spec.Paginate(pageOffset, pageSize))
 	if err = db.Where("is_del = ?", 0).Find(&articles).Error; err != nil {
 		return nil, err
 	}
 	return articles, err
 }
 
+// 获取指定 ID 的文章，不限制文章状态
+func (a Article) ListByIDs(db *gorm.DB, ids []uint32) ([]*Article, error) {
+	var articles []*Article
+	if err := db.Where("id IN (?) AND is_del = ?", ids, 0).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// 获取全部未删除的文章，用于构建内置的全文索引
+func (a Article) ListAll(db *gorm.DB) ([]*Article, error) {
+	var articles []*Article
+	if err := db.Where("is_del = ?", 0).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
 func (a Article) Create(db *gorm.DB) (*Article, error) {
 	if err := db.Create(&a).Error; err != nil {
 		return nil, err
@@ -75,61 +126,141 @@ func (a Article) Update(db *gorm.DB, values interface{}) error {
 	return db.Model(&a).Where("is_del = ? and id = ?", 0, a.ID).Update(values).Error
 }
 
+// 仅当文章的版本号仍为 version 时更新，返回是否已更新，用于 If-Match 的条件更新
+func (a Article) UpdateIfUnmodified(db *gorm.DB, values interface{}, version uint32) (bool, error) {
+	db = db.Model(&a).Where("is_del = ? and id = ? and version = ?", 0, a.ID, version).Updates(values)
+	if db.Error != nil {
+		return false, db.Error
+	}
+	return db.RowsAffected > 0, nil
+}
+
 func (a Article) Delete(db *gorm.DB) error {
 	return db.Where("is_del = ? and id = ?", 0, a.ID).Delete(&a).Error
 }
 
-// 此处为标签与文章结合处理
-type ArticleRow struct {
-	ArticleID     uint32
-	TagID         uint32
-	TagName       string
-	ArticleTitle  string
-	ArticleDesc   string
-	CoverImageUrl string
-	Content       string
+// 获取计划发布时间不晚于 publishAt 的定时发布文章，按计划发布时间排序
+func (a Article) ListDue(db *gorm.DB, publishAt uint32, limit int) ([]*Article, error) {
+	var articles []*Article
+	err := db.Where("state = ? AND publish_at <= ? AND is_del = ?", ARTICLE_STATE_SCHEDULED, publishAt, 0).
+		Order("publish_at, id").Limit(limit).Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// 将定时发布的文章改为已发布，仅当文章仍处于定时发布状态时才会更新
+// 避免覆盖期间被手动修改的状态，多个实例同时执行时也只有一个会成功
+func (a Article) Publish(db *gorm.DB) (bool, error) {
+	db = db.Model(&a).Where("is_del = ? AND id = ? AND state = ?", 0, a.ID, ARTICLE_STATE_SCHEDULED).
+		Updates(map[string]interface{}{"state": ARTICLE_STATE_PUBLISHED, "modified_by": a.ModifiedBy})
+	if db.Error != nil {
+		return false, db.Error
+	}
+	return db.RowsAffected > 0, nil
+}
+
+// 文章与标签为多对多关系，通过子查询筛选出关联了指定标签的文章
+// 避免联表后一篇文章因关联多个标签而重复出现，保证分页与总数准确
+func (a Article) ListByTagID(db *gorm.DB, tagID uint32, spec *app.QuerySpec, pageOffset, pageSize int) ([]*Article, error) {
+	var articles []*Article
+	if a.Model != nil && a.CreatedBy != "" {
+		db = db.Where("created_by = ?", a.CreatedBy)
+	}
+	// 未指定排序时按 ID 倒序返回
+	err := db.Where("id IN ? AND state = ? AND is_del = ?", articleIDsByTagID(db, tagID), a.State, 0).
+		Scopes(spec.Where, spec.Order, spec.Paginate(pageOffset, pageSize)).
+		Order("id DESC").
+		Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return articles, nil
+}
+
+func (a Article) CountByTagID(db *gorm.DB, tagID uint32, spec *app.QuerySpec) (int, error) {
+	var count int
+	if a.Model != nil && a.CreatedBy != "" {
+		db = db.Where("created_by = ?", a.CreatedBy)
+	}
+	err := db.Model(&a).
+		Where("id IN ? AND state = ? AND is_del = ?", articleIDsByTagID(db, tagID), a.State, 0).
+		Scopes(spec.Where).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// 关联了指定标签的文章 ID 子查询
+func articleIDsByTagID(db *gorm.DB, tagID uint32) *gorm.SqlExpr {
+	return db.New().Table(ArticleTag{}.TableName()).
+		Select("article_id").
+		Where("tag_id = ? AND is_del = ?", tagID, 0).
+		SubQuery()
+}
+
+// 全文索引的名称，由 migrations/mysql/000002_article_fulltext.up.sql 创建
+const articleFullTextIndex = "ft_blog_article"
+
+// 检查文章表是否存在全文索引，仅 MySQL 支持
+func (a Article) HasFullTextIndex(db *gorm.DB) (bool, error) {
+	if db.Dialect().GetName() != DBTypeMySQL {
+		return false, nil
+	}
+	var count int
+	err := db.Table("information_schema.STATISTICS").
+		Where("table_schema = DATABASE() AND table_name = ? AND index_name = ? AND index_type = ?", a.TableName(), articleFullTextIndex, "FULLTEXT").
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
 }
 
-func (a Article) ListByTagID(db *gorm.DB, tagID uint32, pageOffset, pageSize int) ([]*ArticleRow, error) {
-	fields := []string{"ar.id AS article_id", "ar.title AS article_title", "ar.desc AS article_desc", "ar.cover_image_url", "ar.content"}
-	fields = append(fields, []string{"t.id AS tag_id", "t.name AS tag_name"}...)
+// MATCH 中的字段需要与全文索引中的字段完全一致
+func matchArticle(db *gorm.DB) string {
+	return "MATCH(title, " + quote(db, "desc") + ", content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+}
 
+// 使用 MySQL 全文索引搜索文章，按相关度从高到低排序
+func (a Article) Search(db *gorm.DB, query string, pageOffset, pageSize int) ([]search.Hit, error) {
 	if pageOffset >= 0 && pageSize > 0 {
 		db = db.Offset(pageOffset).Limit(pageSize)
 	}
-	rows, err := db.Select(fields).Table(ArticleTag{}.TableName()+" AS at").
-		Joins("LEFT JOIN `"+Tag{}.TableName()+"` AS t ON at.tag_id = t.id").
-		Joins("LEFT JOIN `"+Article{}.TableName()+"` AS ar ON at.article_id = ar.id").
-		Where("at.`tag_id` = ? AND ar.state = ? AND ar.is_del = ?", tagID, a.State, 0).
+	match := matchArticle(db)
+	rows, err := db.Table(a.TableName()).
+		Select("id, "+match+" AS score", query).
+		Where(match+" AND state = ? AND is_del = ?", query, a.State, 0).
+		Order("score DESC, id DESC").
 		Rows()
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var articles []*ArticleRow
+	var hits []search.Hit
 	for rows.Next() {
-		r := &ArticleRow{}
-		if err := rows.Scan(&r.ArticleID, &r.ArticleTitle, &r.ArticleDesc, &r.CoverImageUrl, &r.Content, &r.TagID, &r.TagName); err != nil {
+		var hit search.Hit
+		if err := rows.Scan(&hit.ID, &hit.Score); err != nil {
 			return nil, err
 		}
-
-		articles = append(articles, r)
+		hits = append(hits, hit)
 	}
-
-	return articles, nil
+	return hits, rows.Err()
 }
 
-func (a Article) CountByTagID(db *gorm.DB, tagID uint32) (int, error) {
+func (a Article) CountSearch(db *gorm.DB, query string) (int, error) {
 	var count int
-	err := db.Table(ArticleTag{}.TableName()+" AS at").
-		Joins("LEFT JOIN `"+Tag{}.TableName()+"` AS t ON at.tag_id = t.id").
-		Joins("LEFT JOIN `"+Article{}.TableName()+"` AS ar ON at.article_id = ar.id").
-		Where("at.`tag_id` = ? AND ar.state = ? AND ar.is_del = ?", tagID, a.State, 0).
+	err := db.Model(&a).
+		Where(matchArticle(db)+" AND state = ? AND is_del = ?", query, a.State, 0).
 		Count(&count).Error
 	if err != nil {
 		return 0, err
 	}
-
 	return count, nil
 }
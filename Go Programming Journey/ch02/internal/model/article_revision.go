@@ -0,0 +1,58 @@
+package model
+
+import "github.com/jinzhu/gorm"
+
+// 文章修订版本，保存文章在某次创建或更新后的完整内容
+type ArticleRevision struct {
+	*Model
+	ArticleID     uint32 `json:"article_id"`
+	Title         string `json:"title"`
+	Desc          string `json:"desc"`
+	Content       string `json:"content"`
+	CoverImageUrl string `json:"cover_image_url"`
+}
+
+func (r ArticleRevision) TableName() string {
+	return "blog_article_revision"
+}
+
+func (r ArticleRevision) Create(db *gorm.DB) (*ArticleRevision, error) {
+	if err := db.Create(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (r ArticleRevision) Get(db *gorm.DB) (ArticleRevision, error) {
+	var revision ArticleRevision
+	err := db.Where("id = ? AND article_id = ? AND is_del = ?", r.ID, r.ArticleID, 0).First(&revision).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return revision, err
+	}
+	return revision, nil
+}
+
+func (r ArticleRevision) CountByAID(db *gorm.DB) (int, error) {
+	var count int
+	if err := db.Model(&r).Where("article_id = ? AND is_del = ?", r.ArticleID, 0).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// 按修订时间倒序获取文章的修订版本，列表中不包含正文
+func (r ArticleRevision) ListByAID(db *gorm.DB, pageOffset, pageSize int) ([]*ArticleRevision, error) {
+	var revisions []*ArticleRevision
+	if pageOffset >= 0 && pageSize > 0 {
+		db = db.Offset(pageOffset).Limit(pageSize)
+	}
+	fields := []string{"id", "article_id", "title", quote(db, "desc"), "cover_image_url", "created_on", "created_by"}
+	err := db.Select(fields).
+		Where("article_id = ? AND is_del = ?", r.ArticleID, 0).
+		Order("id DESC").
+		Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
@@ -12,15 +12,14 @@ func (a ArticleTag) TableName() string {
 	return "blog_article_tag"
 }
 
-// 文章与标签之间的联合处理
-func (a ArticleTag) GetByAID(db *gorm.DB) (ArticleTag, error) {
-	var articleTag ArticleTag
-	err := db.Where("article_id = ? AND is_del = ?", a.ArticleID, 0).First(&articleTag).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return articleTag, err
+// 文章与标签之间为多对多关系，获取文章关联的全部标签
+func (a ArticleTag) ListByAID(db *gorm.DB) ([]*ArticleTag, error) {
+	var articleTags []*ArticleTag
+	if err := db.Where("article_id = ? AND is_del = ?", a.ArticleID, 0).Find(&articleTags).Error; err != nil {
+		return nil, err
 	}
 
-	return articleTag, nil
+	return articleTags, nil
 }
 
 func (a ArticleTag) ListByTID(db *gorm.DB) ([]*ArticleTag, error) {
@@ -50,24 +49,26 @@ func (a ArticleTag) Create(db *gorm.DB) error {
 	return nil
 }
 
-func (a ArticleTag) UpdateOne(db *gorm.DB, values interface{}) error {
-	if err := db.Model(&a).Where("article_id = ? AND is_del = ?", a.ArticleID, 0).Limit(1).Updates(values).Error; err != nil {
+func (a ArticleTag) Delete(db *gorm.DB) error {
+	if err := db.Where("id = ? AND is_del = ?", a.Model.ID, 0).Delete(&a).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (a ArticleTag) Delete(db *gorm.DB) error {
-	if err := db.Where("id = ? AND is_del = ?", a.Model.ID, 0).Delete(&a).Error; err != nil {
+// 删除文章与指定标签之间的关联
+func (a ArticleTag) DeleteByTIDs(db *gorm.DB, tagIDs []uint32) error {
+	if err := db.Where("article_id = ? AND tag_id IN (?) AND is_del = ?", a.ArticleID, tagIDs, 0).Delete(&a).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (a ArticleTag) DeleteOne(db *gorm.DB) error {
-	if err := db.Where("article_id = ? AND is_del = ?", a.ArticleID, 0).Delete(&a).Limit(1).Error; err != nil {
+// 删除文章关联的全部标签
+func (a ArticleTag) DeleteByAID(db *gorm.DB) error {
+	if err := db.Where("article_id = ? AND is_del = ?", a.ArticleID, 0).Delete(&a).Error; err != nil {
 		return err
 	}
 
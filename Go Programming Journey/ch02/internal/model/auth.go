@@ -1,24 +1,71 @@
 package model
 
-import "github.com/jinzhu/gorm"
+import (
+	"demo/ch02/pkg/app"
+	"github.com/jinzhu/gorm"
+)
 
+// AppSecret 为 bcrypt 哈希后的 secret，旧数据可能仍为明文，首次认证成功后重新哈希
+// Scopes 为逗号分隔的权限，* 为全部权限
 type Auth struct {
 	*Model
 	AppKey    string `json:"app_key"`
-	AppSecret string `json:"app_secret"`
+	AppSecret string `json:"-"`
+	Scopes    string `json:"scopes"`
+	State     uint8  `json:"state"`
 }
 
 func (a Auth) TableName() string {
 	return "blog_auth"
 }
 
-// 通过传入的 app_key 和 app_secret 获取认证信息
+// 按 ID 获取认证信息，ID 为 0 时按 app_key 获取
 func (a Auth) Get(db *gorm.DB) (Auth, error) {
 	var auth Auth
-	db = db.Where("app_key = ? AND app_secret = ? AND is_del = ?", a.AppKey, a.AppSecret, 0)
-	err := db.First(&auth).Error
+	if a.Model != nil && a.ID > 0 {
+		db = db.Where("id = ?", a.ID)
+	} else {
+		db = db.Where("app_key = ?", a.AppKey)
+	}
+	err := db.Where("is_del = ?", 0).First(&auth).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return auth, err
 	}
 	return auth, nil
 }
+
+func (a Auth) Create(db *gorm.DB) (*Auth, error) {
+	if err := db.Create(&a).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (a Auth) Count(db *gorm.DB) (int, error) {
+	var count int
+	if err := db.Model(&a).Where("is_del = ?", 0).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (a Auth) List(db *gorm.DB, pageOffset, pageSize int) ([]*Auth, error) {
+	var auths []*Auth
+	if pageOffset >= 0 && pageSize > 0 {
+		db = db.Offset(pageOffset).Limit(pageSize)
+	}
+	if err := db.Where("is_del = ?", 0).Order("id").Find(&auths).Error; err != nil {
+		return nil, err
+	}
+	return auths, nil
+}
+
+func (a Auth) Update(db *gorm.DB, values interface{}) error {
+	return db.Model(&a).Where("id = ? AND is_del = ?", a.ID, 0).Updates(values).Error
+}
+
+// auth.go
+type AuthSwagger struct {
+	List  []*Auth
+	Pager *app.Pager
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"demo/ch02/pkg/app"
+	"github.com/jinzhu/gorm"
+)
+
+// 文章评论，顶层评论的 RootID 与 ParentID 均为 0
+// 回复的 RootID 为所属的顶层评论，ParentID 为直接回复的评论，同一楼层的回复通过 RootID 一次查出
+type Comment struct {
+	*Model
+	ArticleID uint32 `json:"article_id"`
+	RootID    uint32 `json:"root_id"`
+	ParentID  uint32 `json:"parent_id"`
+	Content   string `json:"content"`
+	State     uint8  `json:"state"`
+}
+
+// 评论审核状态
+const (
+	COMMENT_STATE_PENDING  = 0
+	COMMENT_STATE_APPROVED = 1
+	COMMENT_STATE_REJECTED = 2
+)
+
+// comment.go
+type CommentSwagger struct {
+	List  []*Comment
+	Pager *app.Pager
+}
+
+func (c Comment) TableName() string {
+	return "blog_comment"
+}
+
+func (c Comment) Create(db *gorm.DB) (*Comment, error) {
+	if err := db.Create(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c Comment) Get(db *gorm.DB) (Comment, error) {
+	var comment Comment
+	err := db.Where("id = ? AND is_del = ?", c.ID, 0).First(&comment).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return comment, err
+	}
+	return comment, nil
+}
+
+// 统计文章下指定状态的顶层评论数量
+func (c Comment) CountRoots(db *gorm.DB) (int, error) {
+	var count int
+	err := db.Model(&c).
+		Where("article_id = ? AND root_id = ? AND state = ? AND is_del = ?", c.ArticleID, 0, c.State, 0).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// 按发表时间倒序获取文章下指定状态的顶层评论
+func (c Comment) ListRoots(db *gorm.DB, pageOffset, pageSize int) ([]*Comment, error) {
+	var comments []*Comment
+	if pageOffset >= 0 && pageSize > 0 {
+		db = db.Offset(pageOffset).Limit(pageSize)
+	}
+	err := db.Where("article_id = ? AND root_id = ? AND state = ? AND is_del = ?", c.ArticleID, 0, c.State, 0).
+		Order("id DESC").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// 按发表时间顺序获取多个顶层评论下指定状态的回复
+func (c Comment) ListByRootIDs(db *gorm.DB, rootIDs []uint32) ([]*Comment, error) {
+	var comments []*Comment
+	err := db.Where("root_id IN (?) AND state = ? AND is_del = ?", rootIDs, c.State, 0).
+		Order("id").Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// 统计指定状态的评论数量，ArticleID 不为 0 时仅统计该文章下的评论
+func (c Comment) CountByState(db *gorm.DB) (int, error) {
+	var count int
+	if c.ArticleID != 0 {
+		db = db.Where("article_id = ?", c.ArticleID)
+	}
+	if err := db.Model(&c).Where("state = ? AND is_del = ?", c.State, 0).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// 按发表时间顺序获取指定状态的评论，用于审核队列
+func (c Comment) ListByState(db *gorm.DB, pageOffset, pageSize int) ([]*Comment, error) {
+	var comments []*Comment
+	if pageOffset >= 0 && pageSize > 0 {
+		db = db.Offset(pageOffset).Limit(pageSize)
+	}
+	if c.ArticleID != 0 {
+		db = db.Where("article_id = ?", c.ArticleID)
+	}
+	if err := db.Where("state = ? AND is_del = ?", c.State, 0).Order("id").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (c Comment) Update(db *gorm.DB, values interface{}) error {
+	return db.Model(&c).Where("id = ? AND is_del = ?", c.ID, 0).Updates(values).Error
+}
+
+// 删除评论，删除顶层评论时同时删除该楼层下的全部回复
+func (c Comment) Delete(db *gorm.DB) error {
+	return db.Where("(id = ? OR root_id = ?) AND is_del = ?", c.ID, c.ID, 0).Delete(&Comment{}).Error
+}
@@ -6,10 +6,16 @@ import (
 	"fmt"
 	otgorm "github.com/eddycjy/opentracing-gorm"
 	"github.com/jinzhu/gorm"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
 	"time"
 
-	// 引入 MYSQL驱动库进行初始化
+	// 引入 MYSQL、PostgreSQL、SQLite 驱动库进行初始化
 	_ "github.com/jinzhu/gorm/dialects/mysql"
+	_ "github.com/jinzhu/gorm/dialects/postgres"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
 )
 
 const (
@@ -17,6 +23,17 @@ const (
 	STATE_CLOSE = 0
 )
 
+// 支持的数据库类型，与 gorm 的 dialect 名称一致
+const (
+	DBTypeMySQL    = "mysql"
+	DBTypePostgres = "postgres"
+	DBTypeSQLite   = "sqlite3"
+)
+
+// 表名前缀，各模型的 TableName() 与 migrations 目录下的迁移文件均使用该前缀
+// Database.TablePrefix 仅能配置为该值，配置为其他值时无法启动
+const TablePrefix = "blog_"
+
 // 公共字段
 type Model struct {
 	ID         uint32 `gorm:"primary_key" json:"id"`
@@ -28,21 +45,35 @@ type Model struct {
 	IsDel      uint8  `json:"is_del"`
 }
 
+// 资源的最后修改时间，未修改过时为创建时间，实现 app.LastModifier
+func (m *Model) LastModified() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	if m.ModifiedOn > 0 {
+		return time.Unix(int64(m.ModifiedOn), 0)
+	}
+	if m.CreatedOn > 0 {
+		return time.Unix(int64(m.CreatedOn), 0)
+	}
+	return time.Time{}
+}
+
 // 新增 NewDBEngine()
 func NewDBEngine(databaseSetting *setting.DatabaseSettingS) (*gorm.DB, error) {
-	// gorm.Open() 初始化一个 MySQL 连接，首先需要导入驱动
-	db, err := gorm.Open(databaseSetting.DBType, fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=%s&parseTime=%t&loc=Local",
-		databaseSetting.UserName,
-		databaseSetting.Password,
-		databaseSetting.Host,
-		databaseSetting.DBName,
-		databaseSetting.Charset,
-		databaseSetting.ParseTime,
-	))
+	if databaseSetting.TablePrefix != "" && databaseSetting.TablePrefix != TablePrefix {
+		return nil, fmt.Errorf("Database.TablePrefix %q is not supported, table names and migrations use the fixed prefix %q", databaseSetting.TablePrefix, TablePrefix)
+	}
+	dsn, err := buildDSN(databaseSetting)
+	if err != nil {
+		return nil, err
+	}
+	// gorm.Open() 根据 DBType 初始化对应的数据库连接，首先需要导入驱动
+	db, err := gorm.Open(databaseSetting.DBType, dsn)
 	if err != nil {
 		return nil, err
 	}
-	if global.ServerSetting.RunMode == "debug" {
+	if global.ServerSetting().RunMode == "debug" {
 		// 显示日志输出
 		db.LogMode(true)
 	}
@@ -58,11 +89,56 @@ func NewDBEngine(databaseSetting *setting.DatabaseSettingS) (*gorm.DB, error) {
 	db.DB().SetMaxIdleConns(databaseSetting.MaxIdleConns)
 	// 设置数据库的最大打开连接数。
 	db.DB().SetMaxOpenConns(databaseSetting.MaxOpenConns)
+	if databaseSetting.DBType == DBTypeSQLite {
+		// SQLite 同一时间只允许一个写入者，使用单个连接避免 database is locked 错误
+		db.DB().SetMaxOpenConns(1)
+	}
 	// 添加 OpenTracing 注册回调
 	otgorm.AddGormCallbacks(db)
 	return db, nil
 }
 
+// 根据数据库类型构造对应格式的 DSN
+func buildDSN(databaseSetting *setting.DatabaseSettingS) (string, error) {
+	switch databaseSetting.DBType {
+	case DBTypeMySQL:
+		// clientFoundRows 使 RowsAffected 为匹配的行数，与其他数据库一致，条件更新的值未变化时也视为更新成功
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=%s&parseTime=%t&loc=Local&clientFoundRows=true",
+			databaseSetting.UserName,
+			databaseSetting.Password,
+			databaseSetting.Host,
+			databaseSetting.DBName,
+			databaseSetting.Charset,
+			databaseSetting.ParseTime,
+		), nil
+	case DBTypePostgres:
+		// 使用 URL 格式，由 url.UserPassword 对账号密码中的特殊字符进行转义
+		host := databaseSetting.Host
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, "5432")
+		}
+		sslMode := databaseSetting.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(databaseSetting.UserName, databaseSetting.Password),
+			Host:     host,
+			Path:     "/" + databaseSetting.DBName,
+			RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+		}
+		return dsn.String(), nil
+	case DBTypeSQLite:
+		// SQLite 中 DBName 为数据库文件路径，e.g. storage/ch02.db
+		if err := os.MkdirAll(filepath.Dir(databaseSetting.DBName), os.ModePerm); err != nil {
+			return "", err
+		}
+		return databaseSetting.DBName + "?_busy_timeout=5000", nil
+	}
+	return "", fmt.Errorf("unsupported database type: %s", databaseSetting.DBType)
+}
+
 // 更新时间戳的新增行为的回调
 // 当对数据库执行任何操作时，Scope 包含当前操作的信息
 // Scope 允许复用通用的逻辑
@@ -95,6 +171,11 @@ func updateTimeStampForUpdateCallback(scope *gorm.Scope) {
 		// 若不存在，即未自定义设置 update_column
 		// 设置默认字段 ModifiedOn 的值为当前时间戳
 		_ = scope.SetColumn("ModifiedOn", time.Now().Unix())
+		// 带有版本号的模型每次 Update 时版本号加 1，条件更新比较版本号以区分同一秒内的修改
+		field, hasVersion := scope.FieldByName("Version")
+		if attrs, ok := scope.InstanceGet("gorm:update_attrs"); ok && hasVersion {
+			attrs.(map[string]interface{})[field.DBName] = gorm.Expr(scope.Quote(field.DBName)+" + ?", 1)
+		}
 	}
 }
 
@@ -110,6 +191,11 @@ func deleteCallback(scope *gorm.Scope) {
 		deletedOnField, hasDeletedOnField := scope.FieldByName("DeletedOn")
 		isDelField, hasIsDelField := scope.FieldByName("IsDel")
 
+		// 只有 MySQL 支持在 UPDATE 与 DELETE 中使用 ORDER BY 与 LIMIT，其他数据库需要将其去除
+		if scope.Dialect().GetName() != DBTypeMySQL {
+			scope.Search.Order(nil, true).Limit(nil).Offset(nil)
+		}
+
 		// 若存在执行 UPDATE 进行软删除
 		if !scope.Search.Unscoped && hasDeletedOnField && hasIsDelField {
 			now := time.Now().Unix()
@@ -148,3 +234,8 @@ func addExtraSpaceIfExist(str string) string {
 	}
 	return ""
 }
+
+// 按当前数据库的规则引用标识符，MySQL 中为 `name`，PostgreSQL 与 SQLite 中为 "name"
+func quote(db *gorm.DB, name string) string {
+	return db.Dialect().Quote(name)
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+
+	"demo/ch02/global"
+	"demo/ch02/pkg/setting"
+)
+
+func TestNewDBEngineTablePrefix(t *testing.T) {
+	oldSettings := global.LoadSettings()
+	defer global.StoreSettings(oldSettings)
+	global.UpdateSettings(func(s *global.Settings) { s.Server = &setting.ServerSettingS{} })
+	tests := []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{prefix: "", wantErr: false},
+		{prefix: TablePrefix, wantErr: false},
+		{prefix: "app_", wantErr: true},
+	}
+	for _, tt := range tests {
+		db, err := NewDBEngine(&setting.DatabaseSettingS{
+			DBType:      DBTypeSQLite,
+			DBName:      filepath.Join(t.TempDir(), "test.db"),
+			TablePrefix: tt.prefix,
+		})
+		if db != nil {
+			db.Close()
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("TablePrefix %q: NewDBEngine err = %v, wantErr %v", tt.prefix, err, tt.wantErr)
+		}
+	}
+}
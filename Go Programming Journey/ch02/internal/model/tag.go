@@ -7,8 +7,9 @@ import (
 
 type Tag struct {
 	*Model
-	Name  string `json:"name"`
-	State uint8  `json:"state"`
+	Name    string `json:"name"`
+	State   uint8  `json:"state"`
+	Version uint32 `json:"-"` // 版本号，每次更新时由回调加 1
 }
 
 // tag.go
@@ -17,17 +18,35 @@ type TagSwagger struct {
 	Pager *app.Pager
 }
 
+// 标签列表允许的筛选、排序与返回字段
+var TagQueryRule = &app.QueryRule{
+	Filters: map[string][]string{
+		"id":          {app.OpEq, app.OpIn},
+		"name":        {app.OpEq, app.OpNe, app.OpLike, app.OpIn},
+		"created_by":  {app.OpEq, app.OpNe, app.OpLike, app.OpIn},
+		"created_on":  {app.OpEq, app.OpGt, app.OpGte, app.OpLt, app.OpLte},
+		"modified_on": {app.OpEq, app.OpGt, app.OpGte, app.OpLt, app.OpLte},
+	},
+	Sorts:  []string{"id", "name", "created_on", "modified_on"},
+	Fields: []string{"id", "name", "state", "created_by", "modified_by", "created_on", "modified_on"},
+}
+
 func (t Tag) TableName() string {
 	return "blog_tag"
 }
 
 // 使用 db *grom.DB 作为函数首参数传入
-func (t Tag) Count(db *gorm.DB) (int, error) {
+func (t Tag) Count(db *gorm.DB, spec *app.QuerySpec) (int, error) {
 	var count int
 	if t.Name != "" {
 		// Where 设置筛选条件，接受 map、struct、string作为条件
 		db = db.Where("name = ?", t.Name)
 	}
+	if t.Model != nil && t.CreatedBy != "" {
+		db = db.Where("created_by = ?", t.CreatedBy)
+	}
+	// 通用查询参数中的筛选条件
+	db = db.Scopes(spec.Where)
 	db = db.Where("state = ?", t.State)
 	// Model 指定运行 DB 操作的模型实例，默认解析该结构体的名字为表名
 	// Count 统计行为，用于统计模型的记录数
@@ -37,7 +56,7 @@ func (t Tag) Count(db *gorm.DB) (int, error) {
 	return count, nil
 }
 
-func (t Tag) List(db *gorm.DB, pageOffset, pageSize int) ([]*Tag, error) {
+func (t Tag) List(db *gorm.DB, spec *app.QuerySpec, pageOffset, pageSize int) ([]*Tag, error) {
 	var tags []*Tag
 	var err error
 	if pageOffset >= 0 && pageSize > 0 {
@@ -48,7 +67,12 @@ func (t Tag) List(db *gorm.DB, pageOffset, pageSize int) ([]*Tag, error) {
 	if t.Name != "" {
 		db = db.Where("name = ?", t.Name)
 	}
+	if t.Model != nil && t.CreatedBy != "" {
+		db = db.Where("created_by = ?", t.CreatedBy)
+	}
 	db = db.Where("state = ?", t.State)
+	// Scopes 将通用查询参数中的筛选与排序条件应用到当前查询
+	db = db.Scopes(spec.Where, spec.Order)
 	// Find 有两个参数，out 是数据接收者，where 是查询条件，可以代替 Where 来传入条件
 	// err = e.g. db.Find(&tags, "is_del = 0").Error
 	if err = db.Where("is_del = ?", 0).Find(&tags).Error; err != nil {
@@ -67,19 +91,44 @@ func (t Tag) Update(db *gorm.DB, values interface{}) error {
 	return db.Model(&t).Where("id = ? AND is_del = ?", t.ID, 0).Updates(values).Error
 }
 
+// 仅当标签的版本号仍为 version 时更新，返回是否已更新，用于 If-Match 的条件更新
+func (t Tag) UpdateIfUnmodified(db *gorm.DB, values interface{}, version uint32) (bool, error) {
+	db = db.Model(&t).Where("id = ? AND is_del = ? AND version = ?", t.ID, 0, version).Updates(values)
+	if db.Error != nil {
+		return false, db.Error
+	}
+	return db.RowsAffected > 0, nil
+}
+
 func (t Tag) Delete(db *gorm.DB) error {
 	// Delete 删除数据
 	return db.Where("id = ? AND is_del = ?", t.Model.ID, 0).Delete(&t).Error
 }
 
-// 文章管理新增代码
+// 文章管理新增代码，未找到时返回 nil
 func (t Tag) Get(db *gorm.DB) (*Tag, error) {
-	var tag *Tag
+	var tag Tag
 	err := db.Where("id = ? and is_del = ? and state = ?", t.ID, 0, t.State).First(&tag).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return tag, err
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// 按 ID 获取标签，不区分状态，未找到时返回 nil
+func (t Tag) GetByID(db *gorm.DB) (*Tag, error) {
+	var tag Tag
+	err := db.Where("id = ? AND is_del = ?", t.ID, 0).First(&tag).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	return tag, nil
+	return &tag, nil
 }
 
 func (t Tag) ListByIDs(db *gorm.DB, ids []uint32) ([]*Tag, error) {
@@ -0,0 +1,32 @@
+package model
+
+import "github.com/jinzhu/gorm"
+
+// 已吊销的 token，ExpiresOn 与 token 的过期时间一致，过期后记录即可清理
+type TokenRevocation struct {
+	JTI       string `gorm:"column:jti;primary_key" json:"jti"`
+	ExpiresOn uint32 `json:"expires_on"`
+	CreatedOn uint32 `json:"created_on"`
+}
+
+func (t TokenRevocation) TableName() string {
+	return "blog_token_revocation"
+}
+
+// jti 为主键，重复吊销同一个 token 时返回错误
+func (t TokenRevocation) Create(db *gorm.DB) error {
+	return db.Create(&t).Error
+}
+
+func (t TokenRevocation) Exists(db *gorm.DB) (bool, error) {
+	var count int
+	if err := db.Model(&t).Where("jti = ?", t.JTI).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// 删除 expiresOn 之前过期的记录
+func (t TokenRevocation) DeleteExpired(db *gorm.DB, expiresOn uint32) error {
+	return db.Where("expires_on < ?", expiresOn).Delete(&t).Error
+}
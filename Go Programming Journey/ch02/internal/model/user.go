@@ -0,0 +1,170 @@
+package model
+
+import (
+	"demo/ch02/pkg/app"
+	"github.com/jinzhu/gorm"
+)
+
+// 用户，Password 为 bcrypt 哈希后的密码，不会出现在接口返回中
+type User struct {
+	*Model
+	Username string `json:"username"`
+	Password string `json:"-"`
+	Role     string `json:"role"`
+	State    uint8  `json:"state"`
+}
+
+// 用户角色
+const (
+	ROLE_ADMIN  = "admin"
+	ROLE_EDITOR = "editor"
+	ROLE_VIEWER = "viewer"
+)
+
+// 接口权限，路由通过 middleware.Permission 声明所需的权限
+const (
+	PERM_TAG_READ         = "tag:read"
+	PERM_TAG_WRITE        = "tag:write"
+	PERM_ARTICLE_READ     = "article:read"
+	PERM_ARTICLE_WRITE    = "article:write"
+	PERM_COMMENT_READ     = "comment:read"
+	PERM_COMMENT_WRITE    = "comment:write"
+	PERM_COMMENT_MODERATE = "comment:moderate"
+	PERM_UPLOAD           = "upload"
+	PERM_USER_MANAGE      = "user:manage"
+	PERM_APP_KEY_MANAGE   = "app_key:manage"
+	PERM_METRICS_READ     = "metrics:read"
+)
+
+// 通过 app_key 认证的调用方的角色，其权限由 app_key 的 Scopes 决定
+const ROLE_APP = "app"
+
+// app_key 的全部权限
+const SCOPE_ALL = "*"
+
+// 可以授予 app_key 的权限
+var allPermissions = []string{
+	PERM_TAG_READ, PERM_TAG_WRITE, PERM_ARTICLE_READ, PERM_ARTICLE_WRITE,
+	PERM_COMMENT_READ, PERM_COMMENT_WRITE, PERM_COMMENT_MODERATE,
+	PERM_UPLOAD, PERM_USER_MANAGE, PERM_APP_KEY_MANAGE, PERM_METRICS_READ,
+}
+
+// 各角色拥有的权限，admin 拥有全部权限
+var rolePermissions = map[string][]string{
+	ROLE_VIEWER: {
+		PERM_TAG_READ, PERM_ARTICLE_READ, PERM_COMMENT_READ, PERM_COMMENT_WRITE,
+	},
+	ROLE_EDITOR: {
+		PERM_TAG_READ, PERM_ARTICLE_READ, PERM_COMMENT_READ, PERM_COMMENT_WRITE,
+		PERM_TAG_WRITE, PERM_ARTICLE_WRITE, PERM_COMMENT_MODERATE, PERM_UPLOAD,
+	},
+}
+
+// 判断角色是否拥有指定权限
+func HasPermission(role, perm string) bool {
+	if role == ROLE_ADMIN {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断是否为可以授予 app_key 的权限
+func IsScope(scope string) bool {
+	if scope == SCOPE_ALL {
+		return true
+	}
+	for _, p := range allPermissions {
+		if p == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断 app_key 的权限范围是否包含指定权限
+func HasScope(scopes []string, perm string) bool {
+	for _, scope := range scopes {
+		if scope == SCOPE_ALL || scope == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// 判断调用方是否拥有指定权限，通过 app_key 认证时按权限范围判断，否则按角色判断
+func PrincipalHasPermission(principal *app.Principal, perm string) bool {
+	if principal.Role == ROLE_APP {
+		return HasScope(principal.Scopes, perm)
+	}
+	return HasPermission(principal.Role, perm)
+}
+
+// user.go
+type UserSwagger struct {
+	List  []*User
+	Pager *app.Pager
+}
+
+func (u User) TableName() string {
+	return "blog_user"
+}
+
+func (u User) Create(db *gorm.DB) (*User, error) {
+	if err := db.Create(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// 按 ID 获取用户，ID 为 0 时按用户名获取
+func (u User) Get(db *gorm.DB) (User, error) {
+	var user User
+	if u.Model != nil && u.ID > 0 {
+		db = db.Where("id = ?", u.ID)
+	} else {
+		db = db.Where("username = ?", u.Username)
+	}
+	err := db.Where("is_del = ?", 0).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return user, err
+	}
+	return user, nil
+}
+
+func (u User) Count(db *gorm.DB) (int, error) {
+	var count int
+	if u.Role != "" {
+		db = db.Where("role = ?", u.Role)
+	}
+	if err := db.Model(&u).Where("is_del = ?", 0).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (u User) List(db *gorm.DB, pageOffset, pageSize int) ([]*User, error) {
+	var users []*User
+	if pageOffset >= 0 && pageSize > 0 {
+		db = db.Offset(pageOffset).Limit(pageSize)
+	}
+	if u.Role != "" {
+		db = db.Where("role = ?", u.Role)
+	}
+	if err := db.Where("is_del = ?", 0).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (u User) Update(db *gorm.DB, values interface{}) error {
+	return db.Model(&u).Where("id = ? AND is_del = ?", u.ID, 0).Updates(values).Error
+}
+
+func (u User) Delete(db *gorm.DB) error {
+	return db.Where("id = ? AND is_del = ?", u.ID, 0).Delete(&u).Error
+}
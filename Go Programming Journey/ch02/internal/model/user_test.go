@@ -0,0 +1,94 @@
+package model
+
+import (
+	"testing"
+
+	"demo/ch02/pkg/app"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		role string
+		perm string
+		want bool
+	}{
+		{role: ROLE_ADMIN, perm: PERM_USER_MANAGE, want: true},
+		{role: ROLE_ADMIN, perm: PERM_METRICS_READ, want: true},
+		{role: ROLE_EDITOR, perm: PERM_ARTICLE_WRITE, want: true},
+		{role: ROLE_EDITOR, perm: PERM_UPLOAD, want: true},
+		{role: ROLE_EDITOR, perm: PERM_USER_MANAGE, want: false},
+		{role: ROLE_EDITOR, perm: PERM_APP_KEY_MANAGE, want: false},
+		{role: ROLE_VIEWER, perm: PERM_ARTICLE_READ, want: true},
+		{role: ROLE_VIEWER, perm: PERM_COMMENT_WRITE, want: true},
+		{role: ROLE_VIEWER, perm: PERM_ARTICLE_WRITE, want: false},
+		{role: ROLE_VIEWER, perm: PERM_COMMENT_MODERATE, want: false},
+		// app_key 的权限由权限范围决定，不按角色授予
+		{role: ROLE_APP, perm: PERM_TAG_READ, want: false},
+		{role: "", perm: PERM_TAG_READ, want: false},
+	}
+	for _, tt := range tests {
+		if got := HasPermission(tt.role, tt.perm); got != tt.want {
+			t.Errorf("HasPermission(%q, %q) = %v, want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestIsScope(t *testing.T) {
+	tests := []struct {
+		scope string
+		want  bool
+	}{
+		{scope: SCOPE_ALL, want: true},
+		{scope: PERM_TAG_WRITE, want: true},
+		{scope: PERM_METRICS_READ, want: true},
+		{scope: "tag:delete", want: false},
+		{scope: ROLE_ADMIN, want: false},
+		{scope: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsScope(tt.scope); got != tt.want {
+			t.Errorf("IsScope(%q) = %v, want %v", tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		perm   string
+		want   bool
+	}{
+		{scopes: []string{SCOPE_ALL}, perm: PERM_USER_MANAGE, want: true},
+		{scopes: []string{PERM_TAG_READ, PERM_TAG_WRITE}, perm: PERM_TAG_WRITE, want: true},
+		{scopes: []string{PERM_TAG_READ}, perm: PERM_TAG_WRITE, want: false},
+		{scopes: []string{"tag:*"}, perm: PERM_TAG_READ, want: false},
+		{scopes: nil, perm: PERM_TAG_READ, want: false},
+	}
+	for _, tt := range tests {
+		if got := HasScope(tt.scopes, tt.perm); got != tt.want {
+			t.Errorf("HasScope(%v, %q) = %v, want %v", tt.scopes, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestPrincipalHasPermission(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *app.Principal
+		perm      string
+		want      bool
+	}{
+		{name: "user role", principal: &app.Principal{UserID: 1, Role: ROLE_EDITOR}, perm: PERM_TAG_WRITE, want: true},
+		{name: "user missing role permission", principal: &app.Principal{UserID: 1, Role: ROLE_VIEWER}, perm: PERM_TAG_WRITE, want: false},
+		// 通过用户认证时忽略 Scopes
+		{name: "user with scopes", principal: &app.Principal{UserID: 1, Role: ROLE_VIEWER, Scopes: []string{SCOPE_ALL}}, perm: PERM_TAG_WRITE, want: false},
+		{name: "app scope", principal: &app.Principal{Role: ROLE_APP, Scopes: []string{PERM_TAG_WRITE}}, perm: PERM_TAG_WRITE, want: true},
+		{name: "app missing scope", principal: &app.Principal{Role: ROLE_APP, Scopes: []string{PERM_TAG_READ}}, perm: PERM_TAG_WRITE, want: false},
+		{name: "anonymous", principal: &app.Principal{}, perm: PERM_TAG_READ, want: false},
+	}
+	for _, tt := range tests {
+		if got := PrincipalHasPermission(tt.principal, tt.perm); got != tt.want {
+			t.Errorf("%s: PrincipalHasPermission = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -21,23 +21,147 @@ func GetAuth(c *gin.Context) {
 
 	// 判断认证信息
 	svc := service.New(c.Request.Context())
-	err := svc.CheckAuth(&param)
+	principal, err := svc.CheckAuth(&param)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.CheckAuth err: %v", err)
 		response.ToErrorResponse(errcode.UnauthorizedAuthNotExist)
 		return
 	}
 
-	// 生成 token
-	token, err := app.GenerateToken(param.AppKey, param.AppSecret)
+	// 生成 access token 与 refresh token
+	pair, err := app.GenerateTokenPair(*principal)
 	if err != nil {
-		global.Logger.Errorf(c, "app.GenerateToken err: %v", err)
+		global.Logger.Errorf(c, "app.GenerateTokenPair err: %v", err)
 		response.ToErrorResponse(errcode.UnauthorizedTokenGenerate)
 		return
 	}
 
 	// 返回生成的 token
-	response.ToResponse(gin.H{
-		"token": token,
-	})
+	response.ToResponse(pair)
+}
+
+// Login @Summary 用户登录
+// @Produce json
+// @Param username body string true "用户名"
+// @Param password body string true "密码"
+// @Success 200 {object} app.TokenPair "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 401 {object} errcode.Error "用户名或密码错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /auth/login [post]
+func Login(c *gin.Context) {
+	param := service.LoginRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	principal, err := svc.Login(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.Login err: %v", err)
+		if err == service.ErrInvalidCredentials {
+			response.ToErrorResponse(errcode.UnauthorizedUserError)
+			return
+		}
+		response.ToErrorResponse(errcode.ServerError)
+		return
+	}
+
+	pair, err := app.GenerateTokenPair(*principal)
+	if err != nil {
+		global.Logger.Errorf(c, "app.GenerateTokenPair err: %v", err)
+		response.ToErrorResponse(errcode.UnauthorizedTokenGenerate)
+		return
+	}
+
+	response.ToResponse(pair)
+}
+
+// RefreshToken @Summary 刷新 token
+// @Produce json
+// @Param refresh_token body string true "refresh token，使用后即失效"
+// @Success 200 {object} app.TokenPair "成功，返回新的 token 对"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 401 {object} errcode.Error "refresh token 无效、已过期或已使用"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /auth/refresh [post]
+func RefreshToken(c *gin.Context) {
+	param := service.RefreshTokenRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	principal, err := svc.RefreshToken(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.RefreshToken err: %v", err)
+		if err == service.ErrInvalidToken {
+			response.ToErrorResponse(errcode.UnauthorizedTokenError)
+			return
+		}
+		response.ToErrorResponse(errcode.ServerError)
+		return
+	}
+
+	pair, err := app.GenerateTokenPair(*principal)
+	if err != nil {
+		global.Logger.Errorf(c, "app.GenerateTokenPair err: %v", err)
+		response.ToErrorResponse(errcode.UnauthorizedTokenGenerate)
+		return
+	}
+
+	response.ToResponse(pair)
+}
+
+// Logout @Summary 注销
+// @Produce json
+// @Param token header string true "access token"
+// @Param refresh_token body string false "同时吊销的 refresh token"
+// @Success 200 {string} string "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 401 {object} errcode.Error "token 无效"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	param := service.LogoutRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	err := svc.Logout(app.GetClaims(c), &param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.Logout err: %v", err)
+		if err == service.ErrInvalidToken {
+			response.ToErrorResponse(errcode.UnauthorizedTokenError)
+			return
+		}
+		response.ToErrorResponse(errcode.ServerError)
+		return
+	}
+
+	response.ToResponse(gin.H{})
+}
+
+// GetJWKS @Summary 获取校验 token 的公钥
+// @Produce json
+// @Success 200 {object} app.JWKSet "成功，使用 HS256 签名时 keys 为空"
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(c *gin.Context) {
+	// 公钥仅在轮换时变化，允许客户端短时间缓存
+	c.Header("Cache-Control", "public, max-age=300")
+	response := app.NewResponse(c)
+	response.ToResponse(app.JWKSet{Keys: app.JWKS()})
 }
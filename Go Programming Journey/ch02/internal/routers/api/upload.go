@@ -6,8 +6,13 @@ import (
 	"demo/ch02/pkg/app"
 	"demo/ch02/pkg/convert"
 	"demo/ch02/pkg/errcode"
+	"demo/ch02/pkg/storage"
 	"demo/ch02/pkg/upload"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
 )
 
 type Upload struct{}
@@ -18,6 +23,13 @@ func NewUpload() Upload {
 
 func (u Upload) UploadFile(c *gin.Context) {
 	response := app.NewResponse(c)
+	// 限制请求体的大小，超过限制的请求在读取时即返回错误，不会整体读入内存或写入临时文件
+	maxRequestSize := upload.MaxRequestSize()
+	if c.Request.ContentLength > maxRequestSize {
+		response.ToErrorResponse(errcode.ErrorUploadFileTooLarge)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestSize)
 	// 参数获取与检测
 	// 通过 c.Request.FormFile() 读取入参 file 字段的上传文件信息
 	file, fileHeader, err := c.Request.FormFile("file")
@@ -31,18 +43,81 @@ func (u Upload) UploadFile(c *gin.Context) {
 		response.ToErrorResponse(errcode.InvalidParams)
 		return
 	}
+	// private 为 true 时保存为私有文件，只能通过有时效的签名地址下载
+	private, err := strconv.ParseBool(c.DefaultPostForm("private", "false"))
+	if err != nil {
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(err.Error()))
+		return
+	}
 
 	// 调用 service 方法完成文件上传、文件保存，并返回文件展示地址
 	svc := service.New(c.Request.Context())
-	fileInfo, err := svc.UploadFile(upload.FileType(fileType), file, fileHeader)
+	defer file.Close()
+	fileInfo, err := svc.UploadFile(upload.FileType(fileType), file, fileHeader.Filename, private)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.UploadFile err: %v", err)
-		response.ToErrorResponse(errcode.ErrorUploadFileFail.WithDetails(err.Error()))
+		switch err {
+		case upload.ErrFileTooLarge:
+			response.ToErrorResponse(errcode.ErrorUploadFileTooLarge)
+		case upload.ErrUnsupportedExt, upload.ErrContentMismatch, upload.ErrInvalidImage:
+			response.ToErrorResponse(errcode.ErrorUploadFileType.WithDetails(err.Error()))
+		case upload.ErrImageDimensions:
+			response.ToErrorResponse(errcode.ErrorUploadImageSize)
+		default:
+			response.ToErrorResponse(errcode.ErrorUploadFileFail.WithDetails(err.Error()))
+		}
 		return
 	}
 
-	// 返回文件展示地址
-	response.ToResponse(gin.H{
+	// 返回文件展示地址，私有文件同时返回地址的过期时间，图片同时返回宽高与缩略图等版本的地址
+	data := gin.H{
+		"file_key":        fileInfo.Name,
 		"file_access_url": fileInfo.AccessUrl,
-	})
+	}
+	if fileInfo.ExpiresAt > 0 {
+		data["expires_at"] = fileInfo.ExpiresAt
+	}
+	if fileType == int(upload.TypeImage) {
+		data["width"] = fileInfo.Width
+		data["height"] = fileInfo.Height
+		variants := fileInfo.Variants
+		if variants == nil {
+			variants = []*service.FileVariant{}
+		}
+		data["variants"] = variants
+	}
+	response.ToResponse(data)
+}
+
+// 下载保存在本服务中的文件，私有文件需携带有效的签名
+// 存储后端为 s3 时文件由对象存储提供下载，该接口返回 404
+func (u Upload) Download(c *gin.Context) {
+	response := app.NewResponse(c)
+	// 先去除 .. 等路径再判断是否为私有文件，避免绕过签名校验
+	key := strings.TrimPrefix(path.Clean(c.Param("key")), "/")
+	server, ok := global.Storage.(storage.Server)
+	if !ok {
+		response.ToErrorResponse(errcode.NotFound)
+		return
+	}
+	if storage.IsPrivate(key) && !server.Verify(key, c.Request.URL.Query()) {
+		response.ToErrorResponse(errcode.Forbidden.WithDetails("下载地址无效或已过期"))
+		return
+	}
+	file, modTime, err := server.Open(c.Request.Context(), key)
+	if err == storage.ErrNotExist {
+		response.ToErrorResponse(errcode.NotFound)
+		return
+	}
+	if err != nil {
+		global.Logger.Errorf(c, "server.Open err: %v", err)
+		response.ToErrorResponse(errcode.ServerError)
+		return
+	}
+	defer file.Close()
+	// 文件名为内容的摘要，内容不会变化，公开文件允许客户端长期缓存
+	if !storage.IsPrivate(key) {
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	http.ServeContent(c.Writer, c.Request, path.Base(key), modTime, file)
 }
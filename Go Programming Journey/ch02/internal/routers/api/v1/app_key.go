@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"demo/ch02/global"
+	"demo/ch02/internal/service"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/convert"
+	"demo/ch02/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type AppKey struct{}
+
+func NewAppKey() AppKey {
+	return AppKey{}
+}
+
+// List @Summary 获取 app_key 列表
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} model.AuthSwagger "成功，不包含 secret"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/app_keys [get]
+func (a AppKey) List(c *gin.Context) {
+	response := app.NewResponse(c)
+	svc := service.New(c.Request.Context())
+	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
+	appKeys, totalRows, err := svc.GetAppKeyList(&pager)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.GetAppKeyList err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGetAppKeyListFail)
+		return
+	}
+
+	response.ToResponseList(appKeys, totalRows)
+	return
+}
+
+// Create @Summary 创建 app_key
+// @Produce json
+// @Param app_key body string false "app_key，未传入时随机生成" minlength(4) maxlength(20)
+// @Param scopes body []string true "权限范围，可用逗号分隔，* 为全部权限"
+// @Success 200 {object} service.AppKeyCredential "成功，app_secret 仅在此时返回一次"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/app_keys [post]
+func (a AppKey) Create(c *gin.Context) {
+	param := service.CreateAppKeyRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.Operator = app.GetPrincipal(c)
+	param.CreatedBy = param.Operator.Name
+	svc := service.New(c.Request.Context())
+	credential, err := svc.CreateAppKey(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.CreateAppKey err: %v", err)
+		response.ToErrorResponse(appKeyError(err, errcode.ErrorCreateAppKeyFail))
+		return
+	}
+
+	response.ToResponse(credential)
+	return
+}
+
+// Update @Summary 更新 app_key 的权限范围或状态
+// @Produce json
+// @Param id path int true "app_key ID"
+// @Param scopes body []string false "权限范围，未传入时保持不变"
+// @Param state body int false "状态 0 为禁用、1 为启用" Enums(0, 1)
+// @Success 200 {string} string "成功，已签发的 token 在刷新时按新的设置生效"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 404 {object} errcode.Error "app_key 不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/app_keys/{id} [put]
+func (a AppKey) Update(c *gin.Context) {
+	param := service.UpdateAppKeyRequest{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.Operator = app.GetPrincipal(c)
+	param.ModifiedBy = param.Operator.Name
+	svc := service.New(c.Request.Context())
+	err := svc.UpdateAppKey(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.UpdateAppKey err: %v", err)
+		response.ToErrorResponse(appKeyError(err, errcode.ErrorUpdateAppKeyFail))
+		return
+	}
+
+	response.ToResponse(gin.H{})
+	return
+}
+
+// Rotate @Summary 轮换 app_key 的 secret
+// @Produce json
+// @Param id path int true "app_key ID"
+// @Success 200 {object} service.AppKeyCredential "成功，旧的 secret 立即失效，新的 app_secret 仅在此时返回一次"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 404 {object} errcode.Error "app_key 不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/app_keys/{id}/rotate [post]
+func (a AppKey) Rotate(c *gin.Context) {
+	param := service.RotateAppKeyRequest{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.Operator = app.GetPrincipal(c)
+	param.ModifiedBy = param.Operator.Name
+	svc := service.New(c.Request.Context())
+	credential, err := svc.RotateAppKey(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.RotateAppKey err: %v", err)
+		response.ToErrorResponse(appKeyError(err, errcode.ErrorRotateAppKeyFail))
+		return
+	}
+
+	response.ToResponse(credential)
+	return
+}
+
+// app_key 不存在时返回 404，超出调用方权限时返回 403，重复、权限范围不合法或修改自己时返回入参错误
+func appKeyError(err error, fail *errcode.Error) *errcode.Error {
+	switch err {
+	case service.ErrAppKeyNotFound:
+		return errcode.NotFound.WithDetails(err.Error())
+	case service.ErrAppKeyDenied:
+		return errcode.Forbidden.WithDetails(err.Error())
+	case service.ErrAppKeyExists, service.ErrAppKeyScope, service.ErrAppKeySelf:
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+	return fail
+}
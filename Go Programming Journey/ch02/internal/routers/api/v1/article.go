@@ -2,10 +2,12 @@ package v1
 
 import (
 	"demo/ch02/global"
+	"demo/ch02/internal/model"
 	"demo/ch02/internal/service"
 	"demo/ch02/pkg/app"
 	"demo/ch02/pkg/convert"
 	"demo/ch02/pkg/errcode"
+	"errors"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,8 +20,12 @@ func NewArticle() Article {
 // Get @Summary 获取单个文章
 // @Produce json
 // @Param id path int true "文章ID"
+// @Param If-None-Match header string false "上次返回的 ETag，未变化时返回 304"
+// @Param If-Modified-Since header string false "上次返回的 Last-Modified，未修改时返回 304"
 // @Success 200 {object} model.Article "成功"
+// @Success 304 {string} string "未修改"
 // @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "文章不存在"
 // @Failure 500 {object} errcode.Error "内部错误"
 // @Router /api/v1/articles/{id} [get]
 func (a Article) Get(c *gin.Context) {
@@ -38,10 +44,15 @@ func (a Article) Get(c *gin.Context) {
 	article, err := svc.GetArticleWithTag(&param)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.GetArticle err: %v", err)
+		if err == service.ErrArticleNotFound {
+			response.ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
+			return
+		}
 		response.ToErrorResponse(errcode.ErrorGetArticleFail)
 		return
 	}
 
+	// service.Article 实现了 app.LastModifier，同时返回 ETag 与 Last-Modified
 	response.ToResponse(article)
 	return
 }
@@ -50,7 +61,12 @@ func (a Article) Get(c *gin.Context) {
 // @Produce json
 // @Param name query string false "文章名称"
 // @Param tag_id query int false "标签ID"
-// @Param state query int false "状态"
+// @Param state query int false "状态 0 为已归档、1 为已发布、2 为草稿、3 为审核中、4 为定时发布，默认为已发布"
+// @Param created_by query string false "创建者"
+// @Param sort query string false "排序字段，多个字段以逗号分隔，- 前缀表示倒序，e.g. -created_on,title"
+// @Param filter[field][op] query string false "筛选条件，op 可选 eq、ne、gt、gte、lt、lte、like、in，e.g. filter[created_on][gte]=1650000000"
+// @Param fields query string false "返回字段，多个字段以逗号分隔，e.g. id,title,tags"
+// @Param cursor query string false "游标，传入空值或上一页返回的 next_cursor 时使用游标分页，按创建时间倒序排列"
 // @Param page query int false "页码"
 // @Param page_size query int false "每页数量"
 // @Success 200 {object} model.ArticleSwagger "成功"
@@ -67,28 +83,73 @@ func (a Article) List(c *gin.Context) {
 		return
 	}
 
+	spec, errs := app.BindQuerySpec(c, model.ArticleQueryRule)
+	if len(errs) > 0 {
+		global.Logger.Errorf(c, "app.BindQuerySpec errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
 	svc := service.New(c.Request.Context())
 	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
-	articles, articleCount, err := svc.GetArticleListWithTag(&param, &pager)
+	articles, articleCount, err := svc.GetArticleListWithTag(&param, spec, &pager)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.GetArticleList err: %v", err)
 		response.ToErrorResponse(errcode.ErrorGetArticlesFail)
 		return
 	}
+	list, err := spec.Project(articles)
+	if err != nil {
+		global.Logger.Errorf(c, "spec.Project err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGetArticlesFail)
+		return
+	}
+
+	response.ToResponseCursorList(list, articleCount, pager.NextCursor)
+	return
+}
+
+// Search @Summary 搜索文章
+// @Produce json
+// @Param q query string true "搜索关键词"
+// @Param state query int false "状态 0 为已归档、1 为已发布、2 为草稿、3 为审核中、4 为定时发布，默认为已发布"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} model.ArticleSwagger "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/articles/search [get]
+func (a Article) Search(c *gin.Context) {
+	param := service.ArticleSearchRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
+	articles, totalRows, err := svc.SearchArticles(&param, &pager)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.SearchArticles err: %v", err)
+		response.ToErrorResponse(errcode.ErrorSearchArticleFail)
+		return
+	}
 
-	response.ToResponseList(articles, articleCount)
+	response.ToResponseList(articles, totalRows)
 	return
 }
 
 // Create @Summary 创建文章
 // @Produce json
-// @Param tag_id body string true "标签ID"
+// @Param tag_ids body []int true "标签ID列表"
 // @Param title body string true "文章标题"
 // @Param desc body string false "文章简述"
 // @Param cover_image_url body string true "封面图片地址"
 // @Param content body string true "文章内容"
-// @Param created_by body int true "创建者"
-// @Param state body int false "状态"
+// @Param state body int false "状态 2 为草稿、3 为提交审核，默认为草稿"
 // @Success 200 {object} model.Article "成功"
 // @Failure 400 {object} errcode.Error "请求错误"
 // @Failure 500 {object} errcode.Error "内部错误"
@@ -103,6 +164,7 @@ func (a Article) Create(c *gin.Context) {
 		return
 	}
 
+	param.CreatedBy = app.GetPrincipal(c).Name
 	svc := service.New(c.Request.Context())
 	err := svc.CreateArticle(&param)
 	if err != nil {
@@ -117,14 +179,19 @@ func (a Article) Create(c *gin.Context) {
 
 // Update @Summary 更新文章
 // @Produce json
-// @Param tag_id body string false "标签ID"
+// @Param tag_ids body []int false "标签ID列表，未传入时保持不变"
 // @Param title body string false "文章标题"
 // @Param desc body string false "文章简述"
 // @Param cover_image_url body string false "封面图片地址"
 // @Param content body string false "文章内容"
-// @Param modified_by body string true "修改者"
+// @Param state body int false "状态 0 为已归档、1 为已发布、2 为草稿、3 为审核中、4 为定时发布，未传入时保持不变"
+// @Param publish_at body int false "计划发布时间，变更为定时发布时必填"
+// @Param If-Match header string false "获取文章时返回的 ETag，文章已被修改时返回 412"
 // @Success 200 {object} model.Article "成功"
 // @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "文章不存在"
+// @Failure 409 {object} errcode.Error "文章状态变更不合法"
+// @Failure 412 {object} errcode.Error "文章已被修改"
 // @Failure 500 {object} errcode.Error "内部错误"
 // @Router /api/v1/articles/{id} [put]
 func (a Article) Update(c *gin.Context) {
@@ -137,11 +204,22 @@ func (a Article) Update(c *gin.Context) {
 		return
 	}
 
+	param.ModifiedBy = app.GetPrincipal(c).Name
+	param.IfMatch = c.GetHeader("If-Match")
 	svc := service.New(c.Request.Context())
 	err := svc.UpdateArticle(&param)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.UpdateArticle err: %v", err)
-		response.ToErrorResponse(errcode.ErrorUpdateArticleFail)
+		switch {
+		case err == service.ErrArticleNotFound:
+			response.ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
+		case errors.Is(err, service.ErrArticleStateTransition):
+			response.ToErrorResponse(errcode.ErrorArticleStateTransition.WithDetails(err.Error()))
+		case err == service.ErrPreconditionFailed:
+			response.ToErrorResponse(errcode.PreconditionFailed)
+		default:
+			response.ToErrorResponse(errcode.ErrorUpdateArticleFail)
+		}
 		return
 	}
 
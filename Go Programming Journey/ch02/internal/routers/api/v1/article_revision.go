@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"demo/ch02/global"
+	"demo/ch02/internal/service"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/convert"
+	"demo/ch02/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type ArticleRevision struct{}
+
+func NewArticleRevision() ArticleRevision {
+	return ArticleRevision{}
+}
+
+// List @Summary 获取文章修订历史
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {array} model.ArticleRevision "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/articles/{id}/revisions [get]
+func (r ArticleRevision) List(c *gin.Context) {
+	param := service.ArticleRevisionListRequest{ArticleID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
+	revisions, totalRows, err := svc.GetArticleRevisionList(&param, &pager)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.GetArticleRevisionList err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGetArticleRevisionsFail)
+		return
+	}
+
+	response.ToResponseList(revisions, totalRows)
+	return
+}
+
+// Get @Summary 获取文章的单个修订版本
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param revision_id path int true "修订版本ID"
+// @Success 200 {object} model.ArticleRevision "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "修订版本不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/articles/{id}/revisions/{revision_id} [get]
+func (r ArticleRevision) Get(c *gin.Context) {
+	param := service.ArticleRevisionRequest{
+		ArticleID:  convert.StrTo(c.Param("id")).MustUInt32(),
+		RevisionID: convert.StrTo(c.Param("revision_id")).MustUInt32(),
+	}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	revision, err := svc.GetArticleRevision(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.GetArticleRevision err: %v", err)
+		response.ToErrorResponse(revisionError(err, errcode.ErrorGetArticleRevisionFail))
+		return
+	}
+
+	response.ToResponse(revision)
+	return
+}
+
+// Diff @Summary 比较文章的两个修订版本
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param from query int true "旧的修订版本ID"
+// @Param to query int true "新的修订版本ID"
+// @Success 200 {object} service.ArticleRevisionDiff "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "修订版本不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/articles/{id}/revisions/diff [get]
+func (r ArticleRevision) Diff(c *gin.Context) {
+	param := service.ArticleRevisionDiffRequest{ArticleID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	result, err := svc.DiffArticleRevision(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.DiffArticleRevision err: %v", err)
+		response.ToErrorResponse(revisionError(err, errcode.ErrorDiffArticleRevisionFail))
+		return
+	}
+
+	response.ToResponse(result)
+	return
+}
+
+// Restore @Summary 将文章恢复为指定的修订版本
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param revision_id path int true "修订版本ID"
+// @Param If-Match header string false "获取文章时返回的 ETag，文章已被修改时返回 412"
+// @Success 200 {object} model.ArticleRevision "恢复后生成的新修订版本"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "文章或修订版本不存在"
+// @Failure 412 {object} errcode.Error "文章已被修改"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/articles/{id}/revisions/{revision_id}/restore [post]
+func (r ArticleRevision) Restore(c *gin.Context) {
+	param := service.RestoreArticleRevisionRequest{
+		ArticleID:  convert.StrTo(c.Param("id")).MustUInt32(),
+		RevisionID: convert.StrTo(c.Param("revision_id")).MustUInt32(),
+	}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.ModifiedBy = app.GetPrincipal(c).Name
+	param.IfMatch = c.GetHeader("If-Match")
+	svc := service.New(c.Request.Context())
+	revision, err := svc.RestoreArticleRevision(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.RestoreArticleRevision err: %v", err)
+		response.ToErrorResponse(revisionError(err, errcode.ErrorRestoreArticleRevisionFail))
+		return
+	}
+
+	response.ToResponse(revision)
+	return
+}
+
+// 文章或修订版本不存在时返回 404，If-Match 校验失败时返回 412，其余错误返回对应的业务错误码
+func revisionError(err error, fail *errcode.Error) *errcode.Error {
+	switch err {
+	case service.ErrArticleNotFound, service.ErrArticleRevisionNotFound:
+		return errcode.NotFound.WithDetails(err.Error())
+	case service.ErrPreconditionFailed:
+		return errcode.PreconditionFailed
+	}
+	return fail
+}
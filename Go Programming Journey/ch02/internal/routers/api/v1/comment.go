@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"demo/ch02/global"
+	"demo/ch02/internal/service"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/convert"
+	"demo/ch02/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type Comment struct{}
+
+func NewComment() Comment {
+	return Comment{}
+}
+
+// List @Summary 获取文章的评论
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} model.CommentSwagger "成功，按时间倒序返回顶层评论，回复按时间顺序放在 replies 中"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "文章不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/articles/{id}/comments [get]
+func (cm Comment) List(c *gin.Context) {
+	param := service.CommentListRequest{ArticleID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
+	comments, totalRows, err := svc.GetCommentList(&param, &pager)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.GetCommentList err: %v", err)
+		response.ToErrorResponse(commentError(err, errcode.ErrorGetCommentListFail))
+		return
+	}
+
+	response.ToResponseList(comments, totalRows)
+	return
+}
+
+// Create @Summary 发表评论
+// @Produce json
+// @Param id path int true "文章ID"
+// @Param parent_id body int false "回复的评论ID，未传入时为顶层评论"
+// @Param content body string true "评论内容" maxlength(1000)
+// @Success 200 {object} service.Comment "成功，命中敏感词的评论 state 为 0，需审核通过后展示"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "文章或回复的评论不存在"
+// @Failure 429 {object} errcode.Error "发表评论过于频繁"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/articles/{id}/comments [post]
+func (cm Comment) Create(c *gin.Context) {
+	param := service.CreateCommentRequest{ArticleID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.CreatedBy = app.GetPrincipal(c).Name
+	svc := service.New(c.Request.Context())
+	comment, err := svc.CreateComment(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.CreateComment err: %v", err)
+		response.ToErrorResponse(commentError(err, errcode.ErrorCreateCommentFail))
+		return
+	}
+
+	response.ToResponse(comment)
+	return
+}
+
+// Delete @Summary 删除评论
+// @Produce json
+// @Param id path int true "评论ID"
+// @Success 200 {string} string "成功，删除顶层评论时该楼层下的回复一并删除"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "评论不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/comments/{id} [delete]
+func (cm Comment) Delete(c *gin.Context) {
+	param := service.DeleteCommentRequest{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	err := svc.DeleteComment(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.DeleteComment err: %v", err)
+		response.ToErrorResponse(commentError(err, errcode.ErrorDeleteCommentFail))
+		return
+	}
+
+	response.ToResponse(gin.H{})
+	return
+}
+
+// ModerationList @Summary 获取评论审核队列
+// @Produce json
+// @Param article_id query int false "文章ID，未传入时返回全部文章的评论"
+// @Param state query int false "审核状态 0 为待审核、1 为已通过、2 为已拒绝" Enums(0, 1, 2) default(0)
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} model.CommentSwagger "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/comments/moderation [get]
+func (cm Comment) ModerationList(c *gin.Context) {
+	param := service.CommentModerationListRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
+	comments, totalRows, err := svc.GetCommentModerationList(&param, &pager)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.GetCommentModerationList err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGetCommentModerationListFail)
+		return
+	}
+
+	response.ToResponseList(comments, totalRows)
+	return
+}
+
+// Moderate @Summary 审核评论
+// @Produce json
+// @Param id path int true "评论ID"
+// @Param state body int true "审核结果 1 为通过、2 为拒绝" Enums(1, 2)
+// @Success 200 {string} string "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "评论不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/comments/{id}/state [patch]
+func (cm Comment) Moderate(c *gin.Context) {
+	param := service.ModerateCommentRequest{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.ModifiedBy = app.GetPrincipal(c).Name
+	svc := service.New(c.Request.Context())
+	err := svc.ModerateComment(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.ModerateComment err: %v", err)
+		response.ToErrorResponse(commentError(err, errcode.ErrorModerateCommentFail))
+		return
+	}
+
+	response.ToResponse(gin.H{})
+	return
+}
+
+// 文章或评论不存在时返回 404，其余错误返回对应的业务错误码
+func commentError(err error, fail *errcode.Error) *errcode.Error {
+	if err == service.ErrArticleNotFound || err == service.ErrCommentNotFound {
+		return errcode.NotFound.WithDetails(err.Error())
+	}
+	return fail
+}
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"demo/ch02/global"
+	"demo/ch02/internal/model"
 	"demo/ch02/internal/service"
 	"demo/ch02/pkg/app"
 	"demo/ch02/pkg/convert"
@@ -15,13 +16,53 @@ func NewTag() Tag {
 	return Tag{}
 }
 
+// Get @Summary 获取单个标签
+// @Produce  json
+// @Param id path int true "标签 ID"
+// @Param state query int false "状态" Enums(0, 1) default(1)
+// @Param If-None-Match header string false "上次返回的 ETag，未变化时返回 304"
+// @Param If-Modified-Since header string false "上次返回的 Last-Modified，未修改时返回 304"
+// @Success 200 {object} model.Tag "成功"
+// @Success 304 {string} string "未修改"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "标签不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/tags/{id} [get]
 func (t Tag) Get(c *gin.Context) {
+	param := service.TagRequest{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	tag, err := svc.GetTag(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.GetTag err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGetTagFail)
+		return
+	}
+	if tag == nil {
+		response.ToErrorResponse(errcode.NotFound.WithDetails(service.ErrTagNotFound.Error()))
+		return
+	}
+
+	// model.Tag 实现了 app.LastModifier，同时返回 ETag 与 Last-Modified
+	response.ToResponse(tag)
+	return
 }
 
 // List @Summary 获取多个标签
 // @Produce  json
 // @Param name query string false "标签名称" maxlength(100)
 // @Param state query int false "状态" Enums(0, 1) default(1)
+// @Param created_by query string false "创建者" maxlength(100)
+// @Param sort query string false "排序字段，多个字段以逗号分隔，- 前缀表示倒序，e.g. -created_on,name"
+// @Param filter[field][op] query string false "筛选条件，op 可选 eq、ne、gt、gte、lt、lte、like、in，e.g. filter[created_on][gte]=1650000000"
+// @Param fields query string false "返回字段，多个字段以逗号分隔，e.g. id,name"
 // @Param page query int false "页码"
 // @Param page_size query int false "每页数量"
 // @Success 200 {object} model.TagSwagger "成功"
@@ -40,26 +81,39 @@ func (t Tag) List(c *gin.Context) {
 		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
 		return
 	}
+	// 解析通用的筛选、排序与字段参数
+	spec, errs := app.BindQuerySpec(c, model.TagQueryRule)
+	if len(errs) > 0 {
+		global.Logger.Errorf(c, "app.BindQuerySpec errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
 
 	svc := service.New(c.Request.Context())
 	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
 	// 获取标签总数
-	totalRows, err := svc.CountTag(&service.CountTagRequest{Name: param.Name, State: param.State})
+	totalRows, err := svc.CountTag(&service.CountTagRequest{Name: param.Name, CreatedBy: param.CreatedBy, State: param.State}, spec)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.CountTag err: %v", err)
 		response.ToErrorResponse(errcode.ErrorCountTagFail)
 		return
 	}
 	// 获取标签列表
-	tags, err := svc.GetTagList(&param, &pager)
+	tags, err := svc.GetTagList(&param, spec, &pager)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.GetTagList err: %v", err)
 		response.ToErrorResponse(errcode.ErrorGetTagListFail)
 		return
 	}
+	list, err := spec.Project(tags)
+	if err != nil {
+		global.Logger.Errorf(c, "spec.Project err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGetTagListFail)
+		return
+	}
 
 	// 序列化结果集
-	response.ToResponseList(tags, totalRows)
+	response.ToResponseList(list, totalRows)
 	return
 }
 
@@ -67,7 +121,6 @@ func (t Tag) List(c *gin.Context) {
 // @Produce  json
 // @Param name body string true "标签名称" minlength(3) maxlength(100)
 // @Param state body int false "状态" Enums(0, 1) default(1)
-// @Param created_by body string true "创建者" minlength(3) maxlength(100)
 // @Success 200 {object} model.Tag "成功"
 // @Failure 400 {object} errcode.Error "请求错误"
 // @Failure 500 {object} errcode.Error "内部错误"
@@ -83,6 +136,7 @@ func (t Tag) Create(c *gin.Context) {
 		return
 	}
 
+	param.CreatedBy = app.GetPrincipal(c).Name
 	svc := service.New(c.Request.Context())
 	err := svc.CreateTag(&param)
 	if err != nil {
@@ -100,9 +154,11 @@ func (t Tag) Create(c *gin.Context) {
 // @Param id path int true "标签 ID"
 // @Param name body string false "标签名称" minlength(3) maxlength(100)
 // @Param state body int false "状态" Enums(0, 1) default(1)
-// @Param modified_by body string true "修改者" minlength(3) maxlength(100)
+// @Param If-Match header string false "获取标签时返回的 ETag，标签已被修改时返回 412"
 // @Success 200 {array} model.Tag "成功"
 // @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 404 {object} errcode.Error "标签不存在"
+// @Failure 412 {object} errcode.Error "标签已被修改"
 // @Failure 500 {object} errcode.Error "内部错误"
 // @Router /api/v1/tags/{id} [put]
 func (t Tag) Update(c *gin.Context) {
@@ -118,11 +174,20 @@ func (t Tag) Update(c *gin.Context) {
 		return
 	}
 
+	param.ModifiedBy = app.GetPrincipal(c).Name
+	param.IfMatch = c.GetHeader("If-Match")
 	svc := service.New(c.Request.Context())
 	err := svc.UpdateTag(&param)
 	if err != nil {
 		global.Logger.Errorf(c, "svc.UpdateTag err: %v", err)
-		response.ToErrorResponse(errcode.ErrorUpdateTagFail)
+		switch err {
+		case service.ErrTagNotFound:
+			response.ToErrorResponse(errcode.NotFound.WithDetails(err.Error()))
+		case service.ErrPreconditionFailed:
+			response.ToErrorResponse(errcode.PreconditionFailed)
+		default:
+			response.ToErrorResponse(errcode.ErrorUpdateTagFail)
+		}
 		return
 	}
 
@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"demo/ch02/global"
+	"demo/ch02/internal/service"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/convert"
+	"demo/ch02/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type User struct{}
+
+func NewUser() User {
+	return User{}
+}
+
+// Me @Summary 获取当前调用方的身份
+// @Produce json
+// @Success 200 {object} app.Principal "成功"
+// @Router /api/v1/users/me [get]
+func (u User) Me(c *gin.Context) {
+	response := app.NewResponse(c)
+	response.ToResponse(app.GetPrincipal(c))
+}
+
+// List @Summary 获取用户列表
+// @Produce json
+// @Param role query string false "角色" Enums(admin, editor, viewer)
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} model.UserSwagger "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/users [get]
+func (u User) List(c *gin.Context) {
+	param := service.UserListRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	svc := service.New(c.Request.Context())
+	pager := app.Pager{Page: app.GetPage(c), PageSize: app.GetPageSize(c)}
+	users, totalRows, err := svc.GetUserList(&param, &pager)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.GetUserList err: %v", err)
+		response.ToErrorResponse(errcode.ErrorGetUserListFail)
+		return
+	}
+
+	response.ToResponseList(users, totalRows)
+	return
+}
+
+// Create @Summary 创建用户
+// @Produce json
+// @Param username body string true "用户名" minlength(2) maxlength(100)
+// @Param password body string true "密码" minlength(8) maxlength(72)
+// @Param role body string false "角色" Enums(admin, editor, viewer) default(viewer)
+// @Success 200 {object} model.User "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/users [post]
+func (u User) Create(c *gin.Context) {
+	param := service.CreateUserRequest{}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.CreatedBy = app.GetPrincipal(c).Name
+	svc := service.New(c.Request.Context())
+	user, err := svc.CreateUser(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.CreateUser err: %v", err)
+		response.ToErrorResponse(userError(err, errcode.ErrorCreateUserFail))
+		return
+	}
+
+	response.ToResponse(user)
+	return
+}
+
+// Update @Summary 更新用户
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param password body string false "密码，未传入时保持不变" minlength(8) maxlength(72)
+// @Param role body string false "角色" Enums(admin, editor, viewer)
+// @Param state body int false "状态 0 为禁用、1 为启用" Enums(0, 1)
+// @Success 200 {string} string "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 404 {object} errcode.Error "用户不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/users/{id} [put]
+func (u User) Update(c *gin.Context) {
+	param := service.UpdateUserRequest{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	principal := app.GetPrincipal(c)
+	param.ModifiedBy = principal.Name
+	param.OperatorID = principal.UserID
+	svc := service.New(c.Request.Context())
+	err := svc.UpdateUser(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.UpdateUser err: %v", err)
+		response.ToErrorResponse(userError(err, errcode.ErrorUpdateUserFail))
+		return
+	}
+
+	response.ToResponse(gin.H{})
+	return
+}
+
+// Delete @Summary 删除用户
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {string} string "成功"
+// @Failure 400 {object} errcode.Error "请求错误"
+// @Failure 403 {object} errcode.Error "没有访问权限"
+// @Failure 404 {object} errcode.Error "用户不存在"
+// @Failure 500 {object} errcode.Error "内部错误"
+// @Router /api/v1/users/{id} [delete]
+func (u User) Delete(c *gin.Context) {
+	param := service.DeleteUserRequest{ID: convert.StrTo(c.Param("id")).MustUInt32()}
+	response := app.NewResponse(c)
+	valid, errs := app.BindAndValid(c, &param)
+	if !valid {
+		global.Logger.Errorf(c, "app.BindAndValid errs: %v", errs)
+		response.ToErrorResponse(errcode.InvalidParams.WithDetails(errs.Errors()...))
+		return
+	}
+
+	param.OperatorID = app.GetPrincipal(c).UserID
+	svc := service.New(c.Request.Context())
+	err := svc.DeleteUser(&param)
+	if err != nil {
+		global.Logger.Errorf(c, "svc.DeleteUser err: %v", err)
+		response.ToErrorResponse(userError(err, errcode.ErrorDeleteUserFail))
+		return
+	}
+
+	response.ToResponse(gin.H{})
+	return
+}
+
+// 用户不存在时返回 404，用户名重复或修改自己时返回入参错误
+func userError(err error, fail *errcode.Error) *errcode.Error {
+	switch err {
+	case service.ErrUserNotFound:
+		return errcode.NotFound.WithDetails(err.Error())
+	case service.ErrUserExists, service.ErrUserSelf:
+		return errcode.InvalidParams.WithDetails(err.Error())
+	}
+	return fail
+}
@@ -3,13 +3,15 @@ package routers
 import (
 	"demo/ch02/global"
 	"demo/ch02/internal/middleware"
+	"demo/ch02/internal/model"
 	"demo/ch02/internal/routers/api"
 	v1 "demo/ch02/internal/routers/api/v1"
 	"demo/ch02/pkg/limiter"
+	"errors"
+	"expvar"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"net/http"
-	"time"
 
 	// 注意此处导包，不需要设置别名
 	"github.com/swaggo/gin-swagger/swaggerFiles"
@@ -17,20 +19,58 @@ import (
 	_ "demo/ch02/docs"
 )
 
-// 对认证接口进行限流
-var methodLimiters = limiter.NewMethodLimiter().AddBucket(limiter.LimiterBucketRule{
-	Key:          "/auth",          // 对接受的 auth 请求进行限流
-	FillInterval: 10 * time.Second, // 对 10s 内接受的请求进行限流
-	Capacity:     10,               // 10s 内最多接受 10 个
-	Quantum:      10,               // 每过 10s 将令牌桶的数量减少 10 #{Quantum}
-})
+// 按配置中的规则对各路由进行限流，配置变更时通过 SetupRateLimit 替换规则
+var rateLimiter = limiter.NewRouteLimiter()
+
+// 键前缀不变时复用，避免配置重新读取后清理已有的令牌桶
+var rateLimitStore *limiter.RedisStore
+var rateLimitStorePrefix string
+
+// 根据 RateLimitSetting 设置限流规则与令牌桶的存储，配置不合法时返回错误并保留原有设置
+func SetupRateLimit() error {
+	s := global.RateLimitSetting()
+	var store limiter.Store
+	switch s.Store {
+	case "", "memory":
+	case "redis":
+		if global.Redis == nil {
+			return errors.New("rate limit store redis: Redis.Addr is not configured")
+		}
+		if rateLimitStore == nil || rateLimitStorePrefix != s.KeyPrefix {
+			rateLimitStore = limiter.NewRedisStore(global.Redis, s.KeyPrefix)
+			rateLimitStorePrefix = s.KeyPrefix
+		}
+		store = rateLimitStore
+	default:
+		return fmt.Errorf("rate limit store %q: unknown store", s.Store)
+	}
+
+	rules := make([]limiter.RouteRule, 0, len(s.Rules))
+	for _, rule := range s.Rules {
+		rules = append(rules, limiter.RouteRule{
+			Method:       rule.Method,
+			Path:         rule.Path,
+			KeyBy:        rule.KeyBy,
+			FillInterval: rule.FillInterval,
+			Capacity:     rule.Capacity,
+			Quantum:      rule.Quantum,
+			Algorithm:    rule.Algorithm,
+			Cost:         rule.Cost,
+		})
+	}
+	if err := rateLimiter.SetRules(rules); err != nil {
+		return err
+	}
+	rateLimiter.SetStore(store)
+	return nil
+}
 
 func NewRouter() *gin.Engine {
 	// 或者 r := gin.Default() 也可
 	r := gin.New()
 	// 根据不同的部署环境进行了应用中间件的设置
 	// 使用了自定义的 Logger 和 Recovery 后就不需要使用 gin 原生提供的组件了
-	if global.ServerSetting.RunMode == "debug" {
+	if global.ServerSetting().RunMode == "debug" {
 		// 在本地开发环境中，可能没有全应用生态圈，故作特殊处理
 		r.Use(gin.Logger())
 		// 在注册顺序上需要注意，类似 Recovery 这类的中间件应当尽可能早的注册
@@ -43,9 +83,9 @@ func NewRouter() *gin.Engine {
 	// 新增链路追踪中间件注册
 	r.Use(middleware.Tracing())
 	// 新增限流控制中间件的注册
-	r.Use(middleware.RateLimiter(methodLimiters))
+	r.Use(middleware.RateLimiter(rateLimiter))
 	// 新增统一超时控制中间件注册
-	r.Use(middleware.ContextTimeout(global.AppSetting.DefaultContextTimeout))
+	r.Use(middleware.ContextTimeout(global.AppSetting().DefaultContextTimeout))
 	// 新增中间件 Translations 的注册
 	r.Use(middleware.Translations())
 	// 新增应用信息中间件注册
@@ -56,38 +96,72 @@ func NewRouter() *gin.Engine {
 	//r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
 	// 注册一个针对 swagger 的路由，默认指向当前应用所启动的域名下的 swagger/doc.json 路径
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// expvar 运行指标，包括各缓存的命中与未命中次数，其中的命令行参数与内存统计不应公开，仅 admin 或授权的 app_key 可访问
+	r.GET("/debug/vars", middleware.JWT(), middleware.Permission(model.PERM_METRICS_READ), gin.WrapH(expvar.Handler()))
 	article := v1.NewArticle()
 	tag := v1.NewTag()
+	revision := v1.NewArticleRevision()
+	comment := v1.NewComment()
+	user := v1.NewUser()
+	appKey := v1.NewAppKey()
 	// 添加上传文件的对应路由
 	upload := api.NewUpload()
-	r.POST("/upload/file", upload.UploadFile)
-	// 提供静态资源的访问
-	// Static 只能展示文件，StaticFS 可以连目录也展示
-	// http.Dir() 实现了 FileSystem接口，利用本地目录实现一个文件系统
-	r.StaticFS("/static", http.Dir(global.AppSetting.UploadSavePath))
+	r.POST("/upload/file", middleware.JWT(), middleware.Permission(model.PERM_UPLOAD), upload.UploadFile)
+	// 提供上传文件的访问，文件由 global.Storage 读取，不展示目录
+	r.GET("/static/*key", upload.Download)
+	r.HEAD("/static/*key", upload.Download)
 	// 新增 auth 相关路由
 	r.POST("/auth", api.GetAuth)
+	r.POST("/auth/login", api.Login)
+	r.POST("/auth/refresh", api.RefreshToken)
+	r.POST("/auth/logout", middleware.JWT(), api.Logout)
+	r.GET("/.well-known/jwks.json", api.GetJWKS)
 
 	// 使用路由组设置访问路由的统一前缀 e.g. /api/v1
 	// 此处定义了一个路由组 /api/v1
 	apiv1 := r.Group("/api/v1")
-	// apiv1 路由分组引入 JWT 中间件
+	// apiv1 路由分组引入 JWT 中间件，JWT 中间件将调用方写入上下文，各路由通过 Permission 声明所需的权限
 	apiv1.Use(middleware.JWT())
 	// 上面花括号是代表中间的语句属于一个空间内，不受外界干扰，可去掉
 	{
 		// 将实现的 Handler 方法注册到对应的路由规则上
-		apiv1.POST("/tags", tag.Create)
-		apiv1.DELETE("/tags/:id", tag.Delete)
-		apiv1.PUT("/tags/:id", tag.Update)
-		apiv1.PATCH("/tags/:id/state", tag.Update)
-		apiv1.GET("/tags", tag.List)
+		apiv1.POST("/tags", middleware.Permission(model.PERM_TAG_WRITE), tag.Create)
+		apiv1.DELETE("/tags/:id", middleware.Permission(model.PERM_TAG_WRITE), tag.Delete)
+		apiv1.PUT("/tags/:id", middleware.Permission(model.PERM_TAG_WRITE), tag.Update)
+		apiv1.PATCH("/tags/:id/state", middleware.Permission(model.PERM_TAG_WRITE), tag.Update)
+		apiv1.GET("/tags", middleware.Permission(model.PERM_TAG_READ), tag.List)
+		apiv1.GET("/tags/:id", middleware.Permission(model.PERM_TAG_READ), tag.Get)
+
+		apiv1.POST("/articles", middleware.Permission(model.PERM_ARTICLE_WRITE), article.Create)
+		apiv1.DELETE("/articles/:id", middleware.Permission(model.PERM_ARTICLE_WRITE), article.Delete)
+		apiv1.PUT("/articles/:id", middleware.Permission(model.PERM_ARTICLE_WRITE), article.Update)
+		apiv1.PATCH("/articles/:id/state", middleware.Permission(model.PERM_ARTICLE_WRITE), article.Update)
+		apiv1.GET("/articles/search", middleware.Permission(model.PERM_ARTICLE_READ), article.Search)
+		apiv1.GET("/articles/:id", middleware.Permission(model.PERM_ARTICLE_READ), article.Get)
+		apiv1.GET("/articles", middleware.Permission(model.PERM_ARTICLE_READ), article.List)
+
+		apiv1.GET("/articles/:id/revisions", middleware.Permission(model.PERM_ARTICLE_WRITE), revision.List)
+		apiv1.GET("/articles/:id/revisions/diff", middleware.Permission(model.PERM_ARTICLE_WRITE), revision.Diff)
+		apiv1.GET("/articles/:id/revisions/:revision_id", middleware.Permission(model.PERM_ARTICLE_WRITE), revision.Get)
+		apiv1.POST("/articles/:id/revisions/:revision_id/restore", middleware.Permission(model.PERM_ARTICLE_WRITE), revision.Restore)
+
+		apiv1.GET("/articles/:id/comments", middleware.Permission(model.PERM_COMMENT_READ), comment.List)
+		apiv1.POST("/articles/:id/comments", middleware.Permission(model.PERM_COMMENT_WRITE), comment.Create)
+		apiv1.DELETE("/comments/:id", middleware.Permission(model.PERM_COMMENT_MODERATE), comment.Delete)
+		apiv1.GET("/comments/moderation", middleware.Permission(model.PERM_COMMENT_MODERATE), comment.ModerationList)
+		apiv1.PATCH("/comments/:id/state", middleware.Permission(model.PERM_COMMENT_MODERATE), comment.Moderate)
+
+		apiv1.GET("/users/me", user.Me)
+		apiv1.GET("/users", middleware.Permission(model.PERM_USER_MANAGE), user.List)
+		apiv1.POST("/users", middleware.Permission(model.PERM_USER_MANAGE), user.Create)
+		apiv1.PUT("/users/:id", middleware.Permission(model.PERM_USER_MANAGE), user.Update)
+		apiv1.DELETE("/users/:id", middleware.Permission(model.PERM_USER_MANAGE), user.Delete)
 
-		apiv1.POST("/articles", article.Create)
-		apiv1.DELETE("/articles/:id", article.Delete)
-		apiv1.PUT("/articles/:id", article.Update)
-		apiv1.PATCH("/articles/:id/state", article.Update)
-		apiv1.GET("/articles/:id", article.Get)
-		apiv1.GET("/articles", article.List)
+		apiv1.GET("/app_keys", middleware.Permission(model.PERM_APP_KEY_MANAGE), appKey.List)
+		apiv1.POST("/app_keys", middleware.Permission(model.PERM_APP_KEY_MANAGE), appKey.Create)
+		apiv1.PUT("/app_keys/:id", middleware.Permission(model.PERM_APP_KEY_MANAGE), appKey.Update)
+		apiv1.PATCH("/app_keys/:id/state", middleware.Permission(model.PERM_APP_KEY_MANAGE), appKey.Update)
+		apiv1.POST("/app_keys/:id/rotate", middleware.Permission(model.PERM_APP_KEY_MANAGE), appKey.Rotate)
 	}
 	return r
 }
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"crypto/rand"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+var (
+	ErrAppKeyNotFound = errors.New("app_key 不存在")
+	ErrAppKeyExists   = errors.New("app_key 已存在")
+	ErrAppKeyScope    = errors.New("权限范围不合法")
+	ErrAppKeySelf     = errors.New("不能修改当前使用的 app_key 的权限范围或状态")
+	ErrAppKeyDenied   = errors.New("不能授予或管理超出自身权限的 app_key")
+)
+
+// Scopes 可以重复传入，也可以用逗号分隔，* 为全部权限
+type CreateAppKeyRequest struct {
+	AppKey    string         `form:"app_key" binding:"omitempty,alphanum,min=4,max=20"` // 未传入时随机生成
+	Scopes    []string       `form:"scopes" binding:"required,min=1"`
+	CreatedBy string         `form:"-"` // 由认证信息填充
+	Operator  *app.Principal `form:"-"` // 当前调用方，只能授予调用方拥有的权限
+}
+
+type UpdateAppKeyRequest struct {
+	ID         uint32         `form:"id" binding:"required,gte=1"`
+	Scopes     []string       `form:"scopes"` // 未传入时保持不变
+	State      *uint8         `form:"state" binding:"omitempty,oneof=0 1"`
+	ModifiedBy string         `form:"-"` // 由认证信息填充
+	Operator   *app.Principal `form:"-"` // 当前调用方
+}
+
+type RotateAppKeyRequest struct {
+	ID         uint32         `form:"id" binding:"required,gte=1"`
+	ModifiedBy string         `form:"-"` // 由认证信息填充
+	Operator   *app.Principal `form:"-"` // 当前调用方，不能轮换权限超出自身的 app_key
+}
+
+// 接口返回的 app_key，不包含 secret
+type AppKey struct {
+	ID         uint32   `json:"id"`
+	AppKey     string   `json:"app_key"`
+	Scopes     []string `json:"scopes"`
+	State      uint8    `json:"state"`
+	CreatedBy  string   `json:"created_by"`
+	CreatedOn  uint32   `json:"created_on"`
+	ModifiedBy string   `json:"modified_by"`
+	ModifiedOn uint32   `json:"modified_on"`
+}
+
+// 创建与轮换时返回的凭证，secret 仅在此时返回一次，服务端只保存其哈希
+type AppKeyCredential struct {
+	*AppKey
+	AppSecret string `json:"app_secret"`
+}
+
+func (svc *Service) GetAppKeyList(pager *app.Pager) ([]*AppKey, int, error) {
+	totalRows, err := svc.dao.CountAuth()
+	if err != nil {
+		return nil, 0, err
+	}
+	auths, err := svc.dao.GetAuthList(pager.Page, pager.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	appKeys := make([]*AppKey, 0, len(auths))
+	for _, auth := range auths {
+		appKeys = append(appKeys, newAppKey(auth))
+	}
+	return appKeys, totalRows, nil
+}
+
+func (svc *Service) CreateAppKey(param *CreateAppKeyRequest) (*AppKeyCredential, error) {
+	scopes, err := normalizeScopes(param.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if !canGrantScopes(param.Operator, scopes) {
+		return nil, ErrAppKeyDenied
+	}
+	appKey := param.AppKey
+	if appKey == "" {
+		if appKey, err = randomToken(8, hex.EncodeToString); err != nil {
+			return nil, err
+		}
+	}
+	existed, err := svc.dao.GetAuth(appKey)
+	if err != nil {
+		return nil, err
+	}
+	if existed.Model != nil && existed.ID > 0 {
+		return nil, ErrAppKeyExists
+	}
+
+	secret, hash, err := newAppSecret()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := svc.dao.CreateAuth(appKey, hash, strings.Join(scopes, ","), param.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &AppKeyCredential{AppKey: newAppKey(auth), AppSecret: secret}, nil
+}
+
+// 修改权限范围或启用、禁用 app_key，已签发的 token 在刷新时按新的设置生效
+func (svc *Service) UpdateAppKey(param *UpdateAppKeyRequest) error {
+	auth, err := svc.getAppKey(param.ID)
+	if err != nil {
+		return err
+	}
+	// 避免调用方误操作导致失去管理权限
+	isSelf := param.Operator.Role == model.ROLE_APP && auth.AppKey == param.Operator.Name
+	if isSelf && (len(param.Scopes) > 0 || param.State != nil) {
+		return ErrAppKeySelf
+	}
+	if !canGrantScopes(param.Operator, splitScopes(auth.Scopes)) {
+		return ErrAppKeyDenied
+	}
+
+	values := map[string]interface{}{
+		"modified_by": param.ModifiedBy,
+	}
+	if len(param.Scopes) > 0 {
+		scopes, err := normalizeScopes(param.Scopes)
+		if err != nil {
+			return err
+		}
+		if !canGrantScopes(param.Operator, scopes) {
+			return ErrAppKeyDenied
+		}
+		values["scopes"] = strings.Join(scopes, ",")
+	}
+	if param.State != nil {
+		values["state"] = *param.State
+	}
+	return svc.dao.UpdateAuth(param.ID, values)
+}
+
+// 生成新的 secret，旧的 secret 立即失效
+func (svc *Service) RotateAppKey(param *RotateAppKeyRequest) (*AppKeyCredential, error) {
+	auth, err := svc.getAppKey(param.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 轮换后调用方会得到新的 secret，相当于获得该 app_key 的全部权限
+	if !canGrantScopes(param.Operator, splitScopes(auth.Scopes)) {
+		return nil, ErrAppKeyDenied
+	}
+	secret, hash, err := newAppSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = svc.dao.UpdateAuth(param.ID, map[string]interface{}{
+		"app_secret":  hash,
+		"modified_by": param.ModifiedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	auth.ModifiedBy = param.ModifiedBy
+	return &AppKeyCredential{AppKey: newAppKey(auth), AppSecret: secret}, nil
+}
+
+func (svc *Service) getAppKey(id uint32) (*model.Auth, error) {
+	auth, err := svc.dao.GetAuthByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if auth.Model == nil || auth.ID == 0 {
+		return nil, ErrAppKeyNotFound
+	}
+	return &auth, nil
+}
+
+// 拆分逗号分隔的权限并去重，包含未知权限时返回错误
+func normalizeScopes(values []string) ([]string, error) {
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, scope := range strings.Split(value, ",") {
+			scope = strings.TrimSpace(scope)
+			if scope == "" || seen[scope] {
+				continue
+			}
+			if !model.IsScope(scope) {
+				return nil, ErrAppKeyScope
+			}
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, ErrAppKeyScope
+	}
+	return scopes, nil
+}
+
+// 调用方只能授予自己拥有的权限，* 只能由拥有 * 的 app_key 或管理员授予
+func canGrantScopes(operator *app.Principal, scopes []string) bool {
+	for _, scope := range scopes {
+		if scope == model.SCOPE_ALL {
+			if operator.Role == model.ROLE_ADMIN {
+				continue
+			}
+			if operator.Role == model.ROLE_APP && model.HasScope(operator.Scopes, model.SCOPE_ALL) {
+				continue
+			}
+			return false
+		}
+		if !model.PrincipalHasPermission(operator, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
+
+// 生成随机的 secret 及其 bcrypt 哈希
+func newAppSecret() (string, string, error) {
+	secret, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, string(hash), nil
+}
+
+func randomToken(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func newAppKey(auth *model.Auth) *AppKey {
+	return &AppKey{
+		ID:         auth.ID,
+		AppKey:     auth.AppKey,
+		Scopes:     splitScopes(auth.Scopes),
+		State:      auth.State,
+		CreatedBy:  auth.CreatedBy,
+		CreatedOn:  auth.CreatedOn,
+		ModifiedBy: auth.ModifiedBy,
+		ModifiedOn: auth.ModifiedOn,
+	}
+}
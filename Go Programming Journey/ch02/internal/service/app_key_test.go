@@ -0,0 +1,121 @@
+package service
+
+import (
+	"testing"
+
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+)
+
+var (
+	adminUser  = &app.Principal{UserID: 1, Name: "root", Role: model.ROLE_ADMIN}
+	editorUser = &app.Principal{UserID: 2, Name: "editor", Role: model.ROLE_EDITOR}
+	allApp     = &app.Principal{Name: "admin", Role: model.ROLE_APP, Scopes: []string{model.SCOPE_ALL}}
+	managerApp = &app.Principal{Name: "manager", Role: model.ROLE_APP, Scopes: []string{model.PERM_APP_KEY_MANAGE, model.PERM_TAG_READ}}
+)
+
+// 迁移中的 admin 拥有 *，另外创建一个只读的 reader 与只能管理 app_key 的 manager
+func newAppKeyTestService(t *testing.T) Service {
+	svc := newTestService(t)
+	if _, err := svc.dao.CreateAuth("reader", "", model.PERM_TAG_READ, "test"); err != nil {
+		t.Fatalf("CreateAuth err: %v", err)
+	}
+	if _, err := svc.dao.CreateAuth("manager", "", model.PERM_APP_KEY_MANAGE+","+model.PERM_TAG_READ, "test"); err != nil {
+		t.Fatalf("CreateAuth err: %v", err)
+	}
+	return svc
+}
+
+func TestCreateAppKeyScopes(t *testing.T) {
+	svc := newAppKeyTestService(t)
+	tests := []struct {
+		name     string
+		operator *app.Principal
+		scopes   []string
+		wantErr  error
+	}{
+		{name: "app grants all", operator: managerApp, scopes: []string{"*"}, wantErr: ErrAppKeyDenied},
+		{name: "app grants missing scope", operator: managerApp, scopes: []string{"tag:read,tag:write"}, wantErr: ErrAppKeyDenied},
+		{name: "app grants own scopes", operator: managerApp, scopes: []string{"tag:read", "app_key:manage"}},
+		{name: "unknown scope", operator: managerApp, scopes: []string{"tag:delete"}, wantErr: ErrAppKeyScope},
+		{name: "app with all grants all", operator: allApp, scopes: []string{"*"}},
+		{name: "editor grants role permission", operator: editorUser, scopes: []string{"tag:write"}},
+		{name: "editor grants missing permission", operator: editorUser, scopes: []string{"user:manage"}, wantErr: ErrAppKeyDenied},
+		{name: "editor grants all", operator: editorUser, scopes: []string{"*"}, wantErr: ErrAppKeyDenied},
+		{name: "admin grants all", operator: adminUser, scopes: []string{"*"}},
+	}
+	for _, tt := range tests {
+		credential, err := svc.CreateAppKey(&CreateAppKeyRequest{Scopes: tt.scopes, Operator: tt.operator})
+		if err != tt.wantErr {
+			t.Errorf("%s: CreateAppKey err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && credential.AppSecret == "" {
+			t.Errorf("%s: AppSecret is empty", tt.name)
+		}
+	}
+}
+
+func TestUpdateAppKeyScopes(t *testing.T) {
+	svc := newAppKeyTestService(t)
+	disabled := uint8(model.STATE_CLOSE)
+	tests := []struct {
+		name       string
+		id         uint32
+		operator   *app.Principal
+		scopes     []string
+		state      *uint8
+		wantErr    error
+		wantScopes string
+	}{
+		{name: "upgrade to all", id: 2, operator: managerApp, scopes: []string{"*"}, wantErr: ErrAppKeyDenied, wantScopes: "tag:read"},
+		{name: "upgrade to missing scope", id: 2, operator: managerApp, scopes: []string{"tag:write"}, wantErr: ErrAppKeyDenied, wantScopes: "tag:read"},
+		{name: "grant own scope", id: 2, operator: managerApp, scopes: []string{"tag:read,app_key:manage"}, wantScopes: "tag:read,app_key:manage"},
+		{name: "disable stronger key", id: 1, operator: managerApp, state: &disabled, wantErr: ErrAppKeyDenied, wantScopes: "*"},
+		{name: "self", id: 3, operator: managerApp, scopes: []string{"tag:read"}, wantErr: ErrAppKeySelf, wantScopes: "app_key:manage,tag:read"},
+		// 用户与 app_key 同名时不视为修改自己
+		{name: "user with same name", id: 3, operator: &app.Principal{UserID: 3, Name: "manager", Role: model.ROLE_ADMIN}, scopes: []string{"*"}, wantScopes: "*"},
+		{name: "app with all", id: 2, operator: allApp, scopes: []string{"*"}, wantScopes: "*"},
+	}
+	for _, tt := range tests {
+		err := svc.UpdateAppKey(&UpdateAppKeyRequest{ID: tt.id, Scopes: tt.scopes, State: tt.state, Operator: tt.operator})
+		if err != tt.wantErr {
+			t.Errorf("%s: UpdateAppKey err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		auth, _ := svc.getAppKey(tt.id)
+		if auth.Scopes != tt.wantScopes {
+			t.Errorf("%s: Scopes = %q, want %q", tt.name, auth.Scopes, tt.wantScopes)
+		}
+	}
+}
+
+func TestRotateAppKeyScopes(t *testing.T) {
+	svc := newAppKeyTestService(t)
+	tests := []struct {
+		name     string
+		id       uint32
+		operator *app.Principal
+		wantErr  error
+	}{
+		// 轮换后得到 secret 即拥有该 app_key 的权限
+		{name: "stronger key", id: 1, operator: managerApp, wantErr: ErrAppKeyDenied},
+		{name: "weaker key", id: 2, operator: managerApp},
+		{name: "admin", id: 1, operator: adminUser},
+		{name: "missing", id: 10, operator: adminUser, wantErr: ErrAppKeyNotFound},
+	}
+	for _, tt := range tests {
+		before, _ := svc.dao.GetAuthByID(tt.id)
+		credential, err := svc.RotateAppKey(&RotateAppKeyRequest{ID: tt.id, Operator: tt.operator})
+		if err != tt.wantErr {
+			t.Errorf("%s: RotateAppKey err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		after, _ := svc.dao.GetAuthByID(tt.id)
+		if changed := before.AppSecret != after.AppSecret; changed != (err == nil) {
+			t.Errorf("%s: secret changed = %v, want %v", tt.name, changed, err == nil)
+		}
+		if err == nil && !svc.checkAppSecret(&after, credential.AppSecret) {
+			t.Errorf("%s: new secret does not match", tt.name)
+		}
+	}
+}
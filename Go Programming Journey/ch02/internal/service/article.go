@@ -5,38 +5,42 @@ import (
 	"demo/ch02/internal/model"
 	"demo/ch02/pkg/app"
 	"fmt"
+	"time"
 )
 
 type ArticleRequest struct {
 	ID    uint32 `form:"id" binding:"required,gte=1"`
-	State uint8  `form:"state,default=1" binding:"oneof=0 1"`
+	State uint8  `form:"state,default=1" binding:"oneof=0 1 2 3 4"`
 }
 
 type ArticleListRequest struct {
-	TagID uint32 `form:"tag_id" binding:"gte=1"`
-	Title string `form:"title" binding:"max=100"`
-	State uint8  `form:"state,default=1" binding:"oneof=0 1"`
+	TagID     uint32 `form:"tag_id" binding:"omitempty,gte=1"` // 未传入时不按标签筛选
+	Title     string `form:"title" binding:"max=100"`
+	CreatedBy string `form:"created_by" binding:"max=100"`
+	State     uint8  `form:"state,default=1" binding:"oneof=0 1 2 3 4"`
 }
 
 type CreateArticleRequest struct {
-	TagID         uint32 `form:"tag_id" binding:"required,gte=1"`
-	Title         string `form:"title" binding:"required,min=2,max=100"`
-	Desc          string `form:"desc" binding:"required,min=2,max=255"`
-	Content       string `form:"content" binding:"required,min=2,max=4294967295"`
-	CoverImageUrl string `form:"cover_image_url" binding:"required,url"`
-	CreatedBy     string `form:"created_by" binding:"required,min=2,max=100"`
-	State         uint8  `form:"state,default=1" binding:"oneof=0 1"`
+	TagIDs        []uint32 `form:"tag_ids" binding:"required,min=1,dive,gte=1"`
+	Title         string   `form:"title" binding:"required,min=2,max=100"`
+	Desc          string   `form:"desc" binding:"required,min=2,max=255"`
+	Content       string   `form:"content" binding:"required,min=2,max=4294967295"`
+	CoverImageUrl string   `form:"cover_image_url" binding:"required,url"`
+	CreatedBy     string   `form:"-"`                                   // 由认证信息填充
+	State         uint8    `form:"state,default=2" binding:"oneof=2 3"` // 新文章只能保存为草稿或直接提交审核
 }
 
 type UpdateArticleRequest struct {
-	ID            uint32 `form:"id" binding:"required,gte=1"`
-	TagID         uint32 `form:"tag_id" binding:"required,gte=1"`
-	Title         string `form:"title" binding:"min=2,max=100"`
-	Desc          string `form:"desc" binding:"min=2,max=255"`
-	Content       string `form:"content" binding:"min=2,max=4294967295"`
-	CoverImageUrl string `form:"cover_image_url" binding:"url"`
-	ModifiedBy    string `form:"modified_by" binding:"required,min=2,max=100"`
-	State         uint8  `form:"state,default=1" binding:"oneof=0 1"`
+	ID            uint32   `form:"id" binding:"required,gte=1"`
+	TagIDs        []uint32 `form:"tag_ids" binding:"omitempty,dive,gte=1"` // 未传入时保持文章原有的标签不变
+	Title         string   `form:"title" binding:"omitempty,min=2,max=100"`
+	Desc          string   `form:"desc" binding:"omitempty,min=2,max=255"`
+	Content       string   `form:"content" binding:"omitempty,min=2,max=4294967295"`
+	CoverImageUrl string   `form:"cover_image_url" binding:"omitempty,url"`
+	ModifiedBy    string   `form:"-"`                                         // 由认证信息填充
+	State         *uint8   `form:"state" binding:"omitempty,oneof=0 1 2 3 4"` // 未传入时保持文章原有的状态不变
+	PublishAt     uint32   `form:"publish_at"`                                // 计划发布时间，仅在变更为定时发布时使用
+	IfMatch       string   `form:"-"`                                         // 由 If-Match 请求头填充，为空时不校验
 }
 
 type DeleteArticleRequest struct {
@@ -47,161 +51,369 @@ func (svc *Service) GetArticle(param *ArticleRequest) (model.Article, error) {
 	return svc.dao.GetArticle(param.ID, param.State)
 }
 
-func (svc *Service) GetArticleList(param *ArticleListRequest, pager *app.Pager) ([]*model.Article, int, error) {
-	articleCount, err := svc.dao.CountArticle(param.Title, param.State)
-	articles, err := svc.dao.GetArticleList(param.Title, param.State, pager.Page, pager.PageSize)
-	return articles, articleCount, err
-}
-
-func (svc *Service) Create(param *CreateArticleRequest) error {
-	fmt.Println(param)
-	_, err := svc.dao.CreateArticle(&dao.Article{
-		Title:         param.Title,
-		Desc:          param.Desc,
-		Content:       param.Content,
-		CoverImageUrl: param.CoverImageUrl,
-		State:         param.State,
-		CreatedBy:     param.CreatedBy,
-	})
+func (svc *Service) GetArticleList(param *ArticleListRequest, spec *app.QuerySpec, pager *app.Pager) ([]*model.Article, int, error) {
+	articleCount, err := svc.dao.CountArticle(param.Title, param.CreatedBy, param.State, spec)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
-
-	return nil
-}
-
-func (svc *Service) Update(param *UpdateArticleRequest) error {
-	return svc.dao.UpdateArticle(&dao.Article{
-		ID:            param.ID,
-		Title:         param.Title,
-		Desc:          param.Desc,
-		Content:       param.Content,
-		CoverImageUrl: param.CoverImageUrl,
-		State:         param.State,
-		ModifiedBy:    param.ModifiedBy,
-	})
+	articles, err := svc.dao.GetArticleList(param.Title, param.CreatedBy, param.State, spec, pager.Page, pager.PageSize)
+	return articles, articleCount, err
 }
 
-func (svc *Service) Delete(param *DeleteArticleRequest) error {
-	return svc.dao.DeleteArticle(param.ID)
+type Article struct {
+	ID            uint32       `json:"id"`
+	Title         string       `json:"title"`
+	Desc          string       `json:"desc"`
+	Content       string       `json:"content"`
+	CoverImageUrl string       `json:"cover_image_url"`
+	State         uint8        `json:"state"`
+	PublishAt     uint32       `json:"publish_at"`
+	CreatedBy     string       `json:"created_by"`
+	CreatedOn     uint32       `json:"created_on"`
+	ModifiedOn    uint32       `json:"modified_on"`
+	Tags          []*model.Tag `json:"tags"`
 }
 
-type Article struct {
-	ID            uint32     `json:"id"`
-	Title         string     `json:"title"`
-	Desc          string     `json:"desc"`
-	Content       string     `json:"content"`
-	CoverImageUrl string     `json:"cover_image_url"`
-	State         uint8      `json:"state"`
-	Tag           *model.Tag `json:"tag"`
+// 文章及其标签中最晚的修改时间，实现 app.LastModifier，标签修改后响应中的标签同样会变化
+func (a *Article) LastModified() time.Time {
+	lastModified := (&model.Model{CreatedOn: a.CreatedOn, ModifiedOn: a.ModifiedOn}).LastModified()
+	for _, tag := range a.Tags {
+		if t := tag.LastModified(); t.After(lastModified) {
+			lastModified = t
+		}
+	}
+	return lastModified
 }
 
+// 文章及其标签按 ID 与状态缓存，文章或其关联的标签变更后失效
 func (svc *Service) GetArticleWithTag(param *ArticleRequest) (*Article, error) {
-	article, err := svc.dao.GetArticle(param.ID, param.State)
-	if err != nil {
-		return nil, err
-	}
+	var result *Article
+	err := svc.loadCache(articleCache, articleCacheKey(param.ID, param.State), &result, func() (interface{}, error) {
+		article, err := svc.dao.GetArticle(param.ID, param.State)
+		if err != nil {
+			return nil, err
+		}
+		// 文章不存在或状态不符时返回错误，不写入缓存
+		if article.ID == 0 {
+			return nil, ErrArticleNotFound
+		}
 
-	articleTag, err := svc.dao.GetArticleTagByAID(article.ID)
-	if err != nil {
-		return nil, err
-	}
+		tagsMap, err := svc.getArticleTagsMap([]uint32{article.ID})
+		if err != nil {
+			return nil, err
+		}
 
-	tag, err := svc.dao.GetTag(articleTag.TagID, model.STATE_OPEN)
+		return newArticle(&article, tagsMap[article.ID]), nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return &Article{
-		ID:            article.ID,
-		Title:         article.Title,
-		Desc:          article.Desc,
-		Content:       article.Content,
-		CoverImageUrl: article.CoverImageUrl,
-		State:         article.State,
-		Tag:           tag,
-	}, nil
+	return result, nil
 }
 
-func (svc *Service) GetArticleListWithTag(param *ArticleListRequest, pager *app.Pager) ([]*Article, int, error) {
-	articleCount, err := svc.dao.CountArticleListByTagID(param.TagID, param.State)
+// 获取文章列表及文章关联的标签，传入 tag_id 时仅返回关联了该标签的文章
+func (svc *Service) GetArticleListWithTag(param *ArticleListRequest, spec *app.QuerySpec, pager *app.Pager) ([]*Article, int, error) {
+	var articles []*model.Article
+	var articleCount int
+	var err error
+	if param.TagID > 0 {
+		articleCount, err = svc.dao.CountArticleListByTagID(param.TagID, param.CreatedBy, param.State, spec)
+		if err != nil {
+			return nil, 0, err
+		}
+		articles, err = svc.dao.GetArticleListByTagID(param.TagID, param.CreatedBy, param.State, spec, pager.Page, pager.PageSize)
+	} else {
+		articles, articleCount, err = svc.GetArticleList(param, spec, pager)
+	}
 	if err != nil {
 		return nil, 0, err
 	}
 
-	articles, err := svc.dao.GetArticleListByTagID(param.TagID, param.State, pager.Page, pager.PageSize)
+	// 游标分页时多读取了一条记录，截断后生成下一页的游标
+	n, nextCursor := spec.NextCursor(len(articles), pager.PageSize, func(i int) app.Cursor {
+		return app.Cursor{CreatedOn: articles[i].CreatedOn, ID: articles[i].ID}
+	})
+	articles, pager.NextCursor = articles[:n], nextCursor
+
+	articleIDs := make([]uint32, 0, len(articles))
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article.ID)
+	}
+	tagsMap, err := svc.getArticleTagsMap(articleIDs)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	var articleList []*Article
 	for _, article := range articles {
-		articleList = append(articleList, &Article{
-			ID:            article.ArticleID,
-			Title:         article.ArticleTitle,
-			Desc:          article.ArticleDesc,
-			Content:       article.Content,
-			CoverImageUrl: article.CoverImageUrl,
-			Tag:           &model.Tag{Model: &model.Model{ID: article.TagID}, Name: article.TagName},
-		})
+		articleList = append(articleList, newArticle(article, tagsMap[article.ID]))
 	}
 
 	return articleList, articleCount, nil
 }
 
 func (svc *Service) CreateArticle(param *CreateArticleRequest) error {
-	article, err := svc.dao.CreateArticle(&dao.Article{
-		Title:         param.Title,
-		Desc:          param.Desc,
-		Content:       param.Content,
-		CoverImageUrl: param.CoverImageUrl,
-		State:         param.State,
-		CreatedBy:     param.CreatedBy,
+	tagIDs := uniqueTagIDs(param.TagIDs)
+	if err := svc.checkTagIDs(tagIDs); err != nil {
+		return err
+	}
+
+	// 文章、标签关联与初始版本在同一个事务中创建，失败时不会留下没有标签或修订历史的文章
+	var articleID uint32
+	err := svc.transaction(func(tx *Service) error {
+		article, err := tx.dao.CreateArticle(&dao.Article{
+			Title:         param.Title,
+			Desc:          param.Desc,
+			Content:       param.Content,
+			CoverImageUrl: param.CoverImageUrl,
+			State:         param.State,
+			CreatedBy:     param.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, tagID := range tagIDs {
+			if err = tx.dao.CreateArticleTag(article.ID, tagID, param.CreatedBy); err != nil {
+				return err
+			}
+		}
+
+		// 保存文章的初始版本，作为修订历史的起点
+		if _, err = tx.dao.CreateArticleRevision(article, param.CreatedBy); err != nil {
+			return err
+		}
+		articleID = article.ID
+		return nil
 	})
 	if err != nil {
 		return err
 	}
 
-	err = svc.dao.CreateArticleTag(article.ID, param.TagID, param.CreatedBy)
+	return svc.refreshArticleIndex(articleID)
+}
+
+func (svc *Service) UpdateArticle(param *UpdateArticleRequest) error {
+	var tagIDs []uint32
+	if param.TagIDs != nil {
+		tagIDs = uniqueTagIDs(param.TagIDs)
+		if err := svc.checkTagIDs(tagIDs); err != nil {
+			return err
+		}
+	}
+
+	// 校验、更新、修订版本与标签在同一个事务中完成，失败时不会只更新一部分
+	err := svc.transaction(func(tx *Service) error {
+		article, err := tx.getArticle(param.ID)
+		if err != nil {
+			return err
+		}
+		if err = tx.checkArticleIfMatch(param.IfMatch, article); err != nil {
+			return err
+		}
+		state, publishAt, err := nextArticleState(article, param.State, param.PublishAt)
+		if err != nil {
+			return err
+		}
+
+		err = tx.updateArticle(&dao.Article{
+			ID:            param.ID,
+			Title:         param.Title,
+			Desc:          param.Desc,
+			Content:       param.Content,
+			CoverImageUrl: param.CoverImageUrl,
+			State:         state,
+			PublishAt:     publishAt,
+			ModifiedBy:    param.ModifiedBy,
+		}, param.IfMatch, article)
+		if err != nil {
+			return err
+		}
+
+		// 每次更新都保存一份完整的快照，用于查看历史与恢复
+		if _, err = tx.createArticleRevision(param.ID, param.ModifiedBy); err != nil {
+			return err
+		}
+		if param.TagIDs == nil {
+			return nil
+		}
+		return tx.updateArticleTags(param.ID, tagIDs, param.ModifiedBy)
+	})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	svc.invalidateArticleCache(param.ID)
+	return svc.refreshArticleIndex(param.ID)
 }
 
-func (svc *Service) UpdateArticle(param *UpdateArticleRequest) error {
-	err := svc.dao.UpdateArticle(&dao.Article{
-		ID:            param.ID,
-		Title:         param.Title,
-		Desc:          param.Desc,
-		Content:       param.Content,
-		CoverImageUrl: param.CoverImageUrl,
-		State:         param.State,
-		ModifiedBy:    param.ModifiedBy,
+func (svc *Service) DeleteArticle(param *DeleteArticleRequest) error {
+	// 文章与标签关联一起删除，不会留下指向已删除文章的关联
+	err := svc.transaction(func(tx *Service) error {
+		if err := tx.dao.DeleteArticle(param.ID); err != nil {
+			return err
+		}
+		return tx.dao.DeleteArticleTag(param.ID)
 	})
 	if err != nil {
 		return err
 	}
 
-	err = svc.dao.UpdateArticleTag(param.ID, param.TagID, param.ModifiedBy)
+	svc.invalidateArticleCache(param.ID)
+	return svc.refreshArticleIndex(param.ID)
+}
+
+// 校验 If-Match，为空时不校验
+// 与获取文章接口返回的数据一致，标签变更也会使 ETag 变化
+func (svc *Service) checkArticleIfMatch(ifMatch string, article *model.Article) error {
+	if ifMatch == "" {
+		return nil
+	}
+	tagsMap, err := svc.getArticleTagsMap([]uint32{article.ID})
 	if err != nil {
 		return err
 	}
+	return checkIfMatch(ifMatch, newArticle(article, tagsMap[article.ID]))
+}
 
+// 更新文章，article 为校验 If-Match 时读取的文章
+// 校验了 If-Match 时使用条件更新，校验之后文章被其他请求修改时不覆盖其他请求的修改
+func (svc *Service) updateArticle(values *dao.Article, ifMatch string, article *model.Article) error {
+	if ifMatch == "" {
+		return svc.dao.UpdateArticle(values)
+	}
+	ok, err := svc.dao.UpdateArticleIfUnmodified(values, article.Version)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPreconditionFailed
+	}
 	return nil
 }
 
-func (svc *Service) DeleteArticle(param *DeleteArticleRequest) error {
-	err := svc.dao.DeleteArticle(param.ID)
+// 对比文章现有的标签集合，仅新增缺少的关联并删除多余的关联
+func (svc *Service) updateArticleTags(articleID uint32, tagIDs []uint32, modifiedBy string) error {
+	articleTags, err := svc.dao.GetArticleTagListByAID(articleID)
 	if err != nil {
 		return err
 	}
 
-	err = svc.dao.DeleteArticleTag(param.ID)
+	existed := make(map[uint32]bool, len(articleTags))
+	for _, articleTag := range articleTags {
+		existed[articleTag.TagID] = true
+	}
+	wanted := make(map[uint32]bool, len(tagIDs))
+	for _, tagID := range tagIDs {
+		wanted[tagID] = true
+	}
+
+	var removed []uint32
+	for tagID := range existed {
+		if !wanted[tagID] {
+			removed = append(removed, tagID)
+		}
+	}
+	if len(removed) > 0 {
+		if err := svc.dao.DeleteArticleTagByTIDs(articleID, removed); err != nil {
+			return err
+		}
+	}
+
+	for _, tagID := range tagIDs {
+		if existed[tagID] {
+			continue
+		}
+		if err := svc.dao.CreateArticleTag(articleID, tagID, modifiedBy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 批量获取文章关联的标签，key 为文章 ID，仅返回启用状态的标签
+func (svc *Service) getArticleTagsMap(articleIDs []uint32) (map[uint32][]*model.Tag, error) {
+	tagsMap := make(map[uint32][]*model.Tag, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return tagsMap, nil
+	}
+
+	articleTags, err := svc.dao.GetArticleTagListByAIDs(articleIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(articleTags) == 0 {
+		return tagsMap, nil
+	}
+
+	var tagIDs []uint32
+	for _, articleTag := range articleTags {
+		tagIDs = append(tagIDs, articleTag.TagID)
+	}
+	tags, err := svc.dao.GetTagListByIDs(uniqueTagIDs(tagIDs), model.STATE_OPEN)
+	if err != nil {
+		return nil, err
+	}
+
+	tagByID := make(map[uint32]*model.Tag, len(tags))
+	for _, tag := range tags {
+		tagByID[tag.ID] = tag
+	}
+	for _, articleTag := range articleTags {
+		if tag, ok := tagByID[articleTag.TagID]; ok {
+			tagsMap[articleTag.ArticleID] = append(tagsMap[articleTag.ArticleID], tag)
+		}
+	}
+
+	return tagsMap, nil
+}
+
+// 检查标签是否都存在且处于启用状态
+func (svc *Service) checkTagIDs(tagIDs []uint32) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	tags, err := svc.dao.GetTagListByIDs(tagIDs, model.STATE_OPEN)
 	if err != nil {
 		return err
 	}
+	if len(tags) != len(tagIDs) {
+		return fmt.Errorf("标签 %v 中存在不存在或未启用的标签", tagIDs)
+	}
 
 	return nil
 }
+
+func newArticle(article *model.Article, tags []*model.Tag) *Article {
+	if tags == nil {
+		tags = []*model.Tag{}
+	}
+	return &Article{
+		ID:            article.ID,
+		Title:         article.Title,
+		Desc:          article.Desc,
+		Content:       article.Content,
+		CoverImageUrl: article.CoverImageUrl,
+		State:         article.State,
+		PublishAt:     article.PublishAt,
+		CreatedBy:     article.CreatedBy,
+		CreatedOn:     article.CreatedOn,
+		ModifiedOn:    article.ModifiedOn,
+		Tags:          tags,
+	}
+}
+
+// 去除重复的标签 ID，保持原有顺序
+func uniqueTagIDs(tagIDs []uint32) []uint32 {
+	seen := make(map[uint32]bool, len(tagIDs))
+	result := make([]uint32, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		if seen[tagID] {
+			continue
+		}
+		seen[tagID] = true
+		result = append(result, tagID)
+	}
+	return result
+}
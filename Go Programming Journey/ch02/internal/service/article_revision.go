@@ -0,0 +1,176 @@
+package service
+
+import (
+	"demo/ch02/internal/dao"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/diff"
+	"errors"
+)
+
+var (
+	ErrArticleNotFound         = errors.New("文章不存在")
+	ErrArticleRevisionNotFound = errors.New("文章修订版本不存在")
+)
+
+type ArticleRevisionListRequest struct {
+	ArticleID uint32 `form:"id" binding:"required,gte=1"`
+}
+
+type ArticleRevisionRequest struct {
+	ArticleID  uint32 `form:"id" binding:"required,gte=1"`
+	RevisionID uint32 `form:"revision_id" binding:"required,gte=1"`
+}
+
+type ArticleRevisionDiffRequest struct {
+	ArticleID uint32 `form:"id" binding:"required,gte=1"`
+	From      uint32 `form:"from" binding:"required,gte=1"`
+	To        uint32 `form:"to" binding:"required,gte=1"`
+}
+
+type RestoreArticleRevisionRequest struct {
+	ArticleID  uint32 `form:"id" binding:"required,gte=1"`
+	RevisionID uint32 `form:"revision_id" binding:"required,gte=1"`
+	ModifiedBy string `form:"-"` // 由认证信息填充
+	IfMatch    string `form:"-"` // 由 If-Match 请求头填充，为空时不校验
+}
+
+// 修订版本的基本信息
+type ArticleRevisionInfo struct {
+	ID        uint32 `json:"id"`
+	CreatedBy string `json:"created_by"`
+	CreatedOn uint32 `json:"created_on"`
+}
+
+// 两个修订版本之间各字段的逐行差异
+type ArticleRevisionDiff struct {
+	From          ArticleRevisionInfo `json:"from"`
+	To            ArticleRevisionInfo `json:"to"`
+	Title         []diff.Line         `json:"title"`
+	Desc          []diff.Line         `json:"desc"`
+	CoverImageUrl []diff.Line         `json:"cover_image_url"`
+	Content       []diff.Line         `json:"content"`
+	Stat          diff.Stat           `json:"stat"`
+}
+
+func (svc *Service) GetArticleRevisionList(param *ArticleRevisionListRequest, pager *app.Pager) ([]*model.ArticleRevision, int, error) {
+	totalRows, err := svc.dao.CountArticleRevision(param.ArticleID)
+	if err != nil {
+		return nil, 0, err
+	}
+	revisions, err := svc.dao.GetArticleRevisionList(param.ArticleID, pager.Page, pager.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return revisions, totalRows, nil
+}
+
+func (svc *Service) GetArticleRevision(param *ArticleRevisionRequest) (*model.ArticleRevision, error) {
+	return svc.getArticleRevision(param.ArticleID, param.RevisionID)
+}
+
+func (svc *Service) DiffArticleRevision(param *ArticleRevisionDiffRequest) (*ArticleRevisionDiff, error) {
+	from, err := svc.getArticleRevision(param.ArticleID, param.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := svc.getArticleRevision(param.ArticleID, param.To)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArticleRevisionDiff{
+		From:          newArticleRevisionInfo(from),
+		To:            newArticleRevisionInfo(to),
+		Title:         diff.Lines(from.Title, to.Title),
+		Desc:          diff.Lines(from.Desc, to.Desc),
+		CoverImageUrl: diff.Lines(from.CoverImageUrl, to.CoverImageUrl),
+		Content:       diff.Lines(from.Content, to.Content),
+	}
+	for _, lines := range [][]diff.Line{result.Title, result.Desc, result.CoverImageUrl, result.Content} {
+		stat := diff.Count(lines)
+		result.Stat.Insertions += stat.Insertions
+		result.Stat.Deletions += stat.Deletions
+	}
+	return result, nil
+}
+
+// 将文章恢复为指定修订版本的内容，恢复操作本身会生成一个新的修订版本，不会覆盖历史
+// 与更新文章相同，在事务中完成并支持 If-Match 校验
+func (svc *Service) RestoreArticleRevision(param *RestoreArticleRevisionRequest) (*model.ArticleRevision, error) {
+	var restored *model.ArticleRevision
+	err := svc.transaction(func(tx *Service) error {
+		revision, err := tx.getArticleRevision(param.ArticleID, param.RevisionID)
+		if err != nil {
+			return err
+		}
+		article, err := tx.getArticle(param.ArticleID)
+		if err != nil {
+			return err
+		}
+		if err = tx.checkArticleIfMatch(param.IfMatch, article); err != nil {
+			return err
+		}
+
+		err = tx.updateArticle(&dao.Article{
+			ID:            param.ArticleID,
+			Title:         revision.Title,
+			Desc:          revision.Desc,
+			Content:       revision.Content,
+			CoverImageUrl: revision.CoverImageUrl,
+			State:         article.State,
+			PublishAt:     article.PublishAt,
+			ModifiedBy:    param.ModifiedBy,
+		}, param.IfMatch, article)
+		if err != nil {
+			return err
+		}
+		restored, err = tx.createArticleRevision(param.ArticleID, param.ModifiedBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	svc.invalidateArticleCache(param.ArticleID)
+	if err = svc.refreshArticleIndex(param.ArticleID); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// 保存文章当前内容的快照
+func (svc *Service) createArticleRevision(articleID uint32, createdBy string) (*model.ArticleRevision, error) {
+	article, err := svc.getArticle(articleID)
+	if err != nil {
+		return nil, err
+	}
+	return svc.dao.CreateArticleRevision(article, createdBy)
+}
+
+// 获取未删除的文章，不限制文章状态
+func (svc *Service) getArticle(id uint32) (*model.Article, error) {
+	articles, err := svc.dao.GetArticleListByIDs([]uint32{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(articles) == 0 {
+		return nil, ErrArticleNotFound
+	}
+	return articles[0], nil
+}
+
+func (svc *Service) getArticleRevision(articleID, revisionID uint32) (*model.ArticleRevision, error) {
+	revision, err := svc.dao.GetArticleRevision(articleID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Model == nil || revision.ID == 0 {
+		return nil, ErrArticleRevisionNotFound
+	}
+	return &revision, nil
+}
+
+func newArticleRevisionInfo(revision *model.ArticleRevision) ArticleRevisionInfo {
+	return ArticleRevisionInfo{ID: revision.ID, CreatedBy: revision.CreatedBy, CreatedOn: revision.CreatedOn}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+)
+
+func TestRestoreArticleRevision(t *testing.T) {
+	svc := newTestService(t)
+	if err := svc.CreateArticle(&CreateArticleRequest{Title: "first", State: model.ARTICLE_STATE_DRAFT}); err != nil {
+		t.Fatalf("CreateArticle err: %v", err)
+	}
+	if err := svc.UpdateArticle(&UpdateArticleRequest{ID: 1, Title: "second"}); err != nil {
+		t.Fatalf("UpdateArticle err: %v", err)
+	}
+	current, err := svc.GetArticleWithTag(&ArticleRequest{ID: 1, State: model.ARTICLE_STATE_DRAFT})
+	if err != nil {
+		t.Fatalf("GetArticleWithTag err: %v", err)
+	}
+	etag, _ := app.NewETag(current)
+
+	tests := []struct {
+		name          string
+		revisionID    uint32
+		ifMatch       string
+		wantErr       error
+		wantTitle     string
+		wantRevisions int
+	}{
+		{name: "missing revision", revisionID: 10, wantErr: ErrArticleRevisionNotFound, wantTitle: "second", wantRevisions: 2},
+		{name: "stale", revisionID: 1, ifMatch: `"stale"`, wantErr: ErrPreconditionFailed, wantTitle: "second", wantRevisions: 2},
+		{name: "match", revisionID: 1, ifMatch: etag, wantTitle: "first", wantRevisions: 3},
+		// 恢复后 ETag 已变化
+		{name: "reused", revisionID: 2, ifMatch: etag, wantErr: ErrPreconditionFailed, wantTitle: "first", wantRevisions: 3},
+		{name: "no if-match", revisionID: 2, wantTitle: "second", wantRevisions: 4},
+	}
+	for _, tt := range tests {
+		revision, err := svc.RestoreArticleRevision(&RestoreArticleRevisionRequest{ArticleID: 1, RevisionID: tt.revisionID, IfMatch: tt.ifMatch})
+		if err != tt.wantErr {
+			t.Errorf("%s: RestoreArticleRevision err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && revision.Title != tt.wantTitle {
+			t.Errorf("%s: revision.Title = %q, want %q", tt.name, revision.Title, tt.wantTitle)
+		}
+		article, _ := svc.getArticle(1)
+		if article.Title != tt.wantTitle {
+			t.Errorf("%s: Title = %q, want %q", tt.name, article.Title, tt.wantTitle)
+		}
+		if revisions, _ := svc.dao.CountArticleRevision(1); revisions != tt.wantRevisions {
+			t.Errorf("%s: revisions = %d, want %d", tt.name, revisions, tt.wantRevisions)
+		}
+	}
+}
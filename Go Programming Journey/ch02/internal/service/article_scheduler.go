@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"demo/ch02/global"
+	"sync"
+	"time"
+)
+
+// 定时发布时记录的修改者
+const articleSchedulerOperator = "scheduler"
+
+// 每次最多发布的文章数量，超出部分在下一次执行时继续处理
+const articleSchedulerBatchSize = 100
+
+// 发布计划发布时间已到的文章，返回成功发布的数量
+func (svc *Service) PublishDueArticles(now time.Time) (int, error) {
+	articles, err := svc.dao.GetDueArticles(uint32(now.Unix()), articleSchedulerBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var published int
+	for _, article := range articles {
+		ok, err := svc.dao.PublishArticle(article.ID, articleSchedulerOperator)
+		if err != nil {
+			return published, err
+		}
+		// 文章在此期间已被改为其他状态
+		if !ok {
+			continue
+		}
+		published++
+		svc.invalidateArticleCache(article.ID)
+		if err = svc.refreshArticleIndex(article.ID); err != nil {
+			return published, err
+		}
+	}
+	return published, nil
+}
+
+// ArticleScheduler 在后台定期发布到期的定时发布文章
+type ArticleScheduler struct {
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func NewArticleScheduler(interval time.Duration) *ArticleScheduler {
+	return &ArticleScheduler{
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// 启动后台 goroutine，启动时立即执行一次，之后每隔 interval 执行一次
+func (s *ArticleScheduler) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.run()
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// 通知后台 goroutine 退出并等待当前正在执行的发布任务完成，ctx 超时时直接返回
+func (s *ArticleScheduler) Stop(ctx context.Context) error {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ArticleScheduler) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+	defer cancel()
+
+	svc := New(ctx)
+	published, err := svc.PublishDueArticles(time.Now())
+	if err != nil {
+		global.Logger.Errorf(ctx, "svc.PublishDueArticles err: %v", err)
+	}
+	if published > 0 {
+		global.Logger.Infof(ctx, "svc.PublishDueArticles published: %d", published)
+	}
+}
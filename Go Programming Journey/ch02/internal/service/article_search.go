@@ -0,0 +1,217 @@
+package service
+
+import (
+	"demo/ch02/global"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/search"
+	"sync"
+)
+
+type ArticleSearchRequest struct {
+	Query string `form:"q" binding:"required,min=1,max=100"`
+	State uint8  `form:"state,default=1" binding:"oneof=0 1 2 3 4"`
+}
+
+// 搜索结果中的高亮片段，命中的查询词使用 <em></em> 包裹
+type ArticleHighlight struct {
+	Title   string `json:"title"`
+	Desc    string `json:"desc"`
+	Content string `json:"content"`
+}
+
+type ArticleSearchResult struct {
+	ID            uint32           `json:"id"`
+	Title         string           `json:"title"`
+	Desc          string           `json:"desc"`
+	CoverImageUrl string           `json:"cover_image_url"`
+	State         uint8            `json:"state"`
+	Score         float64          `json:"score"`
+	Highlight     ArticleHighlight `json:"highlight"`
+}
+
+// 正文高亮片段的最大字符数
+const contentSnippetSize = 120
+
+// 标题、简述、正文在内置索引中的权重
+var articleIndex = &articleSearchIndex{index: search.NewIndex(3, 2, 1)}
+
+// 数据库是否存在全文索引，在第一次搜索时检查
+// 仅缓存检查成功的结果，检查失败时本次使用内置索引，下次搜索时重新检查
+var fullText struct {
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+func (svc *Service) SearchArticles(param *ArticleSearchRequest, pager *app.Pager) ([]*ArticleSearchResult, int, error) {
+	var hits []search.Hit
+	var total int
+	var err error
+	if svc.supportFullText() {
+		total, err = svc.dao.CountSearchArticle(param.Query, param.State)
+		if err != nil {
+			return nil, 0, err
+		}
+		hits, err = svc.dao.SearchArticle(param.Query, param.State, pager.Page, pager.PageSize)
+	} else {
+		hits, total, err = svc.searchArticleIndex(param, pager)
+	}
+	if err != nil || len(hits) == 0 {
+		return []*ArticleSearchResult{}, total, err
+	}
+
+	ids := make([]uint32, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	articles, err := svc.dao.GetArticleListByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	articleByID := make(map[uint32]*model.Article, len(articles))
+	for _, article := range articles {
+		articleByID[article.ID] = article
+	}
+
+	// 按命中顺序组装结果，并生成高亮片段
+	terms := search.Terms(param.Query)
+	results := make([]*ArticleSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		article, ok := articleByID[hit.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, &ArticleSearchResult{
+			ID:            article.ID,
+			Title:         article.Title,
+			Desc:          article.Desc,
+			CoverImageUrl: article.CoverImageUrl,
+			State:         article.State,
+			Score:         hit.Score,
+			Highlight: ArticleHighlight{
+				Title:   search.Highlight(article.Title, terms, len([]rune(article.Title))),
+				Desc:    search.Highlight(article.Desc, terms, len([]rune(article.Desc))),
+				Content: search.Highlight(article.Content, terms, contentSnippetSize),
+			},
+		})
+	}
+	return results, total, nil
+}
+
+func (svc *Service) supportFullText() bool {
+	fullText.mu.Lock()
+	defer fullText.mu.Unlock()
+	if fullText.checked {
+		return fullText.supported
+	}
+
+	ok, err := svc.dao.HasArticleFullTextIndex()
+	if err != nil {
+		global.Logger.Errorf(svc.ctx, "svc.dao.HasArticleFullTextIndex err: %v", err)
+		return false
+	}
+	fullText.checked, fullText.supported = true, ok
+	return ok
+}
+
+// 使用内置倒排索引搜索，索引在第一次搜索时从数据库中加载
+func (svc *Service) searchArticleIndex(param *ArticleSearchRequest, pager *app.Pager) ([]search.Hit, int, error) {
+	if err := articleIndex.load(svc); err != nil {
+		return nil, 0, err
+	}
+
+	var hits []search.Hit
+	for _, hit := range articleIndex.index.Search(param.Query) {
+		if state, ok := articleIndex.state(hit.ID); ok && state == param.State {
+			hits = append(hits, hit)
+		}
+	}
+
+	total := len(hits)
+	start := app.GetPageOffset(pager.Page, pager.PageSize)
+	if start > total {
+		start = total
+	}
+	end := start + pager.PageSize
+	if end > total {
+		end = total
+	}
+	return hits[start:end], total, nil
+}
+
+// 文章创建、更新、删除后同步内置索引，索引尚未加载时无需处理
+func (svc *Service) refreshArticleIndex(id uint32) error {
+	if !articleIndex.loaded() {
+		return nil
+	}
+	articles, err := svc.dao.GetArticleListByIDs([]uint32{id})
+	if err != nil {
+		return err
+	}
+	if len(articles) == 0 {
+		articleIndex.remove(id)
+		return nil
+	}
+	articleIndex.add(articles[0])
+	return nil
+}
+
+// 内置倒排索引，额外记录文章状态用于筛选
+// 索引仅保存在当前进程中，多实例部署时建议使用 MySQL 全文索引
+type articleSearchIndex struct {
+	mu     sync.RWMutex
+	ready  bool
+	states map[uint32]uint8
+	index  *search.Index
+}
+
+func (i *articleSearchIndex) load(svc *Service) error {
+	if i.loaded() {
+		return nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.ready {
+		return nil
+	}
+	articles, err := svc.dao.GetAllArticles()
+	if err != nil {
+		return err
+	}
+	i.states = make(map[uint32]uint8, len(articles))
+	for _, article := range articles {
+		i.states[article.ID] = article.State
+		i.index.Add(article.ID, article.Title, article.Desc, article.Content)
+	}
+	i.ready = true
+	return nil
+}
+
+func (i *articleSearchIndex) loaded() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.ready
+}
+
+func (i *articleSearchIndex) state(id uint32) (uint8, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	state, ok := i.states[id]
+	return state, ok
+}
+
+func (i *articleSearchIndex) add(article *model.Article) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.states[article.ID] = article.State
+	i.index.Add(article.ID, article.Title, article.Desc, article.Content)
+}
+
+func (i *articleSearchIndex) remove(id uint32) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.states, id)
+	i.index.Remove(id)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"demo/ch02/internal/model"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrArticleStateTransition = errors.New("文章状态变更不合法")
+
+// 文章状态的名称，用于错误提示
+var articleStateNames = map[uint8]string{
+	model.ARTICLE_STATE_ARCHIVED:  "已归档",
+	model.ARTICLE_STATE_PUBLISHED: "已发布",
+	model.ARTICLE_STATE_DRAFT:     "草稿",
+	model.ARTICLE_STATE_IN_REVIEW: "审核中",
+	model.ARTICLE_STATE_SCHEDULED: "定时发布",
+}
+
+// 文章状态允许的流转，key 为当前状态，value 为可以变更到的状态
+// 草稿 -> 审核中 -> 定时发布 -> 已发布 -> 已归档，审核不通过或取消定时发布时退回草稿，归档的文章可重新编辑
+var articleStateTransitions = map[uint8][]uint8{
+	model.ARTICLE_STATE_DRAFT:     {model.ARTICLE_STATE_IN_REVIEW},
+	model.ARTICLE_STATE_IN_REVIEW: {model.ARTICLE_STATE_DRAFT, model.ARTICLE_STATE_SCHEDULED, model.ARTICLE_STATE_PUBLISHED},
+	model.ARTICLE_STATE_SCHEDULED: {model.ARTICLE_STATE_DRAFT, model.ARTICLE_STATE_PUBLISHED},
+	model.ARTICLE_STATE_PUBLISHED: {model.ARTICLE_STATE_ARCHIVED},
+	model.ARTICLE_STATE_ARCHIVED:  {model.ARTICLE_STATE_DRAFT},
+}
+
+// 根据文章当前状态计算更新后的状态与发布时间，state 为 nil 时保持原有状态
+// 变更为定时发布时 publishAt 必须晚于当前时间，已定时发布的文章可以通过 publishAt 修改计划发布时间
+func nextArticleState(article *model.Article, state *uint8, publishAt uint32) (uint8, uint32, error) {
+	current := article.State
+	next := current
+	if state != nil {
+		next = *state
+	}
+	if next != current && !canTransitArticleState(current, next) {
+		return 0, 0, fmt.Errorf("%w: 不能从%s变更为%s", ErrArticleStateTransition, articleStateNames[current], articleStateNames[next])
+	}
+
+	now := uint32(time.Now().Unix())
+	switch {
+	case next == model.ARTICLE_STATE_SCHEDULED:
+		if publishAt == 0 && next == current {
+			return next, article.PublishAt, nil
+		}
+		if publishAt <= now {
+			return 0, 0, fmt.Errorf("%w: 定时发布的 publish_at 必须晚于当前时间", ErrArticleStateTransition)
+		}
+		return next, publishAt, nil
+	case publishAt != 0:
+		return 0, 0, fmt.Errorf("%w: 仅定时发布的文章可以设置 publish_at", ErrArticleStateTransition)
+	case next == current:
+		return next, article.PublishAt, nil
+	case next == model.ARTICLE_STATE_PUBLISHED:
+		return next, now, nil
+	case current == model.ARTICLE_STATE_SCHEDULED:
+		// 取消定时发布，清除计划发布时间
+		return next, 0, nil
+	}
+	return next, article.PublishAt, nil
+}
+
+func canTransitArticleState(from, to uint8) bool {
+	for _, state := range articleStateTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
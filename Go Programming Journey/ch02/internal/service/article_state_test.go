@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"demo/ch02/internal/model"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func uint8Ptr(v uint8) *uint8 {
+	return &v
+}
+
+func TestNextArticleState(t *testing.T) {
+	now := uint32(time.Now().Unix())
+	future := now + 3600
+	tests := []struct {
+		name          string
+		current       uint8
+		publishAt     uint32 // 文章原有的计划发布时间
+		state         *uint8
+		paramPublish  uint32
+		wantState     uint8
+		wantPublishAt uint32 // 为 1 时表示应为当前时间
+		wantErr       bool
+	}{
+		{name: "keep state", current: model.ARTICLE_STATE_DRAFT, wantState: model.ARTICLE_STATE_DRAFT},
+		{name: "draft to review", current: model.ARTICLE_STATE_DRAFT, state: uint8Ptr(model.ARTICLE_STATE_IN_REVIEW), wantState: model.ARTICLE_STATE_IN_REVIEW},
+		{name: "draft to published", current: model.ARTICLE_STATE_DRAFT, state: uint8Ptr(model.ARTICLE_STATE_PUBLISHED), wantErr: true},
+		{name: "review to published", current: model.ARTICLE_STATE_IN_REVIEW, state: uint8Ptr(model.ARTICLE_STATE_PUBLISHED), wantState: model.ARTICLE_STATE_PUBLISHED, wantPublishAt: 1},
+		{name: "review to scheduled", current: model.ARTICLE_STATE_IN_REVIEW, state: uint8Ptr(model.ARTICLE_STATE_SCHEDULED), paramPublish: future, wantState: model.ARTICLE_STATE_SCHEDULED, wantPublishAt: future},
+		{name: "scheduled in the past", current: model.ARTICLE_STATE_IN_REVIEW, state: uint8Ptr(model.ARTICLE_STATE_SCHEDULED), paramPublish: now - 1, wantErr: true},
+		{name: "keep schedule", current: model.ARTICLE_STATE_SCHEDULED, publishAt: future, wantState: model.ARTICLE_STATE_SCHEDULED, wantPublishAt: future},
+		{name: "reschedule", current: model.ARTICLE_STATE_SCHEDULED, publishAt: future, paramPublish: future + 60, wantState: model.ARTICLE_STATE_SCHEDULED, wantPublishAt: future + 60},
+		{name: "cancel schedule", current: model.ARTICLE_STATE_SCHEDULED, publishAt: future, state: uint8Ptr(model.ARTICLE_STATE_DRAFT), wantState: model.ARTICLE_STATE_DRAFT},
+		{name: "publish_at on draft", current: model.ARTICLE_STATE_DRAFT, paramPublish: future, wantErr: true},
+		{name: "published to archived", current: model.ARTICLE_STATE_PUBLISHED, publishAt: now, state: uint8Ptr(model.ARTICLE_STATE_ARCHIVED), wantState: model.ARTICLE_STATE_ARCHIVED, wantPublishAt: now},
+		{name: "archived to draft", current: model.ARTICLE_STATE_ARCHIVED, state: uint8Ptr(model.ARTICLE_STATE_DRAFT), wantState: model.ARTICLE_STATE_DRAFT},
+		{name: "archived to published", current: model.ARTICLE_STATE_ARCHIVED, state: uint8Ptr(model.ARTICLE_STATE_PUBLISHED), wantErr: true},
+	}
+	for _, tt := range tests {
+		article := &model.Article{State: tt.current, PublishAt: tt.publishAt}
+		state, publishAt, err := nextArticleState(article, tt.state, tt.paramPublish)
+		if tt.wantErr {
+			if !errors.Is(err, ErrArticleStateTransition) {
+				t.Errorf("%s: err = %v, want ErrArticleStateTransition", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: err: %v", tt.name, err)
+			continue
+		}
+		if state != tt.wantState {
+			t.Errorf("%s: state = %d, want %d", tt.name, state, tt.wantState)
+		}
+		if tt.wantPublishAt == 1 {
+			if publishAt < now {
+				t.Errorf("%s: publishAt = %d, want now", tt.name, publishAt)
+			}
+		} else if publishAt != tt.wantPublishAt {
+			t.Errorf("%s: publishAt = %d, want %d", tt.name, publishAt, tt.wantPublishAt)
+		}
+	}
+}
+
+// PATCH /api/v1/articles/:id/state 与 PUT 共用请求结构，仅传入 state 时也应通过校验
+func TestUpdateArticleRequestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     UpdateArticleRequest
+		wantErr bool
+	}{
+		{name: "state only", req: UpdateArticleRequest{ID: 1, State: uint8Ptr(model.ARTICLE_STATE_IN_REVIEW)}},
+		{name: "all fields", req: UpdateArticleRequest{ID: 1, Title: "title", Desc: "desc", Content: "content", CoverImageUrl: "https://example.com/a.png"}},
+		{name: "short title", req: UpdateArticleRequest{ID: 1, Title: "t"}, wantErr: true},
+		{name: "invalid cover url", req: UpdateArticleRequest{ID: 1, CoverImageUrl: "not a url"}, wantErr: true},
+		{name: "invalid state", req: UpdateArticleRequest{ID: 1, State: uint8Ptr(9)}, wantErr: true},
+		{name: "missing id", req: UpdateArticleRequest{State: uint8Ptr(model.ARTICLE_STATE_DRAFT)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := binding.Validator.ValidateStruct(&tt.req)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateStruct err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"demo/ch02/global"
+	"demo/ch02/internal/dao"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/cache"
+)
+
+func TestGetArticleWithTag(t *testing.T) {
+	svc := newTestService(t)
+	article, err := svc.dao.CreateArticle(&dao.Article{Title: "title", State: model.ARTICLE_STATE_PUBLISHED})
+	if err != nil {
+		t.Fatalf("CreateArticle err: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      uint32
+		state   uint8
+		wantErr error
+	}{
+		{name: "published", id: article.ID, state: model.ARTICLE_STATE_PUBLISHED},
+		{name: "wrong state", id: article.ID, state: model.ARTICLE_STATE_DRAFT, wantErr: ErrArticleNotFound},
+		{name: "missing", id: article.ID + 1, state: model.ARTICLE_STATE_PUBLISHED, wantErr: ErrArticleNotFound},
+	}
+	for _, tt := range tests {
+		got, err := svc.GetArticleWithTag(&ArticleRequest{ID: tt.id, State: tt.state})
+		if err != tt.wantErr {
+			t.Errorf("%s: GetArticleWithTag err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		_, cacheErr := global.Cache.Get(svc.ctx, articleCacheKey(tt.id, tt.state))
+		if tt.wantErr != nil {
+			// 不存在的文章不写入缓存，之后创建或变更状态的文章可以立即读取
+			if cacheErr != cache.ErrNotFound {
+				t.Errorf("%s: cache.Get err = %v, want ErrNotFound", tt.name, cacheErr)
+			}
+			continue
+		}
+		if got.ID != tt.id || got.Title != "title" {
+			t.Errorf("%s: GetArticleWithTag = %+v", tt.name, got)
+		}
+		if cacheErr != nil {
+			t.Errorf("%s: cache.Get err = %v, want cached", tt.name, cacheErr)
+		}
+	}
+}
+
+func TestUpdateArticleIfMatch(t *testing.T) {
+	svc := newTestService(t)
+	created, err := svc.dao.CreateArticle(&dao.Article{Title: "title", State: model.ARTICLE_STATE_PUBLISHED})
+	if err != nil {
+		t.Fatalf("CreateArticle err: %v", err)
+	}
+	current, err := svc.GetArticleWithTag(&ArticleRequest{ID: created.ID, State: model.ARTICLE_STATE_PUBLISHED})
+	if err != nil {
+		t.Fatalf("GetArticleWithTag err: %v", err)
+	}
+	etag, _ := app.NewETag(current)
+
+	tests := []struct {
+		name      string
+		ifMatch   string
+		title     string
+		wantErr   error
+		wantTitle string
+	}{
+		{name: "stale", ifMatch: `"stale"`, title: "stale", wantErr: ErrPreconditionFailed, wantTitle: "title"},
+		{name: "match", ifMatch: etag, title: "matched", wantTitle: "matched"},
+		// 上一次更新后 ETag 已变化
+		{name: "reused", ifMatch: etag, title: "reused", wantErr: ErrPreconditionFailed, wantTitle: "matched"},
+		{name: "any", ifMatch: "*", title: "any", wantTitle: "any"},
+	}
+	for _, tt := range tests {
+		err := svc.UpdateArticle(&UpdateArticleRequest{ID: created.ID, Title: tt.title, IfMatch: tt.ifMatch})
+		if err != tt.wantErr {
+			t.Errorf("%s: UpdateArticle err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		article, _ := svc.getArticle(created.ID)
+		if article.Title != tt.wantTitle {
+			t.Errorf("%s: Title = %q, want %q", tt.name, article.Title, tt.wantTitle)
+		}
+	}
+}
+
+// 校验之后文章被其他请求修改时不更新
+func TestUpdateArticleIfUnmodified(t *testing.T) {
+	svc := newTestService(t)
+	created, err := svc.dao.CreateArticle(&dao.Article{Title: "title", State: model.ARTICLE_STATE_PUBLISHED})
+	if err != nil {
+		t.Fatalf("CreateArticle err: %v", err)
+	}
+	// 每次更新版本号加 1，同一秒内的第二次更新使用旧的版本号时不会覆盖
+	tests := []struct {
+		name    string
+		version uint32
+		want    bool
+	}{
+		{name: "modified", version: created.Version + 1, want: false},
+		{name: "unmodified", version: created.Version, want: true},
+		{name: "stale", version: created.Version, want: false},
+		{name: "next", version: created.Version + 1, want: true},
+	}
+	for _, tt := range tests {
+		ok, err := svc.dao.UpdateArticleIfUnmodified(&dao.Article{ID: created.ID, Title: tt.name, State: created.State}, tt.version)
+		if err != nil || ok != tt.want {
+			t.Errorf("%s: UpdateArticleIfUnmodified = %v, %v, want %v", tt.name, ok, err, tt.want)
+		}
+	}
+	// 不带条件的更新同样使版本号加 1
+	if err := svc.dao.UpdateArticle(&dao.Article{ID: created.ID, Title: "update", State: created.State}); err != nil {
+		t.Fatalf("UpdateArticle err: %v", err)
+	}
+	if article, _ := svc.getArticle(created.ID); article.Title != "update" || article.Version != created.Version+3 {
+		t.Errorf("Title, Version = %q, %d, want %q, %d", article.Title, article.Version, "update", created.Version+3)
+	}
+}
+
+func TestCreateArticle(t *testing.T) {
+	svc := newTestService(t)
+	if err := svc.dao.CreateTag("go", model.STATE_OPEN, "admin"); err != nil {
+		t.Fatalf("CreateTag err: %v", err)
+	}
+	param := &CreateArticleRequest{TagIDs: []uint32{1, 1}, Title: "title", State: model.ARTICLE_STATE_DRAFT, CreatedBy: "admin"}
+	if err := svc.CreateArticle(param); err != nil {
+		t.Fatalf("CreateArticle err: %v", err)
+	}
+	articleTags, _ := svc.dao.GetArticleTagListByAID(1)
+	revisions, _ := svc.dao.CountArticleRevision(1)
+	if len(articleTags) != 1 || revisions != 1 {
+		t.Errorf("len(articleTags) = %d, revisions = %d, want 1, 1", len(articleTags), revisions)
+	}
+
+	// 保存初始版本失败时文章与标签关联一起回滚
+	if err := global.DBEngine.Exec("DROP TABLE blog_article_revision").Error; err != nil {
+		t.Fatalf("DROP TABLE err: %v", err)
+	}
+	if err := svc.CreateArticle(param); err == nil {
+		t.Fatalf("CreateArticle err = nil, want error")
+	}
+	if _, err := svc.getArticle(2); err != ErrArticleNotFound {
+		t.Errorf("getArticle err = %v, want ErrArticleNotFound", err)
+	}
+	if articleTags, _ := svc.dao.GetArticleTagListByAID(2); len(articleTags) != 0 {
+		t.Errorf("len(articleTags) = %d, want 0", len(articleTags))
+	}
+}
+
+func TestDeleteArticle(t *testing.T) {
+	svc := newTestService(t)
+	if err := svc.dao.CreateTag("go", model.STATE_OPEN, "admin"); err != nil {
+		t.Fatalf("CreateTag err: %v", err)
+	}
+	if err := svc.CreateArticle(&CreateArticleRequest{TagIDs: []uint32{1}, Title: "title", State: model.ARTICLE_STATE_DRAFT}); err != nil {
+		t.Fatalf("CreateArticle err: %v", err)
+	}
+	if err := svc.DeleteArticle(&DeleteArticleRequest{ID: 1}); err != nil {
+		t.Fatalf("DeleteArticle err: %v", err)
+	}
+	if articleTags, _ := svc.dao.GetArticleTagListByAID(1); len(articleTags) != 0 {
+		t.Errorf("len(articleTags) = %d, want 0", len(articleTags))
+	}
+}
+
+// 响应的 Last-Modified 取文章与其标签中最晚的修改时间
+func TestArticleLastModified(t *testing.T) {
+	tag := func(createdOn, modifiedOn uint32) *model.Tag {
+		return &model.Tag{Model: &model.Model{CreatedOn: createdOn, ModifiedOn: modifiedOn}}
+	}
+	tests := []struct {
+		name    string
+		article *Article
+		want    time.Time
+	}{
+		{name: "empty", article: &Article{}, want: time.Time{}},
+		{name: "created", article: &Article{CreatedOn: 100}, want: time.Unix(100, 0)},
+		{name: "modified", article: &Article{CreatedOn: 100, ModifiedOn: 200}, want: time.Unix(200, 0)},
+		{name: "older tags", article: &Article{CreatedOn: 100, ModifiedOn: 200, Tags: []*model.Tag{tag(50, 150)}}, want: time.Unix(200, 0)},
+		{name: "newer tag", article: &Article{CreatedOn: 100, ModifiedOn: 200, Tags: []*model.Tag{tag(50, 0), tag(300, 0), tag(50, 250)}}, want: time.Unix(300, 0)},
+	}
+	for _, tt := range tests {
+		// ToResponse 通过 app.LastModifier 获取 Last-Modified
+		var data interface{} = tt.article
+		m, ok := data.(app.LastModifier)
+		if !ok {
+			t.Fatalf("%s: *Article does not implement app.LastModifier", tt.name)
+		}
+		if got := m.LastModified(); !got.Equal(tt.want) {
+			t.Errorf("%s: LastModified = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
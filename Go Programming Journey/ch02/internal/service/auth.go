@@ -1,19 +1,180 @@
 package service
 
-import "errors"
+import (
+	"crypto/subtle"
+	"demo/ch02/global"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	ErrInvalidToken       = errors.New("token 无效或已吊销")
+)
 
 type AuthRequest struct {
 	AppKey    string `form:"app_key" binding:"required" json:"app_key"`
-	AppSecret string `form:"app_secret" binding:"required" json:"app_secret"`
+	AppSecret string `form:"app_secret" binding:"required,max=72" json:"app_secret"`
+}
+
+type LoginRequest struct {
+	Username string `form:"username" binding:"required,max=100"`
+	Password string `form:"password" binding:"required,max=72"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `form:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `form:"refresh_token"` // 同时吊销的 refresh token，可不传入
 }
 
-func (svc *Service) CheckAuth(param *AuthRequest) error {
-	auth, err := svc.dao.GetAuth(param.AppKey, param.AppSecret)
+// 用户不存在时用于比较的哈希，使用户不存在与密码错误的耗时一致，避免通过响应时间枚举用户名
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// 校验 app_key 与 app_secret，调用方的权限由 app_key 的权限范围决定，已禁用的 app_key 无法认证
+func (svc *Service) CheckAuth(param *AuthRequest) (*app.Principal, error) {
+	auth, err := svc.dao.GetAuth(param.AppKey)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if auth.Model == nil || auth.ID == 0 {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(param.AppSecret))
+		return nil, ErrInvalidCredentials
+	}
+	if !svc.checkAppSecret(&auth, param.AppSecret) {
+		return nil, ErrInvalidCredentials
+	}
+	if auth.State != model.STATE_OPEN {
+		return nil, ErrInvalidCredentials
+	}
+	return newAppPrincipal(&auth), nil
+}
+
+// 校验 app_secret，仍为明文的 secret 校验通过后重新哈希保存
+func (svc *Service) checkAppSecret(auth *model.Auth, secret string) bool {
+	if isSecretHash(auth.AppSecret) {
+		return bcrypt.CompareHashAndPassword([]byte(auth.AppSecret), []byte(secret)) == nil
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.AppSecret), []byte(secret)) != 1 {
+		return false
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err == nil {
+		err = svc.dao.UpdateAuth(auth.ID, map[string]interface{}{"app_secret": string(hash)})
+	}
+	// 重新哈希失败不影响本次认证，下次认证成功时会再次尝试
+	if err != nil {
+		global.Logger.Errorf(svc.ctx, "svc.checkAppSecret rehash err: %v", err)
+	}
+	return true
+}
+
+// 判断 secret 是否已经是 bcrypt 哈希
+func isSecretHash(secret string) bool {
+	_, err := bcrypt.Cost([]byte(secret))
+	return err == nil
+}
+
+func newAppPrincipal(auth *model.Auth) *app.Principal {
+	return &app.Principal{Name: auth.AppKey, Role: model.ROLE_APP, Scopes: splitScopes(auth.Scopes)}
+}
+
+// 校验用户名与密码，已禁用的用户无法登录
+func (svc *Service) Login(param *LoginRequest) (*app.Principal, error) {
+	user, err := svc.dao.GetUserByUsername(param.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Model == nil || user.ID == 0 {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(param.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(param.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.State != model.STATE_OPEN {
+		return nil, ErrInvalidCredentials
+	}
+	return &app.Principal{UserID: user.ID, Name: user.Username, Role: user.Role}, nil
+}
+
+// 使用 refresh token 换取新的调用方信息，旧的 refresh token 随即吊销，不能重复使用
+func (svc *Service) RefreshToken(param *RefreshTokenRequest) (*app.Principal, error) {
+	claims, err := app.ParseToken(param.RefreshToken)
+	if err != nil || claims.TokenType != app.TokenTypeRefresh || claims.Id == "" {
+		return nil, ErrInvalidToken
+	}
+	revoked, err := svc.IsTokenRevoked(claims.Id)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+	// jti 为主键，并发使用同一个 refresh token 时只有一个请求能吊销成功
+	if err = svc.RevokeToken(claims); err != nil {
+		return nil, err
+	}
+
+	if claims.UserID == 0 {
+		// 重新读取 app_key，使权限范围变更与禁用在刷新时生效
+		auth, err := svc.dao.GetAuth(claims.Name)
+		if err != nil {
+			return nil, err
+		}
+		if auth.Model == nil || auth.ID == 0 || auth.State != model.STATE_OPEN {
+			return nil, ErrInvalidToken
+		}
+		return newAppPrincipal(&auth), nil
 	}
-	if auth.ID > 0 {
-		return nil
+
+	// 重新读取用户，使角色变更与禁用在刷新时生效
+	user, err := svc.getUser(claims.UserID)
+	if err == ErrUserNotFound {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.State != model.STATE_OPEN {
+		return nil, ErrInvalidToken
+	}
+	return &app.Principal{UserID: user.ID, Name: user.Username, Role: user.Role}, nil
+}
+
+// 注销，吊销当前的 access token，传入 refresh token 时一并吊销
+func (svc *Service) Logout(claims *app.Claims, param *LogoutRequest) error {
+	if param.RefreshToken != "" {
+		refresh, err := app.ParseToken(param.RefreshToken)
+		if err != nil || refresh.TokenType != app.TokenTypeRefresh || refresh.Name != claims.Name {
+			return ErrInvalidToken
+		}
+		revoked, err := svc.IsTokenRevoked(refresh.Id)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			if err = svc.RevokeToken(refresh); err != nil {
+				return err
+			}
+		}
+	}
+	return svc.RevokeToken(claims)
+}
+
+func (svc *Service) IsTokenRevoked(jti string) (bool, error) {
+	return svc.dao.IsTokenRevoked(jti)
+}
+
+// 将 token 加入吊销列表，并清理已过期的记录
+func (svc *Service) RevokeToken(claims *app.Claims) error {
+	if err := svc.dao.CreateTokenRevocation(claims.Id, uint32(claims.ExpiresAt)); err != nil {
+		return err
 	}
-	return errors.New("auth info does not exist.")
+	return svc.dao.DeleteExpiredTokenRevocation(uint32(time.Now().Unix()))
 }
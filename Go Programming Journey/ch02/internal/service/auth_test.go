@@ -0,0 +1,296 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"demo/ch02/global"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/setting"
+)
+
+// 在 newTestService 的基础上使用 HS256 签名 token
+func newAuthTestService(t *testing.T) Service {
+	svc := newTestService(t)
+	oldSettings := global.LoadSettings()
+	t.Cleanup(func() { global.StoreSettings(oldSettings) })
+	global.UpdateSettings(func(s *global.Settings) {
+		s.JWT = &setting.JWTSettingS{Secret: "secret", Expire: time.Hour, RefreshExpire: 2 * time.Hour}
+	})
+	return svc
+}
+
+func parseToken(t *testing.T, token string) *app.Claims {
+	claims, err := app.ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken err: %v", err)
+	}
+	return claims
+}
+
+func TestRefreshToken(t *testing.T) {
+	svc := newAuthTestService(t)
+	if _, err := svc.CreateUser(&CreateUserRequest{Username: "editor", Password: "password", Role: model.ROLE_EDITOR}); err != nil {
+		t.Fatalf("CreateUser err: %v", err)
+	}
+	pair, err := app.GenerateTokenPair(app.Principal{UserID: 1, Name: "editor", Role: model.ROLE_EDITOR})
+	if err != nil {
+		t.Fatalf("GenerateTokenPair err: %v", err)
+	}
+
+	// refresh token 只能使用一次
+	principal, err := svc.RefreshToken(&RefreshTokenRequest{RefreshToken: pair.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken err: %v", err)
+	}
+	if principal.UserID != 1 || principal.Name != "editor" || principal.Role != model.ROLE_EDITOR {
+		t.Errorf("RefreshToken = %+v", principal)
+	}
+	if _, err = svc.RefreshToken(&RefreshTokenRequest{RefreshToken: pair.RefreshToken}); err != ErrInvalidToken {
+		t.Errorf("reused RefreshToken err = %v, want ErrInvalidToken", err)
+	}
+	if revoked, _ := svc.IsTokenRevoked(parseToken(t, pair.RefreshToken).Id); !revoked {
+		t.Errorf("refresh token is not revoked after use")
+	}
+	// access token 不能用于刷新
+	if _, err = svc.RefreshToken(&RefreshTokenRequest{RefreshToken: pair.AccessToken}); err != ErrInvalidToken {
+		t.Errorf("RefreshToken with access token err = %v, want ErrInvalidToken", err)
+	}
+	if _, err = svc.RefreshToken(&RefreshTokenRequest{RefreshToken: "token"}); err != ErrInvalidToken {
+		t.Errorf("RefreshToken with malformed token err = %v, want ErrInvalidToken", err)
+	}
+}
+
+// 刷新时重新读取用户，角色变更立即生效，已禁用或已删除的用户无法刷新
+func TestRefreshTokenUserChanged(t *testing.T) {
+	svc := newAuthTestService(t)
+	for _, username := range []string{"editor", "disabled", "deleted"} {
+		if _, err := svc.CreateUser(&CreateUserRequest{Username: username, Password: "password", Role: model.ROLE_EDITOR}); err != nil {
+			t.Fatalf("CreateUser err: %v", err)
+		}
+	}
+	closed := uint8(model.STATE_CLOSE)
+	if err := svc.UpdateUser(&UpdateUserRequest{ID: 1, Role: model.ROLE_VIEWER}); err != nil {
+		t.Fatalf("UpdateUser err: %v", err)
+	}
+	if err := svc.UpdateUser(&UpdateUserRequest{ID: 2, State: &closed}); err != nil {
+		t.Fatalf("UpdateUser err: %v", err)
+	}
+	if err := svc.DeleteUser(&DeleteUserRequest{ID: 3}); err != nil {
+		t.Fatalf("DeleteUser err: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		userID   uint32
+		username string
+		wantErr  error
+		wantRole string
+	}{
+		{name: "role changed", userID: 1, username: "editor", wantRole: model.ROLE_VIEWER},
+		{name: "disabled", userID: 2, username: "disabled", wantErr: ErrInvalidToken},
+		{name: "deleted", userID: 3, username: "deleted", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		token, err := app.GenerateToken(app.Principal{UserID: tt.userID, Name: tt.username, Role: model.ROLE_EDITOR}, app.TokenTypeRefresh)
+		if err != nil {
+			t.Fatalf("GenerateToken err: %v", err)
+		}
+		principal, err := svc.RefreshToken(&RefreshTokenRequest{RefreshToken: token})
+		if err != tt.wantErr {
+			t.Errorf("%s: RefreshToken err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && principal.Role != tt.wantRole {
+			t.Errorf("%s: Role = %q, want %q", tt.name, principal.Role, tt.wantRole)
+		}
+	}
+}
+
+func TestLogout(t *testing.T) {
+	svc := newAuthTestService(t)
+	if _, err := svc.CreateUser(&CreateUserRequest{Username: "editor", Password: "password", Role: model.ROLE_EDITOR}); err != nil {
+		t.Fatalf("CreateUser err: %v", err)
+	}
+	principal := app.Principal{UserID: 1, Name: "editor", Role: model.ROLE_EDITOR}
+	other, err := app.GenerateToken(app.Principal{UserID: 2, Name: "other", Role: model.ROLE_EDITOR}, app.TokenTypeRefresh)
+	if err != nil {
+		t.Fatalf("GenerateToken err: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		refresh     func(pair *app.TokenPair) string
+		wantErr     error
+		wantRevoked bool // refresh token 是否被吊销
+	}{
+		{name: "access token only", refresh: func(*app.TokenPair) string { return "" }},
+		{name: "with refresh token", refresh: func(pair *app.TokenPair) string { return pair.RefreshToken }, wantRevoked: true},
+		// 不能吊销其他调用方的 refresh token
+		{name: "other refresh token", refresh: func(*app.TokenPair) string { return other }, wantErr: ErrInvalidToken},
+		{name: "access token as refresh token", refresh: func(pair *app.TokenPair) string { return pair.AccessToken }, wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		pair, err := app.GenerateTokenPair(principal)
+		if err != nil {
+			t.Fatalf("GenerateTokenPair err: %v", err)
+		}
+		access, refresh := parseToken(t, pair.AccessToken), parseToken(t, pair.RefreshToken)
+		err = svc.Logout(access, &LogoutRequest{RefreshToken: tt.refresh(pair)})
+		if err != tt.wantErr {
+			t.Errorf("%s: Logout err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if revoked, _ := svc.IsTokenRevoked(access.Id); revoked != (err == nil) {
+			t.Errorf("%s: access token revoked = %v, want %v", tt.name, revoked, err == nil)
+		}
+		if revoked, _ := svc.IsTokenRevoked(refresh.Id); revoked != tt.wantRevoked {
+			t.Errorf("%s: refresh token revoked = %v, want %v", tt.name, revoked, tt.wantRevoked)
+		}
+		if _, err = svc.RefreshToken(&RefreshTokenRequest{RefreshToken: pair.RefreshToken}); (err == ErrInvalidToken) != tt.wantRevoked {
+			t.Errorf("%s: RefreshToken after Logout err = %v", tt.name, err)
+		}
+	}
+}
+
+// 已过期的吊销记录在下一次吊销时清理
+func TestRevokeTokenCleanup(t *testing.T) {
+	svc := newAuthTestService(t)
+	expired := &app.Claims{}
+	expired.Id, expired.ExpiresAt = "expired", time.Now().Add(-time.Minute).Unix()
+	current := &app.Claims{}
+	current.Id, current.ExpiresAt = "current", time.Now().Add(time.Hour).Unix()
+
+	if err := svc.dao.CreateTokenRevocation(expired.Id, uint32(expired.ExpiresAt)); err != nil {
+		t.Fatalf("CreateTokenRevocation err: %v", err)
+	}
+	if err := svc.RevokeToken(current); err != nil {
+		t.Fatalf("RevokeToken err: %v", err)
+	}
+	if revoked, _ := svc.IsTokenRevoked(expired.Id); revoked {
+		t.Errorf("expired revocation is not cleaned up")
+	}
+	if revoked, _ := svc.IsTokenRevoked(current.Id); !revoked {
+		t.Errorf("current token is not revoked")
+	}
+}
+
+// 迁移中的 admin 的 app_secret 为明文，第一次认证成功后重新哈希保存
+func TestCheckAuthRehash(t *testing.T) {
+	svc := newTestService(t)
+	auth, _ := svc.dao.GetAuth("admin")
+	if isSecretHash(auth.AppSecret) {
+		t.Fatalf("app_secret of admin is already hashed")
+	}
+	if _, err := svc.CheckAuth(&AuthRequest{AppKey: "admin", AppSecret: "wrong"}); err != ErrInvalidCredentials {
+		t.Errorf("CheckAuth with wrong secret err = %v, want ErrInvalidCredentials", err)
+	}
+	if auth, _ = svc.dao.GetAuth("admin"); isSecretHash(auth.AppSecret) {
+		t.Errorf("app_secret is rehashed after a failed check")
+	}
+
+	for i := 0; i < 2; i++ {
+		principal, err := svc.CheckAuth(&AuthRequest{AppKey: "admin", AppSecret: "go-learning"})
+		if err != nil {
+			t.Fatalf("CheckAuth #%d err: %v", i, err)
+		}
+		if principal.Name != "admin" || principal.Role != model.ROLE_APP || !model.HasScope(principal.Scopes, model.PERM_USER_MANAGE) {
+			t.Errorf("CheckAuth #%d = %+v", i, principal)
+		}
+		if auth, _ = svc.dao.GetAuth("admin"); !isSecretHash(auth.AppSecret) {
+			t.Errorf("CheckAuth #%d: app_secret = %q, want bcrypt hash", i, auth.AppSecret)
+		}
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	svc := newTestService(t)
+	credential, err := svc.CreateAppKey(&CreateAppKeyRequest{AppKey: "reader", Scopes: []string{model.PERM_TAG_READ}, Operator: adminUser})
+	if err != nil {
+		t.Fatalf("CreateAppKey err: %v", err)
+	}
+	disabled, err := svc.CreateAppKey(&CreateAppKeyRequest{AppKey: "disabled", Scopes: []string{model.PERM_TAG_READ}, Operator: adminUser})
+	if err != nil {
+		t.Fatalf("CreateAppKey err: %v", err)
+	}
+	closed := uint8(model.STATE_CLOSE)
+	if err = svc.UpdateAppKey(&UpdateAppKeyRequest{ID: disabled.ID, State: &closed, Operator: adminUser}); err != nil {
+		t.Fatalf("UpdateAppKey err: %v", err)
+	}
+	rotated, err := svc.CreateAppKey(&CreateAppKeyRequest{AppKey: "rotated", Scopes: []string{model.PERM_TAG_READ}, Operator: adminUser})
+	if err != nil {
+		t.Fatalf("CreateAppKey err: %v", err)
+	}
+	if _, err = svc.RotateAppKey(&RotateAppKeyRequest{ID: rotated.ID, Operator: adminUser}); err != nil {
+		t.Fatalf("RotateAppKey err: %v", err)
+	}
+	reader, _ := svc.dao.GetAuth("reader")
+
+	tests := []struct {
+		name    string
+		appKey  string
+		secret  string
+		wantErr error
+	}{
+		{name: "valid", appKey: "reader", secret: credential.AppSecret},
+		{name: "wrong secret", appKey: "reader", secret: "secret", wantErr: ErrInvalidCredentials},
+		// 只保存哈希，使用哈希本身无法认证
+		{name: "hash as secret", appKey: "reader", secret: reader.AppSecret, wantErr: ErrInvalidCredentials},
+		{name: "unknown app_key", appKey: "nobody", secret: credential.AppSecret, wantErr: ErrInvalidCredentials},
+		{name: "disabled", appKey: "disabled", secret: disabled.AppSecret, wantErr: ErrInvalidCredentials},
+		// 轮换后旧的 secret 立即失效
+		{name: "rotated", appKey: "rotated", secret: rotated.AppSecret, wantErr: ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		principal, err := svc.CheckAuth(&AuthRequest{AppKey: tt.appKey, AppSecret: tt.secret})
+		if err != tt.wantErr {
+			t.Errorf("%s: CheckAuth err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (principal.Name != "reader" || len(principal.Scopes) != 1 || principal.Scopes[0] != model.PERM_TAG_READ) {
+			t.Errorf("%s: CheckAuth = %+v", tt.name, principal)
+		}
+	}
+}
+
+// 刷新时重新读取 app_key，权限范围变更立即生效，已禁用的 app_key 无法刷新
+func TestRefreshTokenAppKeyChanged(t *testing.T) {
+	svc := newAuthTestService(t)
+	for _, appKey := range []string{"reader", "disabled"} {
+		if _, err := svc.CreateAppKey(&CreateAppKeyRequest{AppKey: appKey, Scopes: []string{model.PERM_TAG_READ}, Operator: adminUser}); err != nil {
+			t.Fatalf("CreateAppKey err: %v", err)
+		}
+	}
+	closed := uint8(model.STATE_CLOSE)
+	if err := svc.UpdateAppKey(&UpdateAppKeyRequest{ID: 2, Scopes: []string{"tag:read,tag:write"}, Operator: adminUser}); err != nil {
+		t.Fatalf("UpdateAppKey err: %v", err)
+	}
+	if err := svc.UpdateAppKey(&UpdateAppKeyRequest{ID: 3, State: &closed, Operator: adminUser}); err != nil {
+		t.Fatalf("UpdateAppKey err: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		appKey     string
+		wantErr    error
+		wantScopes int
+	}{
+		{name: "scopes changed", appKey: "reader", wantScopes: 2},
+		{name: "disabled", appKey: "disabled", wantErr: ErrInvalidToken},
+		{name: "unknown", appKey: "nobody", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		token, err := app.GenerateToken(app.Principal{Name: tt.appKey, Role: model.ROLE_APP, Scopes: []string{model.PERM_TAG_READ}}, app.TokenTypeRefresh)
+		if err != nil {
+			t.Fatalf("GenerateToken err: %v", err)
+		}
+		principal, err := svc.RefreshToken(&RefreshTokenRequest{RefreshToken: token})
+		if err != tt.wantErr {
+			t.Errorf("%s: RefreshToken err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (principal.Role != model.ROLE_APP || len(principal.Scopes) != tt.wantScopes) {
+			t.Errorf("%s: RefreshToken = %+v", tt.name, principal)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"crypto/sha1"
+	"demo/ch02/global"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/cache"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	tagListCache  = cache.NewLoader("tag_list")
+	tagCountCache = cache.NewLoader("tag_count")
+	articleCache  = cache.NewLoader("article")
+)
+
+// 标签列表的缓存键中包含版本号，任一标签变更后所有筛选条件与分页的结果都可能变化，
+// 此时更换版本号使全部标签列表缓存失效，旧版本的缓存在过期后被清理
+const tagListVersionKey = "tag:list:version"
+
+// 文章的全部状态，文章变更后删除各状态下的缓存
+var articleStates = []uint8{
+	model.ARTICLE_STATE_ARCHIVED,
+	model.ARTICLE_STATE_PUBLISHED,
+	model.ARTICLE_STATE_DRAFT,
+	model.ARTICLE_STATE_IN_REVIEW,
+	model.ARTICLE_STATE_SCHEDULED,
+}
+
+func articleCacheKey(id uint32, state uint8) string {
+	return fmt.Sprintf("article:%d:%d", id, state)
+}
+
+// 使用 loader 读取缓存，未命中时调用 load 加载，未配置缓存时直接加载
+func (svc *Service) loadCache(loader *cache.Loader, key string, v interface{}, load func() (interface{}, error)) error {
+	return loader.Load(svc.ctx, global.Cache, key, global.CacheSetting().TTL, v, load)
+}
+
+// 标签列表的缓存键，由当前版本号与全部查询参数的摘要组成
+func (svc *Service) tagListCacheKey(kind string, params ...interface{}) (string, error) {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(body)
+	return "tag:" + kind + ":" + svc.tagListVersion() + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// 版本号不存在时(首次读取或被淘汰)生成新的版本号
+func (svc *Service) tagListVersion() string {
+	if global.Cache == nil {
+		return ""
+	}
+	version, err := global.Cache.Get(svc.ctx, tagListVersionKey)
+	if err == nil {
+		return string(version)
+	}
+	if err != cache.ErrNotFound {
+		global.Logger.Errorf(svc.ctx, "cache.Get %s err: %v", tagListVersionKey, err)
+		return newCacheVersion()
+	}
+	return svc.renewTagListVersion()
+}
+
+// 更换标签列表的版本号并返回新的版本号
+func (svc *Service) renewTagListVersion() string {
+	version := newCacheVersion()
+	if err := global.Cache.Set(svc.ctx, tagListVersionKey, []byte(version), 0); err != nil {
+		global.Logger.Errorf(svc.ctx, "cache.Set %s err: %v", tagListVersionKey, err)
+	}
+	return version
+}
+
+// 标签变更后使全部标签列表缓存失效，并删除关联了该标签的文章缓存
+func (svc *Service) invalidateTagCache(tagID uint32) error {
+	if global.Cache == nil {
+		return nil
+	}
+	svc.renewTagListVersion()
+	if tagID == 0 {
+		return nil
+	}
+	articleTags, err := svc.dao.GetArticleTagListByTID(tagID)
+	if err != nil {
+		return err
+	}
+	articleIDs := make([]uint32, 0, len(articleTags))
+	for _, articleTag := range articleTags {
+		articleIDs = append(articleIDs, articleTag.ArticleID)
+	}
+	svc.invalidateArticleCache(articleIDs...)
+	return nil
+}
+
+// 文章变更后删除文章在各状态下的缓存，删除失败时缓存在过期前保持旧值
+func (svc *Service) invalidateArticleCache(ids ...uint32) {
+	if global.Cache == nil || len(ids) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(ids)*len(articleStates))
+	for _, id := range ids {
+		for _, state := range articleStates {
+			keys = append(keys, articleCacheKey(id, state))
+		}
+	}
+	if err := global.Cache.Delete(svc.ctx, keys...); err != nil {
+		global.Logger.Errorf(svc.ctx, "cache.Delete %v err: %v", keys, err)
+	}
+}
+
+// 使用纳秒时间戳作为版本号，被淘汰后重新生成的版本号不会与旧版本号重复
+func newCacheVersion() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}
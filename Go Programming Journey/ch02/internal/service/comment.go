@@ -0,0 +1,230 @@
+package service
+
+import (
+	"demo/ch02/global"
+	"demo/ch02/internal/dao"
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/sensitive"
+	"errors"
+	"sync"
+)
+
+var ErrCommentNotFound = errors.New("评论不存在")
+
+type CommentListRequest struct {
+	ArticleID uint32 `form:"id" binding:"required,gte=1"`
+}
+
+type CreateCommentRequest struct {
+	ArticleID uint32 `form:"id" binding:"required,gte=1"`
+	ParentID  uint32 `form:"parent_id" binding:"omitempty,gte=1"` // 回复的评论，未传入时为顶层评论
+	Content   string `form:"content" binding:"required,min=1,max=1000"`
+	CreatedBy string `form:"-"` // 由认证信息填充
+}
+
+type DeleteCommentRequest struct {
+	ID uint32 `form:"id" binding:"required,gte=1"`
+}
+
+type CommentModerationListRequest struct {
+	ArticleID uint32 `form:"article_id" binding:"omitempty,gte=1"` // 未传入时返回全部文章的评论
+	State     uint8  `form:"state,default=0" binding:"oneof=0 1 2"`
+}
+
+type ModerateCommentRequest struct {
+	ID         uint32 `form:"id" binding:"required,gte=1"`
+	State      uint8  `form:"state" binding:"oneof=1 2"`
+	ModifiedBy string `form:"-"` // 由认证信息填充
+}
+
+// 顶层评论的 Replies 为该楼层下按时间顺序排列的全部回复，回复通过 ParentID 指明回复的对象
+type Comment struct {
+	ID        uint32     `json:"id"`
+	ArticleID uint32     `json:"article_id"`
+	RootID    uint32     `json:"root_id"`
+	ParentID  uint32     `json:"parent_id"`
+	Content   string     `json:"content"`
+	State     uint8      `json:"state"`
+	CreatedBy string     `json:"created_by"`
+	CreatedOn uint32     `json:"created_on"`
+	Replies   []*Comment `json:"replies,omitempty"`
+}
+
+var (
+	commentFilterMu sync.RWMutex
+	commentFilter   *sensitive.Filter
+)
+
+// 敏感词过滤器在首次使用时根据配置创建
+func getCommentFilter() *sensitive.Filter {
+	commentFilterMu.RLock()
+	filter := commentFilter
+	commentFilterMu.RUnlock()
+	if filter != nil {
+		return filter
+	}
+	return ReloadCommentFilter()
+}
+
+// 根据当前配置重新创建敏感词过滤器，配置文件变更后调用，使新的 Comment.SensitiveWords 生效
+func ReloadCommentFilter() *sensitive.Filter {
+	filter := sensitive.NewFilter(global.CommentSetting().SensitiveWords)
+	commentFilterMu.Lock()
+	commentFilter = filter
+	commentFilterMu.Unlock()
+	return filter
+}
+
+// 分页获取文章下已审核通过的顶层评论及其回复
+func (svc *Service) GetCommentList(param *CommentListRequest, pager *app.Pager) ([]*Comment, int, error) {
+	if _, err := svc.getCommentableArticle(param.ArticleID); err != nil {
+		return nil, 0, err
+	}
+
+	totalRows, err := svc.dao.CountRootComment(param.ArticleID, model.COMMENT_STATE_APPROVED)
+	if err != nil {
+		return nil, 0, err
+	}
+	roots, err := svc.dao.GetRootCommentList(param.ArticleID, model.COMMENT_STATE_APPROVED, pager.Page, pager.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	comments := make([]*Comment, 0, len(roots))
+	if len(roots) == 0 {
+		return comments, totalRows, nil
+	}
+	rootIDs := make([]uint32, 0, len(roots))
+	commentByID := make(map[uint32]*Comment, len(roots))
+	for _, root := range roots {
+		comment := newComment(root)
+		comments = append(comments, comment)
+		commentByID[root.ID] = comment
+		rootIDs = append(rootIDs, root.ID)
+	}
+
+	replies, err := svc.dao.GetCommentListByRootIDs(rootIDs, model.COMMENT_STATE_APPROVED)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, reply := range replies {
+		if root, ok := commentByID[reply.RootID]; ok {
+			root.Replies = append(root.Replies, newComment(reply))
+		}
+	}
+
+	return comments, totalRows, nil
+}
+
+// 发表评论或回复，内容中的敏感词会被替换为 *，命中敏感词或开启了全部审核时评论进入审核队列
+func (svc *Service) CreateComment(param *CreateCommentRequest) (*Comment, error) {
+	if _, err := svc.getCommentableArticle(param.ArticleID); err != nil {
+		return nil, err
+	}
+
+	var rootID uint32
+	if param.ParentID > 0 {
+		parent, err := svc.getComment(param.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		// 只能回复同一篇文章下已审核通过的评论
+		if parent.ArticleID != param.ArticleID || parent.State != model.COMMENT_STATE_APPROVED {
+			return nil, ErrCommentNotFound
+		}
+		rootID = parent.RootID
+		if rootID == 0 {
+			rootID = parent.ID
+		}
+	}
+
+	content, words := getCommentFilter().Replace(param.Content, '*')
+	var state uint8 = model.COMMENT_STATE_APPROVED
+	if global.CommentSetting().RequireReview || len(words) > 0 {
+		state = model.COMMENT_STATE_PENDING
+	}
+
+	comment, err := svc.dao.CreateComment(&dao.Comment{
+		ArticleID: param.ArticleID,
+		RootID:    rootID,
+		ParentID:  param.ParentID,
+		Content:   content,
+		State:     state,
+		CreatedBy: param.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newComment(comment), nil
+}
+
+// 删除评论，删除顶层评论时该楼层下的回复一并删除
+func (svc *Service) DeleteComment(param *DeleteCommentRequest) error {
+	if _, err := svc.getComment(param.ID); err != nil {
+		return err
+	}
+	return svc.dao.DeleteComment(param.ID)
+}
+
+// 按发表时间顺序获取审核队列中的评论，默认返回待审核的评论
+func (svc *Service) GetCommentModerationList(param *CommentModerationListRequest, pager *app.Pager) ([]*Comment, int, error) {
+	totalRows, err := svc.dao.CountCommentByState(param.ArticleID, param.State)
+	if err != nil {
+		return nil, 0, err
+	}
+	comments, err := svc.dao.GetCommentListByState(param.ArticleID, param.State, pager.Page, pager.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*Comment, 0, len(comments))
+	for _, comment := range comments {
+		result = append(result, newComment(comment))
+	}
+	return result, totalRows, nil
+}
+
+// 审核评论，通过或拒绝
+func (svc *Service) ModerateComment(param *ModerateCommentRequest) error {
+	if _, err := svc.getComment(param.ID); err != nil {
+		return err
+	}
+	return svc.dao.UpdateCommentState(param.ID, param.State, param.ModifiedBy)
+}
+
+// 获取可以评论的文章，仅已发布的文章允许查看与发表评论
+func (svc *Service) getCommentableArticle(id uint32) (*model.Article, error) {
+	article, err := svc.getArticle(id)
+	if err != nil {
+		return nil, err
+	}
+	if article.State != model.ARTICLE_STATE_PUBLISHED {
+		return nil, ErrArticleNotFound
+	}
+	return article, nil
+}
+
+func (svc *Service) getComment(id uint32) (*model.Comment, error) {
+	comment, err := svc.dao.GetComment(id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Model == nil || comment.ID == 0 {
+		return nil, ErrCommentNotFound
+	}
+	return &comment, nil
+}
+
+func newComment(comment *model.Comment) *Comment {
+	return &Comment{
+		ID:        comment.ID,
+		ArticleID: comment.ArticleID,
+		RootID:    comment.RootID,
+		ParentID:  comment.ParentID,
+		Content:   comment.Content,
+		State:     comment.State,
+		CreatedBy: comment.CreatedBy,
+		CreatedOn: comment.CreatedOn,
+	}
+}
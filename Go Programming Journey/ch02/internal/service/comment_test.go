@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"demo/ch02/global"
+	"demo/ch02/pkg/setting"
+)
+
+// 敏感词配置变更后重新创建过滤器，新的敏感词立即生效
+func TestReloadCommentFilter(t *testing.T) {
+	oldSettings := global.LoadSettings()
+	defer func() {
+		global.StoreSettings(oldSettings)
+		commentFilter = nil
+	}()
+
+	global.UpdateSettings(func(s *global.Settings) {
+		s.Comment = &setting.CommentSettingS{SensitiveWords: []string{"foo"}}
+	})
+	ReloadCommentFilter()
+	if words := getCommentFilter().Find("foo bar"); len(words) != 1 || words[0] != "foo" {
+		t.Fatalf("Find = %q, want [foo]", words)
+	}
+
+	global.UpdateSettings(func(s *global.Settings) {
+		s.Comment = &setting.CommentSettingS{SensitiveWords: []string{"bar"}}
+	})
+	ReloadCommentFilter()
+	if words := getCommentFilter().Find("foo bar"); len(words) != 1 || words[0] != "bar" {
+		t.Fatalf("Find after reload = %q, want [bar]", words)
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"context"
 	"demo/ch02/global"
 	"demo/ch02/internal/dao"
+	"demo/ch02/pkg/app"
+	"errors"
 	otgorm "github.com/eddycjy/opentracing-gorm"
 )
 
+// If-Match 与资源当前的 ETag 不一致，资源在客户端读取后已被修改
+var ErrPreconditionFailed = errors.New("资源已被修改")
+
 type Service struct {
 	ctx context.Context
 	dao *dao.Dao
@@ -19,3 +24,24 @@ func New(ctx context.Context) Service {
 	svc.dao = dao.New(otgorm.WithContext(svc.ctx, global.DBEngine))
 	return svc
 }
+
+// 在事务中执行 fn，fn 中需使用 tx 访问数据库
+// SQLite 只有一个连接，事务中使用 svc 访问数据库会一直等待事务结束
+func (svc *Service) transaction(fn func(tx *Service) error) error {
+	return svc.dao.Transaction(func(dao *dao.Dao) error {
+		return fn(&Service{ctx: svc.ctx, dao: dao})
+	})
+}
+
+// 校验请求的 If-Match，current 须与 GET 接口返回的数据一致
+// 校验之后需使用条件更新，同时到达的两个更新请求都通过校验时只有一个会更新成功
+func checkIfMatch(ifMatch string, current interface{}) error {
+	ok, err := app.IfMatch(ifMatch, current)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
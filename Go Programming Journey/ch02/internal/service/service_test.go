@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"demo/ch02/global"
+	"demo/ch02/internal/model"
+	"demo/ch02/migrations"
+	"demo/ch02/pkg/cache"
+	"demo/ch02/pkg/migrate"
+	"demo/ch02/pkg/setting"
+)
+
+// 使用执行了全部迁移的 SQLite 数据库与进程内缓存创建 Service，测试结束后恢复全局变量
+func newTestService(t *testing.T) Service {
+	oldSettings, oldDB, oldCache := global.LoadSettings(), global.DBEngine, global.Cache
+	t.Cleanup(func() {
+		global.StoreSettings(oldSettings)
+		global.DBEngine, global.Cache = oldDB, oldCache
+	})
+
+	global.UpdateSettings(func(s *global.Settings) {
+		s.Server = &setting.ServerSettingS{}
+		s.Cache = &setting.CacheSettingS{TTL: time.Minute}
+	})
+	db, err := model.NewDBEngine(&setting.DatabaseSettingS{
+		DBType: model.DBTypeSQLite,
+		DBName: filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("NewDBEngine err: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := migrate.NewMigrator(db.DB(), model.DBTypeSQLite, migrations.FS)
+	if err != nil {
+		t.Fatalf("NewMigrator err: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("Up err: %v", err)
+	}
+
+	global.DBEngine = db
+	global.Cache = cache.NewLRU(100)
+	return New(context.Background())
+}
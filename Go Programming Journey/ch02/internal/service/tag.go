@@ -3,44 +3,114 @@ package service
 import (
 	"demo/ch02/internal/model"
 	"demo/ch02/pkg/app"
+	"errors"
 )
 
+var ErrTagNotFound = errors.New("标签不存在")
+
 // 设置方法的请求结构体和参数校验规则
-type CountTagRequest struct {
-	Name  string `form:"name" binding:"max=100"`
+type TagRequest struct {
+	ID    uint32 `form:"id" binding:"required,gte=1"`
 	State uint8  `form:"state,default=1" binding:"oneof=0 1"`
 }
+type CountTagRequest struct {
+	Name      string `form:"name" binding:"max=100"`
+	CreatedBy string `form:"created_by" binding:"max=100"`
+	State     uint8  `form:"state,default=1" binding:"oneof=0 1"`
+}
 type TagListRequest struct {
-	Name  string `form:"name" binding:"max=100"`
-	State uint8  `form:"state,default=1" binding:"oneof=0 1"`
+	Name      string `form:"name" binding:"max=100"`
+	CreatedBy string `form:"created_by" binding:"max=100"`
+	State     uint8  `form:"state,default=1" binding:"oneof=0 1"`
 }
 type CreateTagRequest struct {
 	Name      string `form:"name" binding:"required,min=3,max=100"`
-	CreatedBy string `form:"created_by" binding:"required,min=3,max=100"`
+	CreatedBy string `form:"-"` // 由认证信息填充
 	State     uint8  `form:"state,default=1" binding:"oneof=0 1"`
 }
 type UpdateTagRequest struct {
 	ID         uint32 `form:"id" binding:"required,gte=1"`
 	Name       string `form:"name" binding:"max=100"`
 	State      uint8  `form:"state" binding:"oneof=0 1"`
-	ModifiedBy string `form:"modified_by" binding:"required,min=3,max=100"`
+	ModifiedBy string `form:"-"` // 由认证信息填充
+	IfMatch    string `form:"-"` // 由 If-Match 请求头填充，为空时不校验
 }
 type DeleteTagRequest struct {
 	ID uint32 `form:"id" binding:"required,gte=1"`
 }
 
-func (svc *Service) CountTag(param *CountTagRequest) (int, error) {
-	return svc.dao.CountTag(param.Name, param.State)
+// 标签不存在时返回 nil
+func (svc *Service) GetTag(param *TagRequest) (*model.Tag, error) {
+	return svc.dao.GetTag(param.ID, param.State)
 }
-func (svc *Service) GetTagList(param *TagListRequest, pager *app.Pager) ([]*model.Tag, error) {
-	return svc.dao.GetTagList(param.Name, param.State, pager.Page, pager.PageSize)
+
+// 标签总数与标签列表按查询参数缓存，任一标签变更后全部失效
+func (svc *Service) CountTag(param *CountTagRequest, spec *app.QuerySpec) (int, error) {
+	key, err := svc.tagListCacheKey("count", param, spec, spec.Keyset())
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = svc.loadCache(tagCountCache, key, &count, func() (interface{}, error) {
+		return svc.dao.CountTag(param.Name, param.CreatedBy, param.State, spec)
+	})
+	return count, err
+}
+func (svc *Service) GetTagList(param *TagListRequest, spec *app.QuerySpec, pager *app.Pager) ([]*model.Tag, error) {
+	key, err := svc.tagListCacheKey("list", param, spec, spec.Keyset(), pager.Page, pager.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	var tags []*model.Tag
+	err = svc.loadCache(tagListCache, key, &tags, func() (interface{}, error) {
+		return svc.dao.GetTagList(param.Name, param.CreatedBy, param.State, spec, pager.Page, pager.PageSize)
+	})
+	return tags, err
 }
 func (svc *Service) CreateTag(param *CreateTagRequest) error {
-	return svc.dao.CreateTag(param.Name, param.State, param.CreatedBy)
+	if err := svc.dao.CreateTag(param.Name, param.State, param.CreatedBy); err != nil {
+		return err
+	}
+	// 新标签尚未关联文章，仅需使标签列表缓存失效
+	return svc.invalidateTagCache(0)
 }
 func (svc *Service) UpdateTag(param *UpdateTagRequest) error {
-	return svc.dao.UpdateTag(param.ID, param.Name, param.State, param.ModifiedBy)
+	if param.IfMatch == "" {
+		if err := svc.dao.UpdateTag(param.ID, param.Name, param.State, param.ModifiedBy); err != nil {
+			return err
+		}
+		return svc.invalidateTagCache(param.ID)
+	}
+
+	err := svc.transaction(func(tx *Service) error {
+		tag, err := tx.dao.GetTagByID(param.ID)
+		if err != nil {
+			return err
+		}
+		if tag == nil {
+			return ErrTagNotFound
+		}
+		if err = checkIfMatch(param.IfMatch, tag); err != nil {
+			return err
+		}
+		// 校验之后标签被其他请求修改时版本号已变化，不覆盖其他请求的修改
+		ok, err := tx.dao.UpdateTagIfUnmodified(param.ID, param.Name, param.State, param.ModifiedBy, tag.Version)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrPreconditionFailed
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return svc.invalidateTagCache(param.ID)
 }
 func (svc *Service) DeleteTag(param *DeleteTagRequest) error {
-	return svc.dao.DeleteTag(param.ID)
+	if err := svc.dao.DeleteTag(param.ID); err != nil {
+		return err
+	}
+	return svc.invalidateTagCache(param.ID)
 }
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+)
+
+func TestUpdateTagIfMatch(t *testing.T) {
+	svc := newTestService(t)
+	if err := svc.dao.CreateTag("name", model.STATE_OPEN, "test"); err != nil {
+		t.Fatalf("CreateTag err: %v", err)
+	}
+	tag, err := svc.dao.GetTagByID(1)
+	if err != nil || tag == nil {
+		t.Fatalf("GetTagByID = %v, %v", tag, err)
+	}
+	etag, _ := app.NewETag(tag)
+
+	tests := []struct {
+		name     string
+		ifMatch  string
+		newName  string
+		wantErr  error
+		wantName string
+	}{
+		{name: "stale", ifMatch: `"stale"`, newName: "stale", wantErr: ErrPreconditionFailed, wantName: "name"},
+		{name: "weak", ifMatch: "W/" + etag, newName: "weak", wantErr: ErrPreconditionFailed, wantName: "name"},
+		{name: "match", ifMatch: etag, newName: "matched", wantName: "matched"},
+		{name: "reused", ifMatch: etag, newName: "reused", wantErr: ErrPreconditionFailed, wantName: "matched"},
+		{name: "none", newName: "none", wantName: "none"},
+	}
+	for _, tt := range tests {
+		err := svc.UpdateTag(&UpdateTagRequest{ID: tag.ID, Name: tt.newName, State: model.STATE_OPEN, IfMatch: tt.ifMatch})
+		if err != tt.wantErr {
+			t.Errorf("%s: UpdateTag err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		got, _ := svc.dao.GetTagByID(tag.ID)
+		if got.Name != tt.wantName {
+			t.Errorf("%s: Name = %q, want %q", tt.name, got.Name, tt.wantName)
+		}
+	}
+
+	if err := svc.UpdateTag(&UpdateTagRequest{ID: 2, Name: "x", IfMatch: "*"}); err != ErrTagNotFound {
+		t.Errorf("UpdateTag missing tag err = %v, want ErrTagNotFound", err)
+	}
+	// 上面已更新过两次，同一秒内的修改也会使版本号变化
+	if ok, err := svc.dao.UpdateTagIfUnmodified(tag.ID, "x", model.STATE_OPEN, "test", tag.Version); ok || err != nil {
+		t.Errorf("UpdateTagIfUnmodified with a stale version = %v, %v, want false", ok, err)
+	}
+	if got, _ := svc.dao.GetTagByID(tag.ID); got.Version != tag.Version+2 {
+		t.Errorf("Version = %d, want %d", got.Version, tag.Version+2)
+	}
+}
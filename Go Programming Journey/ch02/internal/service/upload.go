@@ -2,38 +2,62 @@ package service
 
 import (
 	"demo/ch02/global"
+	"demo/ch02/pkg/storage"
 	"demo/ch02/pkg/upload"
-	"errors"
-	"mime/multipart"
-	"os"
+	"io"
+	"time"
 )
 
 type FileInfo struct {
 	Name      string
 	AccessUrl string
+	ExpiresAt int64 // 私有文件访问地址的过期时间，公开文件为 0
+	Width     int   // 图片的宽高，其他类型的文件为 0
+	Height    int
+	Variants  []*FileVariant
 }
 
-func (svc *Service) UploadFile(fileType upload.FileType, file multipart.File, fileHeader *multipart.FileHeader) (*FileInfo, error) {
-	fileName := upload.GetFileName(fileHeader.Filename)
-	if !upload.CheckContainExt(fileType, fileName) {
-		return nil, errors.New("file suffix is not supported.")
-	}
-	if upload.CheckMaxSize(fileType, file) {
-		return nil, errors.New("exceeded maximum file limit.")
+// 图片的缩略图与 WebP 版本
+type FileVariant struct {
+	Name      string `json:"name"` // e.g. webp、thumb_200、thumb_200_webp
+	Key       string `json:"file_key"`
+	AccessUrl string `json:"file_access_url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+}
+
+// name 为客户端上传的文件名，仅用于校验后缀，保存的文件名由文件内容决定
+// 私有文件返回有效期为 Storage.SignedURLExpire 的签名地址
+func (svc *Service) UploadFile(fileType upload.FileType, file io.Reader, name string, private bool) (*FileInfo, error) {
+	if !upload.CheckContainExt(fileType, name) {
+		return nil, upload.ErrUnsupportedExt
 	}
-	uploadSavePath := upload.GetSavePath()
-	if upload.CheckSavePath(uploadSavePath) {
-		if err := upload.CreateSavePath(uploadSavePath, os.ModePerm); err != nil {
-			return nil, errors.New("failed to create save directory.")
-		}
+	saved, err := upload.SaveFile(svc.ctx, global.Storage, fileType, name, file, private)
+	if err != nil {
+		return nil, err
 	}
-	if upload.CheckPermission(uploadSavePath) {
-		return nil, errors.New("insufficient file permissions.")
+	fileInfo := &FileInfo{Name: saved.Key, Width: saved.Width, Height: saved.Height}
+	var expire time.Duration
+	if storage.IsPrivate(saved.Key) {
+		expire = global.StorageSetting().SignedURLExpire
+		fileInfo.ExpiresAt = time.Now().Add(expire).Unix()
 	}
-	dst := uploadSavePath + "/" + fileName
-	if err := upload.SaveFile(fileHeader, dst); err != nil {
+	fileInfo.AccessUrl, err = global.Storage.URL(svc.ctx, saved.Key, expire)
+	if err != nil {
 		return nil, err
 	}
-	accessUrl := global.AppSetting.UploadServerUrl + "/" + fileName
-	return &FileInfo{Name: fileName, AccessUrl: accessUrl}, nil
+	for _, variant := range saved.Variants {
+		accessUrl, err := global.Storage.URL(svc.ctx, variant.Key, expire)
+		if err != nil {
+			return nil, err
+		}
+		fileInfo.Variants = append(fileInfo.Variants, &FileVariant{
+			Name:      variant.Name,
+			Key:       variant.Key,
+			AccessUrl: accessUrl,
+			Width:     variant.Width,
+			Height:    variant.Height,
+		})
+	}
+	return fileInfo, nil
 }
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"demo/ch02/internal/model"
+	"demo/ch02/pkg/app"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUserNotFound = errors.New("用户不存在")
+	ErrUserExists   = errors.New("用户名已存在")
+	ErrUserSelf     = errors.New("不能修改自己的角色、状态或删除自己")
+)
+
+type UserListRequest struct {
+	Role string `form:"role" binding:"omitempty,oneof=admin editor viewer"`
+}
+
+type CreateUserRequest struct {
+	Username  string `form:"username" binding:"required,min=2,max=100"`
+	Password  string `form:"password" binding:"required,min=8,max=72"` // bcrypt 最多只使用前 72 个字节
+	Role      string `form:"role,default=viewer" binding:"oneof=admin editor viewer"`
+	CreatedBy string `form:"-"` // 由认证信息填充
+}
+
+type UpdateUserRequest struct {
+	ID         uint32 `form:"id" binding:"required,gte=1"`
+	Password   string `form:"password" binding:"omitempty,min=8,max=72"` // 未传入时保持不变
+	Role       string `form:"role" binding:"omitempty,oneof=admin editor viewer"`
+	State      *uint8 `form:"state" binding:"omitempty,oneof=0 1"`
+	ModifiedBy string `form:"-"` // 由认证信息填充
+	OperatorID uint32 `form:"-"` // 当前操作的用户，通过 app_key 认证时为 0
+}
+
+type DeleteUserRequest struct {
+	ID         uint32 `form:"id" binding:"required,gte=1"`
+	OperatorID uint32 `form:"-"`
+}
+
+func (svc *Service) GetUserList(param *UserListRequest, pager *app.Pager) ([]*model.User, int, error) {
+	totalRows, err := svc.dao.CountUser(param.Role)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err := svc.dao.GetUserList(param.Role, pager.Page, pager.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, totalRows, nil
+}
+
+func (svc *Service) CreateUser(param *CreateUserRequest) (*model.User, error) {
+	existed, err := svc.dao.GetUserByUsername(param.Username)
+	if err != nil {
+		return nil, err
+	}
+	if existed.Model != nil && existed.ID > 0 {
+		return nil, ErrUserExists
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(param.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return svc.dao.CreateUser(param.Username, string(hash), param.Role, param.CreatedBy)
+}
+
+func (svc *Service) UpdateUser(param *UpdateUserRequest) error {
+	if _, err := svc.getUser(param.ID); err != nil {
+		return err
+	}
+	// 避免管理员误操作导致失去管理权限
+	if param.ID == param.OperatorID && (param.Role != "" || param.State != nil) {
+		return ErrUserSelf
+	}
+
+	values := map[string]interface{}{
+		"modified_by": param.ModifiedBy,
+	}
+	if param.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(param.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		values["password"] = string(hash)
+	}
+	if param.Role != "" {
+		values["role"] = param.Role
+	}
+	if param.State != nil {
+		values["state"] = *param.State
+	}
+	return svc.dao.UpdateUser(param.ID, values)
+}
+
+func (svc *Service) DeleteUser(param *DeleteUserRequest) error {
+	if _, err := svc.getUser(param.ID); err != nil {
+		return err
+	}
+	if param.ID == param.OperatorID {
+		return ErrUserSelf
+	}
+	return svc.dao.DeleteUser(param.ID)
+}
+
+func (svc *Service) getUser(id uint32) (*model.User, error) {
+	user, err := svc.dao.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Model == nil || user.ID == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+
+	"demo/ch02/internal/model"
+)
+
+func TestCreateUser(t *testing.T) {
+	svc := newTestService(t)
+	user, err := svc.CreateUser(&CreateUserRequest{Username: "editor", Password: "password", Role: model.ROLE_EDITOR})
+	if err != nil {
+		t.Fatalf("CreateUser err: %v", err)
+	}
+	// 只保存密码的哈希
+	if user.Password == "password" || user.Role != model.ROLE_EDITOR || user.State != model.STATE_OPEN {
+		t.Errorf("CreateUser = %+v", user)
+	}
+	if _, err = svc.CreateUser(&CreateUserRequest{Username: "editor", Password: "password", Role: model.ROLE_VIEWER}); err != ErrUserExists {
+		t.Errorf("CreateUser err = %v, want ErrUserExists", err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	svc := newTestService(t)
+	for _, username := range []string{"editor", "disabled"} {
+		if _, err := svc.CreateUser(&CreateUserRequest{Username: username, Password: "password", Role: model.ROLE_EDITOR}); err != nil {
+			t.Fatalf("CreateUser err: %v", err)
+		}
+	}
+	closed := uint8(model.STATE_CLOSE)
+	if err := svc.UpdateUser(&UpdateUserRequest{ID: 2, State: &closed}); err != nil {
+		t.Fatalf("UpdateUser err: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "valid", username: "editor", password: "password"},
+		{name: "wrong password", username: "editor", password: "Password", wantErr: ErrInvalidCredentials},
+		{name: "unknown user", username: "nobody", password: "password", wantErr: ErrInvalidCredentials},
+		{name: "disabled user", username: "disabled", password: "password", wantErr: ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		principal, err := svc.Login(&LoginRequest{Username: tt.username, Password: tt.password})
+		if err != tt.wantErr {
+			t.Errorf("%s: Login err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (principal.UserID != 1 || principal.Name != tt.username || principal.Role != model.ROLE_EDITOR) {
+			t.Errorf("%s: Login = %+v", tt.name, principal)
+		}
+	}
+}
+
+func TestUpdateUserSelf(t *testing.T) {
+	svc := newTestService(t)
+	if _, err := svc.CreateUser(&CreateUserRequest{Username: "root", Password: "password", Role: model.ROLE_ADMIN}); err != nil {
+		t.Fatalf("CreateUser err: %v", err)
+	}
+	closed := uint8(model.STATE_CLOSE)
+	tests := []struct {
+		name    string
+		param   *UpdateUserRequest
+		wantErr error
+	}{
+		{name: "own role", param: &UpdateUserRequest{ID: 1, Role: model.ROLE_VIEWER, OperatorID: 1}, wantErr: ErrUserSelf},
+		{name: "own state", param: &UpdateUserRequest{ID: 1, State: &closed, OperatorID: 1}, wantErr: ErrUserSelf},
+		// 可以修改自己的密码
+		{name: "own password", param: &UpdateUserRequest{ID: 1, Password: "new-password", OperatorID: 1}},
+		{name: "missing", param: &UpdateUserRequest{ID: 2, Role: model.ROLE_VIEWER}, wantErr: ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		if err := svc.UpdateUser(tt.param); err != tt.wantErr {
+			t.Errorf("%s: UpdateUser err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if err := svc.DeleteUser(&DeleteUserRequest{ID: 1, OperatorID: 1}); err != ErrUserSelf {
+		t.Errorf("DeleteUser err = %v, want ErrUserSelf", err)
+	}
+
+	user, _ := svc.getUser(1)
+	if user.Role != model.ROLE_ADMIN || user.State != model.STATE_OPEN {
+		t.Errorf("user = %+v, want unchanged role and state", user)
+	}
+	if _, err := svc.Login(&LoginRequest{Username: "root", Password: "new-password"}); err != nil {
+		t.Errorf("Login with new password err: %v", err)
+	}
+}
@@ -5,12 +5,20 @@ import (
 	"demo/ch02/global"
 	"demo/ch02/internal/model"
 	"demo/ch02/internal/routers"
+	"demo/ch02/internal/service"
+	"demo/ch02/migrations"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/cache"
 	"demo/ch02/pkg/logger"
+	"demo/ch02/pkg/migrate"
 	"demo/ch02/pkg/setting"
+	"demo/ch02/pkg/storage"
 	"demo/ch02/pkg/tracer"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"log"
 	"net/http"
@@ -29,8 +37,16 @@ var (
 	buildTime    string
 	buildVersion string
 	gitCommitID  string
+	migrateMode  string
+	createAdmin  string
 )
 
+// 创建初始管理员时从环境变量读取密码，避免密码出现在命令行参数与 shell 历史中
+const adminPasswordEnv = "BLOG_ADMIN_PASSWORD"
+
+// 设置时覆盖配置文件中的 Storage.SignSecret，签名密钥不必写入配置文件
+const storageSignSecretEnv = "BLOG_STORAGE_SIGN_SECRET"
+
 // Go 中的执行顺序: 全局变量初始化 =>init() => main()
 // 在 main() 之前自动执行，进行初始化操作
 func init() {
@@ -41,6 +57,31 @@ func init() {
 	if err != nil {
 		log.Fatalf("init.setupSetting err: %v", err)
 	}
+	// Redis 初始化
+	err = setupRedis()
+	if err != nil {
+		log.Fatalf("init.setupRedis err: %v", err)
+	}
+	// 缓存初始化
+	err = setupCache()
+	if err != nil {
+		log.Fatalf("init.setupCache err: %v", err)
+	}
+	// 上传文件存储初始化
+	err = setupStorage()
+	if err != nil {
+		log.Fatalf("init.setupStorage err: %v", err)
+	}
+	// 限流规则初始化
+	err = routers.SetupRateLimit()
+	if err != nil {
+		log.Fatalf("init.setupRateLimit err: %v", err)
+	}
+	// JWT 签名密钥初始化
+	err = app.SetupJWTKeys(global.JWTSetting())
+	if err != nil {
+		log.Fatalf("init.setupJWTKeys err: %v", err)
+	}
 	// 数据库初始化
 	err = setupDBEngine()
 	if err != nil {
@@ -71,24 +112,62 @@ func main() {
 		return
 	}
 
+	// 数据库迁移，up、down、status 执行完成后直接退出，auto 执行完成后继续启动服务
+	switch migrateMode {
+	case "up", "down", "status":
+		if err := runMigrate(migrateMode); err != nil {
+			log.Fatalf("runMigrate err: %v", err)
+		}
+		return
+	case "auto":
+		if err := runMigrate("up"); err != nil {
+			log.Fatalf("runMigrate err: %v", err)
+		}
+	case "":
+		if global.DatabaseSetting().AutoMigrate {
+			if err := runMigrate("up"); err != nil {
+				log.Fatalf("runMigrate err: %v", err)
+			}
+		}
+	default:
+		log.Fatalf("unknown migrate mode: %s", migrateMode)
+	}
+
+	// 创建管理员后直接退出，需要先执行数据库迁移
+	if createAdmin != "" {
+		if err := runCreateAdmin(createAdmin, os.Getenv(adminPasswordEnv)); err != nil {
+			log.Fatalf("runCreateAdmin err: %v", err)
+		}
+		return
+	}
+
 	// 使用映射好的配置设置 gin 的运行模式: debug
-	gin.SetMode(global.ServerSetting.RunMode)
+	serverSetting := global.ServerSetting()
+	gin.SetMode(serverSetting.RunMode)
 	// 不再使用默认路由而使用项目下自定义的路由
 	// router := gin.Default()
 	router := routers.NewRouter()
+	// 未配置时不信任任何代理，客户端 IP 取自连接的远端地址
+	if err := router.SetTrustedProxies(serverSetting.TrustedProxies); err != nil {
+		log.Fatalf("router.SetTrustedProxies err: %v", err)
+	}
 	// 自定义 http.Server
 	s := &http.Server{
-		Addr:           ":" + global.ServerSetting.HttpPort, // 设置监听端口
-		Handler:        router,                              // 设置处理程序
-		ReadTimeout:    global.ServerSetting.ReadTimeout,    // 允许读取最大时间
-		WriteTimeout:   global.ServerSetting.WriteTimeout,   // 允许写入最大时间
-		MaxHeaderBytes: 1 << 20,                             // 请求头最大字节数
+		Addr:           ":" + serverSetting.HttpPort, // 设置监听端口
+		Handler:        router,                       // 设置处理程序
+		ReadTimeout:    serverSetting.ReadTimeout,    // 允许读取最大时间
+		WriteTimeout:   serverSetting.WriteTimeout,   // 允许写入最大时间
+		MaxHeaderBytes: 1 << 20,                      // 请求头最大字节数
 	}
 	//// 调用 ListenAndServe() 监听
 	//if err := s.ListenAndServe(); err != nil {
 	//	log.Fatalf("监听失败：%v", err)
 	//}
 	// 从此处开始修改 使项目支持优雅重启和停止
+	// 启动定时发布任务
+	scheduler := service.NewArticleScheduler(global.AppSetting().ArticlePublishInterval)
+	scheduler.Start()
+
 	go func() {
 		err := s.ListenAndServe()
 		if err != nil && err != http.ErrServerClosed {
@@ -97,7 +176,7 @@ func main() {
 	}()
 
 	// 等待中断信号
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	// 接受 syscall.SIGINT 和 syscall.SIGTERM 信号 两个都是终止信号
 	/*
 		signal.Notify()
@@ -121,7 +200,11 @@ func main() {
 		否则返回关闭服务器的底层侦听器返回的任何错误。
 	*/
 	if err := s.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: ", err)
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+	// 等待正在执行的定时发布任务完成，与 Shutdown 共用超时时间
+	if err := scheduler.Stop(ctx); err != nil {
+		log.Fatalf("Scheduler forced to stop: %v", err)
 	}
 
 	log.Println("Server exiting")
@@ -134,58 +217,267 @@ func setupSetting() error {
 	if err != nil {
 		return err
 	}
-	err = settings.ReadSection("Server", &global.ServerSetting)
+	sections, err := readSetting(settings)
 	if err != nil {
 		return err
 	}
-	err = settings.ReadSection("App", &global.AppSetting)
-	if err != nil {
-		return err
+	sections.publish()
+
+	// 配置文件变更后重新读取配置，转换单位后再替换正在使用的配置，并使新的限流规则与敏感词生效
+	// 读取失败时(e.g. 配置文件写入到一半)保持原有的配置不变
+	settings.OnReload(func() {
+		sections, err := readSetting(settings)
+		if err != nil {
+			log.Printf("readSetting err: %v", err)
+			return
+		}
+		sections.publish()
+		service.ReloadCommentFilter()
+		if err := routers.SetupRateLimit(); err != nil {
+			log.Printf("routers.SetupRateLimit err: %v", err)
+		}
+	})
+	return nil
+}
+
+// 全部配置节，每次读取都创建新的结构体，转换单位后才替换 global 中的配置
+// 请求中读取到的配置要么全部为旧值，要么全部为转换单位后的新值
+type settingSections struct {
+	Server    *setting.ServerSettingS
+	App       *setting.AppSettingS
+	Database  *setting.DatabaseSettingS
+	JWT       *setting.JWTSettingS
+	Email     *setting.EmailSettingS
+	Comment   *setting.CommentSettingS
+	RateLimit *setting.RateLimitSettingS
+	Redis     *setting.RedisSettingS
+	Cache     *setting.CacheSettingS
+	Storage   *setting.StorageSettingS
+}
+
+// 读取全部配置节并转换单位，任一配置节读取失败时返回错误
+func readSetting(settings *setting.Setting) (*settingSections, error) {
+	s := &settingSections{}
+	for k, v := range map[string]interface{}{
+		"Server":    &s.Server,
+		"App":       &s.App,
+		"Database":  &s.Database,
+		"JWT":       &s.JWT,
+		"Email":     &s.Email,
+		"Comment":   &s.Comment,
+		"RateLimit": &s.RateLimit,
+		"Redis":     &s.Redis,
+		"Cache":     &s.Cache,
+		"Storage":   &s.Storage,
+	} {
+		if err := settings.ReadSection(k, v); err != nil {
+			return nil, err
+		}
 	}
-	err = settings.ReadSection("Database", &global.DatabaseSetting)
-	if err != nil {
-		return err
+	s.normalize()
+	return s, nil
+}
+
+// 将配置中以秒为单位的时间转换为 time.Duration，并使用命令行参数与环境变量覆盖配置
+func (s *settingSections) normalize() {
+	s.App.DefaultContextTimeout *= time.Second
+	s.App.ArticlePublishInterval *= time.Second
+	if s.App.ArticlePublishInterval <= 0 {
+		s.App.ArticlePublishInterval = 30 * time.Second
 	}
-	err = settings.ReadSection("JWT", &global.JWTSetting)
-	if err != nil {
-		return err
+	s.JWT.Expire *= time.Second
+	s.JWT.RefreshExpire *= time.Second
+	if s.JWT.RefreshExpire <= 0 {
+		s.JWT.RefreshExpire = 7 * 24 * time.Hour
 	}
-	err = settings.ReadSection("Email", &global.EmailSetting)
-	if err != nil {
-		return err
+	s.Redis.DialTimeout *= time.Second
+	s.Cache.TTL *= time.Second
+	if s.Cache.TTL <= 0 {
+		s.Cache.TTL = time.Minute
+	}
+	s.Storage.SignedURLExpire *= time.Second
+	if s.Storage.SignedURLExpire <= 0 {
+		s.Storage.SignedURLExpire = 15 * time.Minute
+	}
+	for i := range s.RateLimit.Rules {
+		s.RateLimit.Rules[i].FillInterval *= time.Second
 	}
-
-	global.AppSetting.DefaultContextTimeout *= time.Second
-	global.JWTSetting.Expire *= time.Second
 	// global.ServerSetting.ReadTimeout *=1000，将秒转换成毫秒
-	global.ServerSetting.ReadTimeout *= time.Second
-	global.ServerSetting.WriteTimeout *= time.Second
+	s.Server.ReadTimeout *= time.Second
+	s.Server.WriteTimeout *= time.Second
 
 	// 如果存在则覆盖原有的文件配置
 	if port != "" {
-		global.ServerSetting.HttpPort = port
+		s.Server.HttpPort = port
 	}
 	if runMode != "" {
-		global.ServerSetting.RunMode = runMode
+		s.Server.RunMode = runMode
+	}
+	if secret := os.Getenv(storageSignSecretEnv); secret != "" {
+		s.Storage.SignSecret = secret
+	}
+}
+
+// 替换 global 中的配置，已转换单位的配置在替换后不再修改
+func (s *settingSections) publish() {
+	global.StoreSettings(global.Settings(*s))
+}
+
+// Redis 不可用时服务仍可启动，依赖 Redis 的功能在 Redis 恢复前使用本地的实现
+func setupRedis() error {
+	s := global.RedisSetting()
+	if s.Addr == "" {
+		return nil
+	}
+	global.Redis = redis.NewClient(&redis.Options{
+		Addr:        s.Addr,
+		Password:    s.Password,
+		DB:          s.DB,
+		PoolSize:    s.PoolSize,
+		DialTimeout: s.DialTimeout,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := global.Redis.Ping(ctx).Err(); err != nil {
+		log.Printf("setupRedis: redis %s is unreachable: %v", s.Addr, err)
 	}
 	return nil
 }
 
+func setupCache() error {
+	s := global.CacheSetting()
+	switch s.Backend {
+	case "":
+	case "memory":
+		global.Cache = cache.NewLRU(s.Size)
+	case "redis":
+		if global.Redis == nil {
+			return errors.New("cache backend redis: Redis.Addr is not configured")
+		}
+		global.Cache = cache.NewRedis(global.Redis, s.KeyPrefix)
+	default:
+		return fmt.Errorf("cache backend %q: unknown backend", s.Backend)
+	}
+	return nil
+}
+
+// local 与 memory 的文件通过本服务的 /static 接口下载，地址前缀为 App.UploadServerUrl
+func setupStorage() error {
+	s, appSetting := global.StorageSetting(), global.AppSetting()
+	if (s.Backend == "" || s.Backend == "local" || s.Backend == "memory") && s.SignSecret == "" {
+		return fmt.Errorf("storage backend %q: SignSecret is not configured, set Storage.SignSecret or %s", s.Backend, storageSignSecretEnv)
+	}
+	var err error
+	switch s.Backend {
+	case "", "local":
+		global.Storage, err = storage.NewLocal(appSetting.UploadSavePath, appSetting.UploadServerUrl, s.SignSecret)
+	case "memory":
+		global.Storage = storage.NewMemory(appSetting.UploadServerUrl, s.SignSecret)
+	case "s3":
+		global.Storage, err = storage.NewS3(storage.S3Config{
+			Endpoint:        s.S3.Endpoint,
+			Region:          s.S3.Region,
+			Bucket:          s.S3.Bucket,
+			AccessKeyID:     s.S3.AccessKeyID,
+			SecretAccessKey: s.S3.SecretAccessKey,
+			PathStyle:       s.S3.PathStyle,
+			PublicURL:       s.S3.PublicURL,
+		})
+	default:
+		return fmt.Errorf("storage backend %q: unknown backend", s.Backend)
+	}
+	return err
+}
+
 func setupDBEngine() error {
 	var err error
 	// 初始化数据库连接信息，注意此处不是 := 而是 =，使用前者会导致在其他包中调用该变量时值为 nil
-	global.DBEngine, err = model.NewDBEngine(global.DatabaseSetting)
+	global.DBEngine, err = model.NewDBEngine(global.DatabaseSetting())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// 使用内嵌的迁移文件执行数据库迁移，迁移文件目录由 DatabaseSetting.DBType 决定
+func runMigrate(mode string) error {
+	migrator, err := migrate.NewMigrator(global.DBEngine.DB(), global.DatabaseSetting().DBType, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case "up":
+		done, err := migrator.Up()
+		for _, m := range done {
+			log.Printf("migrate up: %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			log.Println("migrate up: no change")
+		}
+	case "down":
+		m, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			log.Println("migrate down: no change")
+			return nil
+		}
+		log.Printf("migrate down: %d_%s", m.Version, m.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied at " + time.Unix(status.AppliedOn, 0).Format("2006-01-02 15:04:05")
+			}
+			if status.Dirty {
+				state += " (checksum mismatch)"
+			}
+			if status.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%06d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate mode: %s", mode)
+	}
+	return nil
+}
+
+// 创建管理员用户，用户名已存在时返回错误，不会修改已有用户的密码
+func runCreateAdmin(username, password string) error {
+	// 与创建用户接口的校验规则一致，bcrypt 最多只使用前 72 个字节
+	if len(password) < 8 || len(password) > 72 {
+		return fmt.Errorf("%s must be 8 to 72 bytes", adminPasswordEnv)
+	}
+	svc := service.New(context.Background())
+	user, err := svc.CreateUser(&service.CreateUserRequest{
+		Username:  username,
+		Password:  password,
+		Role:      model.ROLE_ADMIN,
+		CreatedBy: "system",
+	})
 	if err != nil {
 		return err
 	}
+	log.Printf("create admin: %s (id %d)", user.Username, user.ID)
 	return nil
 }
 
 func setupLogger() error {
+	s := global.AppSetting()
 	// 使用了 lumberjack 作为日志库的 io.Writer
 	global.Logger = logger.NewLogger(&lumberjack.Logger{
 		// 设置生成日志文件存储相对位置与文件名
-		Filename:  global.AppSetting.LogSavePath + "/" + global.AppSetting.LogFileName + global.AppSetting.LogFileExt,
+		Filename:  s.LogSavePath + "/" + s.LogFileName + s.LogFileExt,
 		MaxSize:   600,  // 设置最大占用空间
 		MaxAge:    10,   // 设置日志文件最大生存周期
 		LocalTime: true, // 设置日志文件名的时间格式为本地时间
@@ -209,6 +501,10 @@ func setupFlag() error {
 	flag.StringVar(&config, "config", "configs/", "指定要使用的配置文件路径")
 	// 添加版本信息
 	flag.BoolVar(&isVersion, "version", false, "编译信息")
+	// 添加数据库迁移
+	flag.StringVar(&migrateMode, "migrate", "", "数据库迁移: auto 为启动时自动执行迁移，up、down、status 为执行对应操作后退出")
+	// 创建初始管理员，密码从环境变量 BLOG_ADMIN_PASSWORD 读取
+	flag.StringVar(&createAdmin, "create-admin", "", "创建指定用户名的管理员后退出，密码从环境变量 "+adminPasswordEnv+" 读取")
 	flag.Parse()
 
 	return nil
@@ -0,0 +1,10 @@
+package migrations
+
+import "embed"
+
+// 内嵌到二进制文件中的数据库迁移文件，按数据库类型存放在不同目录下
+// 文件命名规则: {版本号}_{名称}.{up|down}.sql
+// 表名固定使用 blog_ 前缀，与 model.TablePrefix 一致
+//
+//go:embed mysql/*.sql postgres/*.sql sqlite3/*.sql
+var FS embed.FS
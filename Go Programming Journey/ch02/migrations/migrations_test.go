@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"database/sql"
+	"testing"
+
+	"demo/ch02/pkg/migrate"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"mysql", "postgres", "sqlite3"} {
+		if _, err := migrate.NewMigrator(nil, dialect, FS); err != nil {
+			t.Errorf("%s: NewMigrator err: %v", dialect, err)
+		}
+	}
+}
+
+// 在 SQLite 中执行全部迁移后逐个回滚
+func TestSQLiteUpDown(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open err: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	m, err := migrate.NewMigrator(db, "sqlite3", FS)
+	if err != nil {
+		t.Fatalf("NewMigrator err: %v", err)
+	}
+	done, err := m.Up()
+	if err != nil {
+		t.Fatalf("Up err: %v", err)
+	}
+	for range done {
+		if _, err := m.Down(); err != nil {
+			t.Fatalf("Down err: %v", err)
+		}
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status err: %v", err)
+	}
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("%06d_%s is still applied", status.Version, status.Name)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `blog_auth`;
+DROP TABLE IF EXISTS `blog_article_tag`;
+DROP TABLE IF EXISTS `blog_article`;
+DROP TABLE IF EXISTS `blog_tag`;
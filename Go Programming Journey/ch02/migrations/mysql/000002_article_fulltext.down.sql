@@ -0,0 +1 @@
+ALTER TABLE `blog_article` DROP INDEX `ft_blog_article`;
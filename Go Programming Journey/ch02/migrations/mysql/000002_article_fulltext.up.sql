@@ -0,0 +1,3 @@
+-- 文章全文索引，使用 ngram 解析器支持中文分词（MySQL 5.7.6 及以上版本）
+-- ngram 的分词长度由 ngram_token_size 决定，默认为 2
+ALTER TABLE `blog_article` ADD FULLTEXT INDEX `ft_blog_article` (`title`, `desc`, `content`) WITH PARSER ngram;
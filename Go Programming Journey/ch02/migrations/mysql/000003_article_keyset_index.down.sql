@@ -0,0 +1 @@
+DROP INDEX `idx_blog_article_created_on` ON `blog_article`;
@@ -0,0 +1,2 @@
+-- 游标分页按 (created_on, id) 排序与比较
+CREATE INDEX `idx_blog_article_created_on` ON `blog_article` (`created_on`, `id`);
@@ -0,0 +1,17 @@
+-- 文章修订历史，每次创建、更新文章时保存一份完整的快照
+CREATE TABLE IF NOT EXISTS `blog_article_revision` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `article_id` int unsigned NOT NULL COMMENT '文章 ID',
+    `title` varchar(100) DEFAULT '' COMMENT '文章标题',
+    `desc` varchar(255) DEFAULT '' COMMENT '文章简述',
+    `cover_image_url` varchar(255) DEFAULT '' COMMENT '封面图片地址',
+    `content` longtext COMMENT '文章内容',
+    `created_on` int unsigned DEFAULT '0' COMMENT '创建时间',
+    `created_by` varchar(100) DEFAULT '' COMMENT '修订人',
+    `modified_on` int unsigned DEFAULT '0' COMMENT '修改时间',
+    `modified_by` varchar(100) DEFAULT '' COMMENT '修改人',
+    `deleted_on` int unsigned DEFAULT '0' COMMENT '删除时间',
+    `is_del` tinyint unsigned DEFAULT '0' COMMENT '是否删除 0 为未删除、1 为已删除',
+    PRIMARY KEY (`id`),
+    KEY `idx_article_id` (`article_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='文章修订历史';
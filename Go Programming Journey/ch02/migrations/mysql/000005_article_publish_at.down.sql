@@ -0,0 +1,4 @@
+DROP INDEX `idx_blog_article_publish_at` ON `blog_article`;
+ALTER TABLE `blog_article`
+    DROP COLUMN `publish_at`,
+    MODIFY COLUMN `state` tinyint unsigned DEFAULT '1' COMMENT '状态 0 为禁用、1 为启用';
@@ -0,0 +1,6 @@
+-- 文章生命周期: 0 为已归档、1 为已发布、2 为草稿、3 为审核中、4 为定时发布
+ALTER TABLE `blog_article`
+    MODIFY COLUMN `state` tinyint unsigned DEFAULT '1' COMMENT '状态 0 为已归档、1 为已发布、2 为草稿、3 为审核中、4 为定时发布',
+    ADD COLUMN `publish_at` int unsigned DEFAULT '0' COMMENT '发布时间，定时发布的文章为计划发布时间';
+-- 定时发布任务按 (state, publish_at) 查找到期的文章
+CREATE INDEX `idx_blog_article_publish_at` ON `blog_article` (`state`, `publish_at`);
@@ -0,0 +1,18 @@
+-- 文章评论，root_id 为所属顶层评论，parent_id 为回复的评论，顶层评论两者均为 0
+CREATE TABLE IF NOT EXISTS `blog_comment` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `article_id` int unsigned NOT NULL COMMENT '文章 ID',
+    `root_id` int unsigned NOT NULL DEFAULT '0' COMMENT '顶层评论 ID',
+    `parent_id` int unsigned NOT NULL DEFAULT '0' COMMENT '回复的评论 ID',
+    `content` varchar(1000) DEFAULT '' COMMENT '评论内容',
+    `created_on` int unsigned DEFAULT '0' COMMENT '创建时间',
+    `created_by` varchar(100) DEFAULT '' COMMENT '评论人',
+    `modified_on` int unsigned DEFAULT '0' COMMENT '修改时间',
+    `modified_by` varchar(100) DEFAULT '' COMMENT '修改人',
+    `deleted_on` int unsigned DEFAULT '0' COMMENT '删除时间',
+    `is_del` tinyint unsigned DEFAULT '0' COMMENT '是否删除 0 为未删除、1 为已删除',
+    `state` tinyint unsigned DEFAULT '0' COMMENT '状态 0 为待审核、1 为已通过、2 为已拒绝',
+    PRIMARY KEY (`id`),
+    KEY `idx_article_id` (`article_id`, `root_id`, `state`),
+    KEY `idx_state` (`state`, `created_on`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='文章评论';
@@ -0,0 +1,18 @@
+-- 用户，password 为 bcrypt 哈希，role 为 admin、editor、viewer 之一
+CREATE TABLE IF NOT EXISTS `blog_user` (
+    `id` int unsigned NOT NULL AUTO_INCREMENT,
+    `username` varchar(100) NOT NULL COMMENT '用户名',
+    `password` varchar(100) NOT NULL DEFAULT '' COMMENT 'bcrypt 哈希后的密码',
+    `role` varchar(20) NOT NULL DEFAULT 'viewer' COMMENT '角色 admin、editor、viewer',
+    `created_on` int unsigned DEFAULT '0' COMMENT '创建时间',
+    `created_by` varchar(100) DEFAULT '' COMMENT '创建人',
+    `modified_on` int unsigned DEFAULT '0' COMMENT '修改时间',
+    `modified_by` varchar(100) DEFAULT '' COMMENT '修改人',
+    `deleted_on` int unsigned DEFAULT '0' COMMENT '删除时间',
+    `is_del` tinyint unsigned DEFAULT '0' COMMENT '是否删除 0 为未删除、1 为已删除',
+    `state` tinyint unsigned DEFAULT '1' COMMENT '状态 0 为禁用、1 为启用',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户';
+
+-- 不创建初始管理员，避免使用公开的默认密码，部署后通过 -create-admin 参数创建
@@ -0,0 +1,8 @@
+-- 已吊销的 JWT，jti 为 token 的唯一标识，expires_on 之后 token 自然失效，记录可清理
+CREATE TABLE IF NOT EXISTS `blog_token_revocation` (
+    `jti` varchar(64) NOT NULL COMMENT 'token 唯一标识',
+    `expires_on` int unsigned NOT NULL DEFAULT '0' COMMENT 'token 过期时间',
+    `created_on` int unsigned DEFAULT '0' COMMENT '吊销时间',
+    PRIMARY KEY (`jti`),
+    KEY `idx_expires_on` (`expires_on`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='已吊销的 token';
@@ -0,0 +1,5 @@
+-- 已哈希的 secret 无法还原为明文，app_secret 保持加长后的长度
+ALTER TABLE `blog_auth`
+    DROP KEY `idx_app_key`,
+    DROP COLUMN `scopes`,
+    DROP COLUMN `state`;
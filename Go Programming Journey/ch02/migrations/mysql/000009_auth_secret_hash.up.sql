@@ -0,0 +1,9 @@
+-- app_secret 改为存储 bcrypt 哈希，已有的明文 secret 在首次认证成功后重新哈希
+ALTER TABLE `blog_auth`
+    MODIFY COLUMN `app_secret` varchar(100) DEFAULT '' COMMENT 'Secret 的 bcrypt 哈希',
+    ADD COLUMN `scopes` varchar(255) NOT NULL DEFAULT '' COMMENT '权限范围，逗号分隔，* 为全部权限' AFTER `app_secret`,
+    ADD COLUMN `state` tinyint unsigned DEFAULT '1' COMMENT '状态 0 为禁用、1 为启用',
+    ADD KEY `idx_app_key` (`app_key`);
+
+-- 已有的 app_key 保持原先的全部权限
+UPDATE `blog_auth` SET `scopes` = '*';
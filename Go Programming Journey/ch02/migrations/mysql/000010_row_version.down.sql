@@ -0,0 +1,2 @@
+ALTER TABLE `blog_tag` DROP COLUMN `version`;
+ALTER TABLE `blog_article` DROP COLUMN `version`;
@@ -0,0 +1,5 @@
+-- 每次更新时加 1，If-Match 的条件更新比较版本号，修改时间只精确到秒，同一秒内的修改无法区分
+ALTER TABLE `blog_article`
+    ADD COLUMN `version` int unsigned NOT NULL DEFAULT '0' COMMENT '版本号，每次更新时加 1';
+ALTER TABLE `blog_tag`
+    ADD COLUMN `version` int unsigned NOT NULL DEFAULT '0' COMMENT '版本号，每次更新时加 1';
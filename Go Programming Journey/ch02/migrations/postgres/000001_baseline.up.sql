@@ -0,0 +1,55 @@
+-- 基线迁移，与 scripts/ch02.sql 中的表结构一致
+CREATE TABLE IF NOT EXISTS blog_tag (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) DEFAULT '',
+    created_on BIGINT DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on BIGINT DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on BIGINT DEFAULT 0,
+    is_del SMALLINT DEFAULT 0,
+    state SMALLINT DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS blog_article (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(100) DEFAULT '',
+    "desc" VARCHAR(255) DEFAULT '',
+    cover_image_url VARCHAR(255) DEFAULT '',
+    content TEXT,
+    created_on BIGINT DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on BIGINT DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on BIGINT DEFAULT 0,
+    is_del SMALLINT DEFAULT 0,
+    state SMALLINT DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS blog_article_tag (
+    id SERIAL PRIMARY KEY,
+    article_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL DEFAULT 0,
+    created_on BIGINT DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on BIGINT DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on BIGINT DEFAULT 0,
+    is_del SMALLINT DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS blog_auth (
+    id SERIAL PRIMARY KEY,
+    app_key VARCHAR(20) DEFAULT '',
+    app_secret VARCHAR(50) DEFAULT '',
+    created_on BIGINT DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on BIGINT DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on BIGINT DEFAULT 0,
+    is_del SMALLINT DEFAULT 0
+);
+
+INSERT INTO blog_auth (app_key, app_secret, created_by)
+SELECT 'admin', 'go-learning', 'test'
+WHERE NOT EXISTS (SELECT 1 FROM blog_auth WHERE app_key = 'admin');
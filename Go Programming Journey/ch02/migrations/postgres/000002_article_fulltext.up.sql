@@ -0,0 +1,2 @@
+-- 该数据库未使用全文索引，文章搜索由应用内置的倒排索引提供
+-- 保留空的迁移以使各数据库的迁移版本号保持一致
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_blog_article_created_on;
@@ -0,0 +1,17 @@
+-- 文章修订历史，每次创建、更新文章时保存一份完整的快照
+CREATE TABLE IF NOT EXISTS blog_article_revision (
+    id SERIAL PRIMARY KEY,
+    article_id INTEGER NOT NULL,
+    title VARCHAR(100) DEFAULT '',
+    "desc" VARCHAR(255) DEFAULT '',
+    cover_image_url VARCHAR(255) DEFAULT '',
+    content TEXT,
+    created_on BIGINT DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on BIGINT DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on BIGINT DEFAULT 0,
+    is_del SMALLINT DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_article_revision_article_id ON blog_article_revision (article_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_blog_article_publish_at;
+ALTER TABLE blog_article DROP COLUMN IF EXISTS publish_at;
@@ -0,0 +1,4 @@
+-- 已哈希的 secret 无法还原为明文，app_secret 保持加长后的长度
+DROP INDEX IF EXISTS idx_blog_auth_app_key;
+ALTER TABLE blog_auth DROP COLUMN IF EXISTS scopes;
+ALTER TABLE blog_auth DROP COLUMN IF EXISTS state;
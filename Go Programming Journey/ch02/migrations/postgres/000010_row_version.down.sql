@@ -0,0 +1,2 @@
+ALTER TABLE blog_tag DROP COLUMN IF EXISTS version;
+ALTER TABLE blog_article DROP COLUMN IF EXISTS version;
@@ -0,0 +1,54 @@
+-- 基线迁移，与 scripts/ch02.sql 中的表结构一致
+CREATE TABLE IF NOT EXISTS blog_tag (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) DEFAULT '',
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0,
+    state INTEGER DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS blog_article (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(100) DEFAULT '',
+    "desc" VARCHAR(255) DEFAULT '',
+    cover_image_url VARCHAR(255) DEFAULT '',
+    content TEXT,
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0,
+    state INTEGER DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS blog_article_tag (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL DEFAULT 0,
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS blog_auth (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    app_key VARCHAR(20) DEFAULT '',
+    app_secret VARCHAR(50) DEFAULT '',
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0
+);
+
+INSERT OR IGNORE INTO blog_auth (id, app_key, app_secret, created_on, created_by, modified_on, modified_by, deleted_on, is_del)
+VALUES (1, 'admin', 'go-learning', 0, 'test', 0, '', 0, 0);
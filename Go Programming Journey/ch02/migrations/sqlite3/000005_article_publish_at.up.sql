@@ -0,0 +1,4 @@
+-- 文章生命周期: 0 为已归档、1 为已发布、2 为草稿、3 为审核中、4 为定时发布
+ALTER TABLE blog_article ADD COLUMN publish_at INTEGER DEFAULT 0;
+-- 定时发布任务按 (state, publish_at) 查找到期的文章
+CREATE INDEX IF NOT EXISTS idx_blog_article_publish_at ON blog_article (state, publish_at);
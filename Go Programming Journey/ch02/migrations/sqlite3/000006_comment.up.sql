@@ -0,0 +1,18 @@
+-- 文章评论，root_id 为所属顶层评论，parent_id 为回复的评论，顶层评论两者均为 0
+CREATE TABLE IF NOT EXISTS blog_comment (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id INTEGER NOT NULL,
+    root_id INTEGER NOT NULL DEFAULT 0,
+    parent_id INTEGER NOT NULL DEFAULT 0,
+    content VARCHAR(1000) DEFAULT '',
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0,
+    state INTEGER DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_comment_article_id ON blog_comment (article_id, root_id, state);
+CREATE INDEX IF NOT EXISTS idx_blog_comment_state ON blog_comment (state, created_on);
@@ -0,0 +1,18 @@
+-- 用户，password 为 bcrypt 哈希，role 为 admin、editor、viewer 之一
+CREATE TABLE IF NOT EXISTS blog_user (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(100) NOT NULL,
+    password VARCHAR(100) NOT NULL DEFAULT '',
+    role VARCHAR(20) NOT NULL DEFAULT 'viewer',
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0,
+    state INTEGER DEFAULT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_blog_user_username ON blog_user (username);
+
+-- 不创建初始管理员，避免使用公开的默认密码，部署后通过 -create-admin 参数创建
@@ -0,0 +1,8 @@
+-- 已吊销的 JWT，jti 为 token 的唯一标识，expires_on 之后 token 自然失效，记录可清理
+CREATE TABLE IF NOT EXISTS blog_token_revocation (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_on INTEGER NOT NULL DEFAULT 0,
+    created_on INTEGER DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_token_revocation_expires_on ON blog_token_revocation (expires_on);
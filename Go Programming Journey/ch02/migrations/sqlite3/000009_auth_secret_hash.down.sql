@@ -0,0 +1,17 @@
+-- SQLite 3.35 之前不支持 DROP COLUMN，通过重建表移除 scopes 与 state，已哈希的 secret 无法还原为明文
+DROP INDEX IF EXISTS idx_blog_auth_app_key;
+CREATE TABLE blog_auth_backup (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    app_key VARCHAR(20) DEFAULT '',
+    app_secret VARCHAR(50) DEFAULT '',
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0
+);
+INSERT INTO blog_auth_backup (id, app_key, app_secret, created_on, created_by, modified_on, modified_by, deleted_on, is_del)
+    SELECT id, app_key, app_secret, created_on, created_by, modified_on, modified_by, deleted_on, is_del FROM blog_auth;
+DROP TABLE blog_auth;
+ALTER TABLE blog_auth_backup RENAME TO blog_auth;
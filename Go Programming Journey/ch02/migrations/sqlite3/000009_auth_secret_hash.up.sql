@@ -0,0 +1,7 @@
+-- app_secret 改为存储 bcrypt 哈希，已有的明文 secret 在首次认证成功后重新哈希，SQLite 不限制 VARCHAR 长度
+ALTER TABLE blog_auth ADD COLUMN scopes VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE blog_auth ADD COLUMN state INTEGER DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_blog_auth_app_key ON blog_auth (app_key);
+
+-- 已有的 app_key 保持原先的全部权限
+UPDATE blog_auth SET scopes = '*';
@@ -0,0 +1,40 @@
+-- SQLite 3.35 之前不支持 DROP COLUMN，通过重建表移除 version
+CREATE TABLE blog_tag_backup (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) DEFAULT '',
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0,
+    state INTEGER DEFAULT 1
+);
+INSERT INTO blog_tag_backup (id, name, created_on, created_by, modified_on, modified_by, deleted_on, is_del, state)
+    SELECT id, name, created_on, created_by, modified_on, modified_by, deleted_on, is_del, state FROM blog_tag;
+DROP TABLE blog_tag;
+ALTER TABLE blog_tag_backup RENAME TO blog_tag;
+
+DROP INDEX IF EXISTS idx_blog_article_created_on;
+DROP INDEX IF EXISTS idx_blog_article_publish_at;
+CREATE TABLE blog_article_backup (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(100) DEFAULT '',
+    "desc" VARCHAR(255) DEFAULT '',
+    cover_image_url VARCHAR(255) DEFAULT '',
+    content TEXT,
+    created_on INTEGER DEFAULT 0,
+    created_by VARCHAR(100) DEFAULT '',
+    modified_on INTEGER DEFAULT 0,
+    modified_by VARCHAR(100) DEFAULT '',
+    deleted_on INTEGER DEFAULT 0,
+    is_del INTEGER DEFAULT 0,
+    state INTEGER DEFAULT 1,
+    publish_at INTEGER DEFAULT 0
+);
+INSERT INTO blog_article_backup (id, title, "desc", cover_image_url, content, created_on, created_by, modified_on, modified_by, deleted_on, is_del, state, publish_at)
+    SELECT id, title, "desc", cover_image_url, content, created_on, created_by, modified_on, modified_by, deleted_on, is_del, state, publish_at FROM blog_article;
+DROP TABLE blog_article;
+ALTER TABLE blog_article_backup RENAME TO blog_article;
+CREATE INDEX IF NOT EXISTS idx_blog_article_created_on ON blog_article (created_on, id);
+CREATE INDEX IF NOT EXISTS idx_blog_article_publish_at ON blog_article (state, publish_at);
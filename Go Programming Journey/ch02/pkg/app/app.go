@@ -2,8 +2,10 @@ package app
 
 import (
 	"demo/ch02/pkg/errcode"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"time"
 )
 
 type Response struct {
@@ -13,30 +15,69 @@ type Pager struct {
 	Page      int `json:"page"`
 	PageSize  int `json:"page_size"`
 	TotalRows int `json:"total_rows"`
+	// 游标分页时下一页的游标，没有下一页时为空
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func NewResponse(ctx *gin.Context) *Response {
 	return &Response{Ctx: ctx}
 }
 
-// 成功响应处理
+// 成功响应处理，GET 请求返回 ETag，data 实现了 LastModifier 时另返回 Last-Modified
 func (r *Response) ToResponse(data interface{}) {
 	if data == nil {
 		data = gin.H{}
 	}
-	r.Ctx.JSON(http.StatusOK, data)
+	var lastModified time.Time
+	if m, ok := data.(LastModifier); ok {
+		lastModified = m.LastModified()
+	}
+	r.toConditionalJSON(data, lastModified)
 }
 
 // 列表响应处理
 func (r *Response) ToResponseList(list interface{}, totalRows int) {
-	r.Ctx.JSON(http.StatusOK, gin.H{
+	r.ToResponseCursorList(list, totalRows, "")
+}
+
+// 游标分页的列表响应处理，在 pager 中返回下一页的游标
+// 列表中的记录被删除后其他记录的修改时间不变，列表仅返回 ETag
+func (r *Response) ToResponseCursorList(list interface{}, totalRows int, nextCursor string) {
+	r.toConditionalJSON(gin.H{
 		"list": list,
 		"pager": Pager{
-			Page:      GetPage(r.Ctx),
-			PageSize:  GetPageSize(r.Ctx),
-			TotalRows: totalRows,
+			Page:       GetPage(r.Ctx),
+			PageSize:   GetPageSize(r.Ctx),
+			TotalRows:  totalRows,
+			NextCursor: nextCursor,
 		},
-	})
+	}, time.Time{})
+}
+
+// GET 与 HEAD 请求根据响应体计算强 ETag，If-None-Match 或 If-Modified-Since 匹配时返回 304
+func (r *Response) toConditionalJSON(data interface{}, lastModified time.Time) {
+	method := r.Ctx.Request.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		r.Ctx.JSON(http.StatusOK, data)
+		return
+	}
+	// 与 gin 的 JSON 渲染相同使用 json.Marshal，响应体与 NewETag 计算时一致
+	body, err := json.Marshal(data)
+	if err != nil {
+		r.Ctx.JSON(http.StatusOK, data)
+		return
+	}
+	etag := newETag(body)
+	header := r.Ctx.Writer.Header()
+	header.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r.Ctx.Request, etag, lastModified) {
+		r.Ctx.Status(http.StatusNotModified)
+		return
+	}
+	r.Ctx.Data(http.StatusOK, "application/json; charset=utf-8", body)
 }
 
 // 错误响应处理
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/jinzhu/gorm"
+)
+
+// Cursor 为游标分页（keyset pagination）的位置，记录上一页最后一条记录的 (created_on, id)
+// 对客户端而言是不透明的字符串，只能原样传回
+type Cursor struct {
+	CreatedOn uint32 `json:"c"`
+	ID        uint32 `json:"i"`
+}
+
+var ErrInvalidCursor = errors.New("cursor 无效")
+
+func EncodeCursor(cursor Cursor) string {
+	body, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	body, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(body, &cursor); err != nil || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// 是否使用游标分页，请求中带有 cursor 参数（包括空值）即表示使用游标分页
+func (q *QuerySpec) Keyset() bool {
+	return q != nil && q.keyset
+}
+
+// 分页条件，游标分页时按 (created_on, id) 倒序从游标位置之后开始读取
+// 游标分页会多读取一条记录用于判断是否存在下一页，由调用方通过 NextCursor 截断
+func (q *QuerySpec) Paginate(pageOffset, pageSize int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !q.Keyset() {
+			if pageOffset >= 0 && pageSize > 0 {
+				db = db.Offset(pageOffset).Limit(pageSize)
+			}
+			return db
+		}
+
+		createdOn, id := db.Dialect().Quote("created_on"), db.Dialect().Quote("id")
+		if q.Cursor != nil {
+			db = db.Where("("+createdOn+" < ? OR ("+createdOn+" = ? AND "+id+" < ?))", q.Cursor.CreatedOn, q.Cursor.CreatedOn, q.Cursor.ID)
+		}
+		db = db.Order(createdOn + " DESC").Order(id + " DESC")
+		if pageSize > 0 {
+			db = db.Limit(pageSize + 1)
+		}
+		return db
+	}
+}
+
+// 根据多读取的一条记录判断是否存在下一页，返回截断后的记录数与下一页的游标
+// last 用于获取第 n 条记录的 (created_on, id)
+func (q *QuerySpec) NextCursor(count, pageSize int, last func(n int) Cursor) (int, string) {
+	if !q.Keyset() || pageSize <= 0 || count <= pageSize {
+		return count, ""
+	}
+	return pageSize, EncodeCursor(last(pageSize - 1))
+}
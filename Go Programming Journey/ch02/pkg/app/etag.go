@@ -0,0 +1,79 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LastModifier 由带有修改时间的资源实现，ToResponse 据此返回 Last-Modified 并处理 If-Modified-Since
+// 响应中包含其他资源的数据时不应实现该接口，否则其他资源变更后客户端仍会得到 304
+type LastModifier interface {
+	LastModified() time.Time
+}
+
+// 计算 data 序列化为 JSON 后的强 ETag，与 GET 请求中 ToResponse 返回的 ETag 一致
+func NewETag(data interface{}) (string, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return newETag(body), nil
+}
+
+func newETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// 判断 If-Match 请求头是否与 data 当前的 ETag 匹配，未携带 If-Match 时返回 true
+// If-Match 使用强比较，W/ 开头的弱 ETag 不会匹配
+func IfMatch(header string, data interface{}) (bool, error) {
+	if header == "" {
+		return true, nil
+	}
+	etag, err := NewETag(data)
+	if err != nil {
+		return false, err
+	}
+	return matchETag(header, etag, false), nil
+}
+
+// header 为逗号分隔的 ETag 列表，* 匹配任意 ETag，weak 为 true 时忽略 W/ 前缀
+func matchETag(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// 按 RFC 7232 处理条件请求，携带 If-None-Match 时忽略 If-Modified-Since
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return matchETag(header, etag, true)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP 日期只精确到秒
+	return !lastModified.Truncate(time.Second).After(since)
+}
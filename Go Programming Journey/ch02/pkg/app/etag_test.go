@@ -0,0 +1,138 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testETag = `"abc"`
+
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		header string
+		weak   bool
+		want   bool
+	}{
+		{header: `"abc"`, want: true},
+		{header: `"xyz"`, want: false},
+		{header: `"xyz", "abc"`, want: true},
+		{header: `"xyz",  "abc" `, want: true},
+		{header: `*`, want: true},
+		{header: `W/"abc"`, weak: false, want: false},
+		{header: `W/"abc"`, weak: true, want: true},
+		{header: `"xyz", W/"abc"`, weak: true, want: true},
+		{header: `abc`, weak: true, want: false},
+		{header: ``, weak: true, want: false},
+	}
+	for _, tt := range tests {
+		if got := matchETag(tt.header, testETag, tt.weak); got != tt.want {
+			t.Errorf("matchETag(%q, weak %v) = %v, want %v", tt.header, tt.weak, got, tt.want)
+		}
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	data := map[string]int{"id": 1}
+	etag, err := NewETag(data)
+	if err != nil {
+		t.Fatalf("NewETag err: %v", err)
+	}
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: true},
+		{header: etag, want: true},
+		{header: "*", want: true},
+		{header: "W/" + etag, want: false},
+		{header: `"stale"`, want: false},
+	}
+	for _, tt := range tests {
+		if got, err := IfMatch(tt.header, data); err != nil || got != tt.want {
+			t.Errorf("IfMatch(%q) = %v, %v, want %v", tt.header, got, err, tt.want)
+		}
+	}
+	if _, err := IfMatch(etag, make(chan int)); err == nil {
+		t.Error("IfMatch with unmarshalable data err = nil")
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	tests := []struct {
+		name         string
+		ifNoneMatch  string
+		ifModSince   string
+		lastModified time.Time
+		want         bool
+	}{
+		{name: "no headers", lastModified: lastModified, want: false},
+		{name: "etag match", ifNoneMatch: testETag, want: true},
+		{name: "weak etag match", ifNoneMatch: `W/"abc"`, want: true},
+		{name: "etag mismatch", ifNoneMatch: `"xyz"`, want: false},
+		// 携带 If-None-Match 时忽略 If-Modified-Since
+		{name: "etag over date", ifNoneMatch: `"xyz"`, ifModSince: "Mon, 01 Jan 2024 00:00:00 GMT", lastModified: lastModified, want: false},
+		{name: "not modified since", ifModSince: "Mon, 01 Jan 2024 00:00:00 GMT", lastModified: lastModified, want: true},
+		{name: "modified since", ifModSince: "Sun, 31 Dec 2023 23:59:59 GMT", lastModified: lastModified, want: false},
+		{name: "invalid date", ifModSince: "yesterday", lastModified: lastModified, want: false},
+		{name: "no last modified", ifModSince: "Mon, 01 Jan 2024 00:00:00 GMT", want: false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+		if tt.ifModSince != "" {
+			r.Header.Set("If-Modified-Since", tt.ifModSince)
+		}
+		if got := notModified(r, testETag, tt.lastModified); got != tt.want {
+			t.Errorf("%s: notModified = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type modifiedData struct {
+	ID int `json:"id"`
+}
+
+func (modifiedData) LastModified() time.Time {
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestToResponseConditional(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	data := modifiedData{ID: 1}
+	etag, _ := NewETag(data)
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		wantStatus  int
+		wantETag    string
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusOK, wantETag: etag},
+		{name: "get not modified", method: http.MethodGet, ifNoneMatch: etag, wantStatus: http.StatusNotModified, wantETag: etag},
+		{name: "head not modified", method: http.MethodHead, ifNoneMatch: etag, wantStatus: http.StatusNotModified, wantETag: etag},
+		{name: "put", method: http.MethodPut, ifNoneMatch: etag, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(tt.method, "/", nil)
+		if tt.ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+		NewResponse(c).ToResponse(data)
+		c.Writer.WriteHeaderNow()
+		if w.Code != tt.wantStatus || w.Header().Get("ETag") != tt.wantETag {
+			t.Errorf("%s: status = %d, ETag = %q, want %d, %q", tt.name, w.Code, w.Header().Get("ETag"), tt.wantStatus, tt.wantETag)
+		}
+		if tt.wantETag != "" && w.Header().Get("Last-Modified") != "Mon, 01 Jan 2024 00:00:00 GMT" {
+			t.Errorf("%s: Last-Modified = %q", tt.name, w.Header().Get("Last-Modified"))
+		}
+	}
+}
@@ -1,48 +1,110 @@
 package app
 
 import (
+	"crypto/rand"
 	"demo/ch02/global"
-	"demo/ch02/pkg/util"
+	"encoding/hex"
+	"errors"
 	"github.com/dgrijalva/jwt-go"
 	"time"
 )
 
+// token 类型，refresh token 只能用于换取新的 token，不能访问接口
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// Principal 为通过认证的调用方，由 JWT 中间件写入 gin.Context
+type Principal struct {
+	UserID uint32   `json:"uid,omitempty"` // 通过 app_key 认证时为 0
+	Name   string   `json:"name"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes,omitempty"` // 通过 app_key 认证时的权限范围
+}
+
+// StandardClaims.Id 即 jti，用于吊销 token
 type Claims struct {
-	AppKey    string `json:"app_key"`
-	AppSecret string `json:"app_secret"`
+	Principal
+	TokenType string `json:"token_type"`
 	jwt.StandardClaims
 }
 
+// 登录与刷新 token 时返回的 token 对
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token 有效期(秒)
+}
+
 func GetJWTSecret() []byte {
-	return []byte(global.JWTSetting.Secret)
+	return []byte(global.JWTSetting().Secret)
+}
+
+// 签发 access token 与 refresh token
+func GenerateTokenPair(principal Principal) (*TokenPair, error) {
+	accessToken, err := GenerateToken(principal, TokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := GenerateToken(principal, TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(global.JWTSetting().Expire / time.Second),
+	}, nil
 }
 
-// 生成 JWT
-func GenerateToken(appKey, appSecret string) (string, error) {
+// 生成 JWT，每个 token 带有随机的 jti，使用当前的签名密钥签名并在头部写入 kid
+func GenerateToken(principal Principal, tokenType string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	s := global.JWTSetting()
+	expire := s.Expire
+	if tokenType == TokenTypeRefresh {
+		expire = s.RefreshExpire
+	}
 	nowTime := time.Now()
-	expireTime := nowTime.Add(global.JWTSetting.Expire)
 	claims := Claims{
-		AppKey:    util.EncodeMD5(appKey),
-		AppSecret: util.EncodeMD5(appSecret),
+		Principal: principal,
+		TokenType: tokenType,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expireTime.Unix(),
-			Issuer:    global.JWTSetting.Issuer,
+			Id:        jti,
+			IssuedAt:  nowTime.Unix(),
+			ExpiresAt: nowTime.Add(expire).Unix(),
+			Issuer:    s.Issuer,
 		},
 	}
-	// 根据 Claims 结构体创建 Token 实例，jwt.NewWithClaims() 包含两个形参
-	// SigningMethod，其包含 SigningMethodHS256、SigningMethodHS384、SigningMethodHS512 三种 crypto.Hash 加密算法的方案
-	// 第二个参数为 Claims 主要用于传递用户所预定义的一些权限要求，方便后续的加密、校验
-	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	key := getSigningKey()
+	// 根据 Claims 结构体创建 Token 实例，SigningMethod 由当前的签名密钥决定
+	tokenClaims := jwt.NewWithClaims(key.method, claims)
+	if key.id != "" {
+		tokenClaims.Header["kid"] = key.id
+	}
 	// SignedString() 生成签名后的 token 字符串
-	token, err := tokenClaims.SignedString(GetJWTSecret())
-	return token, err
+	return tokenClaims.SignedString(key.signKey)
 }
 
 // 解析和校验 Token
 func ParseToken(token string) (*Claims, error) {
 	// jwt.ParseWithClaims() 用于解析鉴权的声明，最终返回 *Token
 	tokenClaims, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return GetJWTSecret(), nil
+		kid, _ := token.Header["kid"].(string)
+		key := getVerifyKey(kid)
+		if key == nil {
+			return nil, errors.New("unknown kid")
+		}
+		// 签名算法必须与密钥一致，避免使用公钥作为 HMAC 密钥伪造 token
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verifyKey, nil
 	})
 	if err != nil {
 		return nil, err
@@ -55,3 +117,11 @@ func ParseToken(token string) (*Claims, error) {
 	}
 	return nil, err
 }
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
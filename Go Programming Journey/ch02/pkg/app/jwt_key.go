@@ -0,0 +1,183 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"demo/ch02/pkg/setting"
+	"encoding/base64"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"io/ioutil"
+	"math/big"
+	"sort"
+)
+
+// 签名与校验 JWT 使用的密钥，HS256 时 id 为空，signKey 与 verifyKey 均为 Secret
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{} // 仅保留公钥的密钥为 nil
+	verifyKey interface{}
+}
+
+// 未调用 SetupJWTKeys 或未配置 SigningKeyID 时为 nil，使用 HS256 签名
+var jwtKeys map[string]*jwtKey
+var jwtSigningKey *jwtKey
+
+// 加载配置中的非对称密钥，配置了 SigningKeyID 后不再接受 HS256 签名的 token
+func SetupJWTKeys(s *setting.JWTSettingS) error {
+	keys := make(map[string]*jwtKey, len(s.Keys))
+	for _, k := range s.Keys {
+		if k.ID == "" {
+			return fmt.Errorf("jwt key: missing ID")
+		}
+		if _, ok := keys[k.ID]; ok {
+			return fmt.Errorf("jwt key %s: duplicate ID", k.ID)
+		}
+		key, err := loadJWTKey(k)
+		if err != nil {
+			return fmt.Errorf("jwt key %s: %w", k.ID, err)
+		}
+		keys[k.ID] = key
+	}
+
+	if s.SigningKeyID == "" {
+		jwtKeys, jwtSigningKey = nil, nil
+		return nil
+	}
+	signing, ok := keys[s.SigningKeyID]
+	if !ok {
+		return fmt.Errorf("jwt signing key %s: not found in Keys", s.SigningKeyID)
+	}
+	if signing.signKey == nil {
+		return fmt.Errorf("jwt signing key %s: missing PrivateKeyFile", s.SigningKeyID)
+	}
+	jwtKeys, jwtSigningKey = keys, signing
+	return nil
+}
+
+func loadJWTKey(k setting.JWTKeyS) (*jwtKey, error) {
+	key := &jwtKey{id: k.ID}
+	var privatePEM, publicPEM []byte
+	var err error
+	if k.PrivateKeyFile != "" {
+		if privatePEM, err = ioutil.ReadFile(k.PrivateKeyFile); err != nil {
+			return nil, err
+		}
+	} else if k.PublicKeyFile != "" {
+		if publicPEM, err = ioutil.ReadFile(k.PublicKeyFile); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("missing PrivateKeyFile or PublicKeyFile")
+	}
+
+	switch k.Algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key.method = jwt.SigningMethodRS256
+		if privatePEM != nil {
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey, key.verifyKey = private, &private.PublicKey
+		} else {
+			if key.verifyKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM); err != nil {
+				return nil, err
+			}
+		}
+	case jwt.SigningMethodES256.Alg():
+		key.method = jwt.SigningMethodES256
+		var public *ecdsa.PublicKey
+		if privatePEM != nil {
+			private, err := jwt.ParseECPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey, public = private, &private.PublicKey
+		} else {
+			if public, err = jwt.ParseECPublicKeyFromPEM(publicPEM); err != nil {
+				return nil, err
+			}
+		}
+		// ES256 只能使用 P-256 曲线
+		if public.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ES256 requires a P-256 key")
+		}
+		key.verifyKey = public
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+	return key, nil
+}
+
+func getSigningKey() *jwtKey {
+	if jwtSigningKey != nil {
+		return jwtSigningKey
+	}
+	secret := GetJWTSecret()
+	return &jwtKey{method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+// 按 kid 获取校验密钥，未配置非对称密钥时仅接受不带 kid 的 HS256 token
+func getVerifyKey(kid string) *jwtKey {
+	if jwtKeys == nil {
+		if kid != "" {
+			return nil
+		}
+		return getSigningKey()
+	}
+	return jwtKeys[kid]
+}
+
+// JWK 为 RFC 7517 格式的公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet 为 /.well-known/jwks.json 的返回格式
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// 返回全部非对称密钥的公钥，按 kid 排序，使用 HS256 时返回空列表
+func JWKS() []JWK {
+	jwks := make([]JWK, 0, len(jwtKeys))
+	for _, key := range jwtKeys {
+		jwk := JWK{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			jwk.Kty = "EC"
+			jwk.Crv = public.Curve.Params().Name
+			jwk.X = encodeECCoordinate(public.X, public.Curve)
+			jwk.Y = encodeECCoordinate(public.Y, public.Curve)
+		default:
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
+
+// 坐标需按曲线长度补齐前导 0
+func encodeECCoordinate(v *big.Int, curve elliptic.Curve) string {
+	size := (curve.Params().BitSize + 7) / 8
+	b := v.Bytes()
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return base64.RawURLEncoding.EncodeToString(padded)
+}
@@ -0,0 +1,320 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"demo/ch02/global"
+	"demo/ch02/pkg/setting"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// 替换 JWT 配置，测试结束后恢复全局变量
+func setTestJWT(t *testing.T, s *setting.JWTSettingS) {
+	oldSettings, oldKeys, oldSigningKey := global.LoadSettings(), jwtKeys, jwtSigningKey
+	t.Cleanup(func() {
+		global.StoreSettings(oldSettings)
+		jwtKeys, jwtSigningKey = oldKeys, oldSigningKey
+	})
+	global.UpdateSettings(func(gs *global.Settings) { gs.JWT = s })
+	if err := SetupJWTKeys(s); err != nil {
+		t.Fatalf("SetupJWTKeys err: %v", err)
+	}
+}
+
+func writePEM(t *testing.T, name, typ string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatalf("WriteFile err: %v", err)
+	}
+	return path
+}
+
+func writeRSAKey(t *testing.T, key *rsa.PrivateKey) (string, string) {
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey err: %v", err)
+	}
+	return writePEM(t, "rsa.key", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)),
+		writePEM(t, "rsa.pub", "PUBLIC KEY", public)
+}
+
+func writeECKey(t *testing.T, key *ecdsa.PrivateKey) (string, string) {
+	private, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey err: %v", err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey err: %v", err)
+	}
+	return writePEM(t, "ec.key", "EC PRIVATE KEY", private), writePEM(t, "ec.pub", "PUBLIC KEY", public)
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey err: %v", err)
+	}
+	return key
+}
+
+func newECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey err: %v", err)
+	}
+	return key
+}
+
+func tokenHeader(t *testing.T, token string) map[string]interface{} {
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified err: %v", err)
+	}
+	return parsed.Header
+}
+
+func TestTokenHS256(t *testing.T) {
+	setTestJWT(t, &setting.JWTSettingS{Secret: "secret", Issuer: "blog", Expire: time.Hour, RefreshExpire: 2 * time.Hour})
+	principal := Principal{UserID: 1, Name: "root", Role: "admin"}
+	pair, err := GenerateTokenPair(principal)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair err: %v", err)
+	}
+	if pair.ExpiresIn != 3600 {
+		t.Errorf("ExpiresIn = %d, want 3600", pair.ExpiresIn)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		tokenType  string
+		wantExpire time.Duration
+	}{
+		{name: "access", token: pair.AccessToken, tokenType: TokenTypeAccess, wantExpire: time.Hour},
+		{name: "refresh", token: pair.RefreshToken, tokenType: TokenTypeRefresh, wantExpire: 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		header := tokenHeader(t, tt.token)
+		if header["alg"] != "HS256" || header["kid"] != nil {
+			t.Errorf("%s: header = %v, want HS256 without kid", tt.name, header)
+		}
+		claims, err := ParseToken(tt.token)
+		if err != nil {
+			t.Errorf("%s: ParseToken err: %v", tt.name, err)
+			continue
+		}
+		if claims.Principal.Name != "root" || claims.UserID != 1 || claims.TokenType != tt.tokenType || claims.Issuer != "blog" {
+			t.Errorf("%s: claims = %+v", tt.name, claims)
+		}
+		if claims.Id == "" {
+			t.Errorf("%s: jti is empty", tt.name)
+		}
+		if got := time.Duration(claims.ExpiresAt-claims.IssuedAt) * time.Second; got != tt.wantExpire {
+			t.Errorf("%s: expire = %v, want %v", tt.name, got, tt.wantExpire)
+		}
+	}
+
+	// 每个 token 的 jti 不同，吊销时互不影响
+	access, _ := ParseToken(pair.AccessToken)
+	refresh, _ := ParseToken(pair.RefreshToken)
+	if access.Id == refresh.Id {
+		t.Errorf("access and refresh token share jti %s", access.Id)
+	}
+}
+
+func TestParseTokenHS256Errors(t *testing.T) {
+	setTestJWT(t, &setting.JWTSettingS{Secret: "secret", Expire: time.Hour})
+	sign := func(claims jwt.Claims, kid string, secret string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("SignedString err: %v", err)
+		}
+		return s
+	}
+	valid := jwt.StandardClaims{Id: "jti", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+	expired := jwt.StandardClaims{Id: "jti", ExpiresAt: time.Now().Add(-time.Minute).Unix()}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantErrors uint32
+	}{
+		{name: "wrong secret", token: sign(valid, "", "other"), wantErrors: jwt.ValidationErrorSignatureInvalid},
+		{name: "expired", token: sign(expired, "", "secret"), wantErrors: jwt.ValidationErrorExpired},
+		// 未配置非对称密钥时不接受带 kid 的 token
+		{name: "unexpected kid", token: sign(valid, "k1", "secret"), wantErrors: jwt.ValidationErrorUnverifiable},
+		{name: "malformed", token: "not.a.token", wantErrors: jwt.ValidationErrorMalformed},
+	}
+	for _, tt := range tests {
+		_, err := ParseToken(tt.token)
+		verr, ok := err.(*jwt.ValidationError)
+		if !ok {
+			t.Errorf("%s: ParseToken err = %v, want *jwt.ValidationError", tt.name, err)
+			continue
+		}
+		if verr.Errors != tt.wantErrors {
+			t.Errorf("%s: Errors = %b, want %b", tt.name, verr.Errors, tt.wantErrors)
+		}
+	}
+}
+
+func TestTokenKeys(t *testing.T) {
+	rsaKey, ecKey, retiredKey := newRSAKey(t), newECKey(t, elliptic.P256()), newRSAKey(t)
+	rsaPrivate, rsaPublic := writeRSAKey(t, rsaKey)
+	ecPrivate, _ := writeECKey(t, ecKey)
+	_, retiredPublic := writeRSAKey(t, retiredKey)
+	keys := []setting.JWTKeyS{
+		{ID: "rs1", Algorithm: "RS256", PrivateKeyFile: rsaPrivate},
+		{ID: "es1", Algorithm: "ES256", PrivateKeyFile: ecPrivate},
+		{ID: "old", Algorithm: "RS256", PublicKeyFile: retiredPublic},
+	}
+	setTestJWT(t, &setting.JWTSettingS{Secret: "secret", Expire: time.Hour, SigningKeyID: "rs1", Keys: keys})
+	principal := Principal{Name: "admin", Role: "app", Scopes: []string{"*"}}
+
+	rsToken, err := GenerateToken(principal, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("GenerateToken err: %v", err)
+	}
+	if header := tokenHeader(t, rsToken); header["alg"] != "RS256" || header["kid"] != "rs1" {
+		t.Errorf("header = %v, want RS256 with kid rs1", header)
+	}
+
+	// 轮换签名密钥后，旧密钥签发的 token 仍然有效
+	if err := SetupJWTKeys(&setting.JWTSettingS{SigningKeyID: "es1", Keys: keys}); err != nil {
+		t.Fatalf("SetupJWTKeys err: %v", err)
+	}
+	esToken, err := GenerateToken(principal, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("GenerateToken err: %v", err)
+	}
+	if header := tokenHeader(t, esToken); header["alg"] != "ES256" || header["kid"] != "es1" {
+		t.Errorf("header = %v, want ES256 with kid es1", header)
+	}
+
+	signRS256 := func(kid string, key *rsa.PrivateKey) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, Claims{Principal: principal, StandardClaims: jwt.StandardClaims{Id: "jti"}})
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString err: %v", err)
+		}
+		return s
+	}
+	// 使用公钥作为 HMAC 密钥伪造的 token
+	publicPEM, _ := ioutil.ReadFile(rsaPublic)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{Principal: principal})
+	forged.Header["kid"] = "rs1"
+	forgedToken, _ := forged.SignedString(publicPEM)
+	hsToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{Principal: principal}).SignedString([]byte("secret"))
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "rs256", token: rsToken},
+		{name: "es256", token: esToken},
+		{name: "retired key", token: signRS256("old", retiredKey)},
+		{name: "unknown kid", token: signRS256("rs2", rsaKey), wantErr: true},
+		{name: "wrong key for kid", token: signRS256("old", rsaKey), wantErr: true},
+		{name: "alg mismatch", token: forgedToken, wantErr: true},
+		// 配置了非对称密钥后不再接受 HS256 签名的 token
+		{name: "hs256 without kid", token: hsToken, wantErr: true},
+	}
+	for _, tt := range tests {
+		claims, err := ParseToken(tt.token)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ParseToken err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && claims.Principal.Name != "admin" {
+			t.Errorf("%s: claims = %+v", tt.name, claims)
+		}
+	}
+}
+
+func TestSetupJWTKeysErrors(t *testing.T) {
+	rsaPrivate, rsaPublic := writeRSAKey(t, newRSAKey(t))
+	p384Private, _ := writeECKey(t, newECKey(t, elliptic.P384()))
+	setTestJWT(t, &setting.JWTSettingS{})
+
+	tests := []struct {
+		name    string
+		setting *setting.JWTSettingS
+	}{
+		{name: "missing id", setting: &setting.JWTSettingS{Keys: []setting.JWTKeyS{{Algorithm: "RS256", PrivateKeyFile: rsaPrivate}}}},
+		{name: "duplicate id", setting: &setting.JWTSettingS{Keys: []setting.JWTKeyS{
+			{ID: "k1", Algorithm: "RS256", PrivateKeyFile: rsaPrivate},
+			{ID: "k1", Algorithm: "RS256", PublicKeyFile: rsaPublic},
+		}}},
+		{name: "missing key file", setting: &setting.JWTSettingS{Keys: []setting.JWTKeyS{{ID: "k1", Algorithm: "RS256"}}}},
+		{name: "unsupported algorithm", setting: &setting.JWTSettingS{Keys: []setting.JWTKeyS{{ID: "k1", Algorithm: "HS256", PrivateKeyFile: rsaPrivate}}}},
+		{name: "algorithm mismatch", setting: &setting.JWTSettingS{Keys: []setting.JWTKeyS{{ID: "k1", Algorithm: "ES256", PrivateKeyFile: rsaPrivate}}}},
+		{name: "es256 with p-384", setting: &setting.JWTSettingS{Keys: []setting.JWTKeyS{{ID: "k1", Algorithm: "ES256", PrivateKeyFile: p384Private}}}},
+		{name: "unknown signing key", setting: &setting.JWTSettingS{SigningKeyID: "k2", Keys: []setting.JWTKeyS{{ID: "k1", Algorithm: "RS256", PrivateKeyFile: rsaPrivate}}}},
+		// 只有公钥的密钥不能用于签名
+		{name: "signing key without private key", setting: &setting.JWTSettingS{SigningKeyID: "k1", Keys: []setting.JWTKeyS{{ID: "k1", Algorithm: "RS256", PublicKeyFile: rsaPublic}}}},
+	}
+	for _, tt := range tests {
+		if err := SetupJWTKeys(tt.setting); err == nil {
+			t.Errorf("%s: SetupJWTKeys err = nil, want error", tt.name)
+		}
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t), newECKey(t, elliptic.P256())
+	rsaPrivate, _ := writeRSAKey(t, rsaKey)
+	_, ecPublic := writeECKey(t, ecKey)
+	setTestJWT(t, &setting.JWTSettingS{Secret: "secret"})
+	if got := JWKS(); len(got) != 0 {
+		t.Errorf("JWKS with HS256 = %v, want empty", got)
+	}
+
+	setTestJWT(t, &setting.JWTSettingS{SigningKeyID: "rs1", Keys: []setting.JWTKeyS{
+		{ID: "rs1", Algorithm: "RS256", PrivateKeyFile: rsaPrivate},
+		{ID: "es0", Algorithm: "ES256", PublicKeyFile: ecPublic},
+	}})
+	jwks := JWKS()
+	if len(jwks) != 2 || jwks[0].Kid != "es0" || jwks[1].Kid != "rs1" {
+		t.Fatalf("JWKS = %+v, want es0, rs1", jwks)
+	}
+
+	decode := func(s string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("DecodeString(%q) err: %v", s, err)
+		}
+		return new(big.Int).SetBytes(b)
+	}
+	ec, rs := jwks[0], jwks[1]
+	if ec.Kty != "EC" || ec.Alg != "ES256" || ec.Use != "sig" || ec.Crv != "P-256" || ec.N != "" {
+		t.Errorf("EC JWK = %+v", ec)
+	}
+	// 坐标固定为 32 字节
+	if len(ec.X) != 43 || len(ec.Y) != 43 || decode(ec.X).Cmp(ecKey.X) != 0 || decode(ec.Y).Cmp(ecKey.Y) != 0 {
+		t.Errorf("EC JWK x = %q, y = %q", ec.X, ec.Y)
+	}
+	if rs.Kty != "RSA" || rs.Alg != "RS256" || rs.Use != "sig" || rs.E != "AQAB" || rs.Crv != "" {
+		t.Errorf("RSA JWK = %+v", rs)
+	}
+	if decode(rs.N).Cmp(rsaKey.N) != 0 {
+		t.Errorf("RSA JWK n does not match the public key")
+	}
+}
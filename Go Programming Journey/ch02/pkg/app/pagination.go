@@ -18,11 +18,12 @@ func GetPage(c *gin.Context) int {
 func GetPageSize(c *gin.Context) int {
 	// 获取每页大小
 	pageSize := convert.StrTo(c.Query("page_size")).MustInt()
+	s := global.AppSetting()
 	if pageSize <= 0 {
-		return global.AppSetting.DefaultPageSize
+		return s.DefaultPageSize
 	}
-	if pageSize > global.AppSetting.MaxPageSize {
-		return global.AppSetting.MaxPageSize
+	if pageSize > s.MaxPageSize {
+		return s.MaxPageSize
 	}
 	return pageSize
 }
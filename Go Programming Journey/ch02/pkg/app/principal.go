@@ -0,0 +1,45 @@
+package app
+
+import "github.com/gin-gonic/gin"
+
+const (
+	principalKey = "principal"
+	claimsKey    = "claims"
+)
+
+func SetPrincipal(c *gin.Context, principal *Principal) {
+	c.Set(principalKey, principal)
+}
+
+// 获取当前请求的调用方，未经过 JWT 中间件时返回空的 Principal
+func GetPrincipal(c *gin.Context) *Principal {
+	if v, ok := c.Get(principalKey); ok {
+		if principal, ok := v.(*Principal); ok {
+			return principal
+		}
+	}
+	return &Principal{}
+}
+
+// 写入当前请求所使用的 access token 的声明，供注销时吊销该 token
+func SetClaims(c *gin.Context, claims *Claims) {
+	c.Set(claimsKey, claims)
+}
+
+// 未经过 JWT 中间件时返回 nil
+func GetClaims(c *gin.Context) *Claims {
+	if v, ok := c.Get(claimsKey); ok {
+		if claims, ok := v.(*Claims); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
+// 获取请求中的 token，优先使用 query 参数，其次为 token 头部
+func GetRequestToken(c *gin.Context) string {
+	if s, exist := c.GetQuery("token"); exist {
+		return s
+	}
+	return c.GetHeader("token")
+}
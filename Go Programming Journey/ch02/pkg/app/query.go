@@ -0,0 +1,233 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 筛选条件支持的操作符
+const (
+	OpEq   = "eq"
+	OpNe   = "ne"
+	OpGt   = "gt"
+	OpGte  = "gte"
+	OpLt   = "lt"
+	OpLte  = "lte"
+	OpLike = "like"
+	OpIn   = "in"
+)
+
+// 操作符对应的 SQL 比较符
+var operators = map[string]string{
+	OpEq:   "=",
+	OpNe:   "<>",
+	OpGt:   ">",
+	OpGte:  ">=",
+	OpLt:   "<",
+	OpLte:  "<=",
+	OpLike: "LIKE",
+	OpIn:   "IN",
+}
+
+// in 操作符最多允许的取值个数
+const maxInValues = 100
+
+// 匹配 filter[field] 与 filter[field][op]
+var filterKeyRegexp = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`)
+
+// QueryRule 为模型允许的查询白名单，字段名即数据库列名
+// 只有出现在白名单中的字段与操作符才会被拼接到 SQL 中
+type QueryRule struct {
+	// 允许筛选的字段及其支持的操作符
+	Filters map[string][]string
+	// 允许排序的字段
+	Sorts []string
+	// 允许通过 fields 返回的字段，对应 JSON 序列化后的字段名
+	Fields []string
+	// 是否支持游标分页，要求模型存在 created_on 与 id 字段
+	Keyset bool
+}
+
+type QuerySort struct {
+	Field string
+	Desc  bool
+}
+
+type QueryFilter struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// QuerySpec 为列表接口通用的查询参数
+// e.g. ?sort=-created_on,name&filter[created_on][gte]=1650000000&filter[name][like]=go&fields=id,name
+type QuerySpec struct {
+	Sorts   []QuerySort
+	Filters []QueryFilter
+	Fields  []string
+	// 游标分页的起始位置，第一页为 nil
+	Cursor *Cursor
+	keyset bool
+}
+
+// 从 QueryString 中解析查询参数并按白名单进行校验
+func BindQuerySpec(c *gin.Context, rule *QueryRule) (*QuerySpec, ValidErrors) {
+	var errs ValidErrors
+	var err error
+	spec := &QuerySpec{}
+	query := c.Request.URL.Query()
+
+	if s := query.Get("sort"); s != "" {
+		for _, field := range strings.Split(s, ",") {
+			field = strings.TrimSpace(field)
+			querySort := QuerySort{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+			if !contains(rule.Sorts, querySort.Field) {
+				errs = append(errs, &ValidError{Key: "sort", Message: fmt.Sprintf("不支持按 %s 排序", querySort.Field)})
+				continue
+			}
+			spec.Sorts = append(spec.Sorts, querySort)
+		}
+	}
+
+	// 对参数名排序，保证生成的 SQL 稳定
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		matches := filterKeyRegexp.FindStringSubmatch(key)
+		if matches == nil {
+			continue
+		}
+		filter := QueryFilter{Field: matches[1], Op: matches[2], Value: query.Get(key)}
+		if filter.Op == "" {
+			filter.Op = OpEq
+		}
+		ops, ok := rule.Filters[filter.Field]
+		if !ok {
+			errs = append(errs, &ValidError{Key: key, Message: fmt.Sprintf("不支持按 %s 筛选", filter.Field)})
+			continue
+		}
+		if !contains(ops, filter.Op) {
+			errs = append(errs, &ValidError{Key: key, Message: fmt.Sprintf("%s 不支持 %s 操作符", filter.Field, filter.Op)})
+			continue
+		}
+		if filter.Op == OpIn && len(strings.Split(filter.Value, ",")) > maxInValues {
+			errs = append(errs, &ValidError{Key: key, Message: fmt.Sprintf("%s 的取值不能超过 %d 个", filter.Field, maxInValues)})
+			continue
+		}
+		spec.Filters = append(spec.Filters, filter)
+	}
+
+	if s := query.Get("fields"); s != "" {
+		for _, field := range strings.Split(s, ",") {
+			field = strings.TrimSpace(field)
+			if !contains(rule.Fields, field) {
+				errs = append(errs, &ValidError{Key: "fields", Message: fmt.Sprintf("不支持返回 %s 字段", field)})
+				continue
+			}
+			spec.Fields = append(spec.Fields, field)
+		}
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		switch {
+		case !rule.Keyset:
+			errs = append(errs, &ValidError{Key: "cursor", Message: "不支持 cursor 分页"})
+		case len(spec.Sorts) > 0:
+			errs = append(errs, &ValidError{Key: "cursor", Message: "cursor 分页固定按 created_on、id 倒序排列，不能与 sort 同时使用"})
+		case cursor != "":
+			spec.Cursor, err = DecodeCursor(cursor)
+			if err != nil {
+				errs = append(errs, &ValidError{Key: "cursor", Message: err.Error()})
+			}
+		}
+		spec.keyset = true
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return spec, nil
+}
+
+// 筛选条件，字段名已经过白名单校验，取值通过占位符传入
+func (q *QuerySpec) Where(db *gorm.DB) *gorm.DB {
+	if q == nil {
+		return db
+	}
+	for _, filter := range q.Filters {
+		column := db.Dialect().Quote(filter.Field)
+		switch filter.Op {
+		case OpIn:
+			db = db.Where(column+" IN (?)", strings.Split(filter.Value, ","))
+		case OpLike:
+			db = db.Where(column+" LIKE ? ESCAPE '!'", "%"+escapeLike(filter.Value)+"%")
+		default:
+			db = db.Where(column+" "+operators[filter.Op]+" ?", filter.Value)
+		}
+	}
+	return db
+}
+
+// 排序条件，多个排序字段按传入顺序生效
+func (q *QuerySpec) Order(db *gorm.DB) *gorm.DB {
+	if q == nil {
+		return db
+	}
+	for _, querySort := range q.Sorts {
+		order := db.Dialect().Quote(querySort.Field)
+		if querySort.Desc {
+			order += " DESC"
+		}
+		db = db.Order(order)
+	}
+	return db
+}
+
+// 按 fields 参数裁剪列表中每一项返回的字段，未指定 fields 时原样返回
+func (q *QuerySpec) Project(list interface{}) (interface{}, error) {
+	if q == nil || len(q.Fields) == 0 {
+		return list, nil
+	}
+	body, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, err
+	}
+	result := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		projected := make(map[string]json.RawMessage, len(q.Fields))
+		for _, field := range q.Fields {
+			if value, ok := item[field]; ok {
+				projected[field] = value
+			}
+		}
+		result = append(result, projected)
+	}
+	return result, nil
+}
+
+// 转义 LIKE 中的通配符，避免用户输入的 % 与 _ 被当作通配符
+// 各数据库对反斜杠的处理不一致，统一使用 ! 作为转义字符
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+var testQueryRule = &QueryRule{
+	Filters: map[string][]string{
+		"name":       {OpEq, OpLike, OpIn},
+		"created_on": {OpGte, OpLt},
+	},
+	Sorts:  []string{"name", "created_on"},
+	Fields: []string{"id", "name"},
+	Keyset: true,
+}
+
+func bindQuery(query string, rule *QueryRule) (*QuerySpec, ValidErrors) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	return BindQuerySpec(c, rule)
+}
+
+func TestBindQuerySpec(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    *QuerySpec
+		wantErr bool
+	}{
+		{query: "", want: &QuerySpec{}},
+		{query: "sort=-created_on,%20name", want: &QuerySpec{Sorts: []QuerySort{{Field: "created_on", Desc: true}, {Field: "name"}}}},
+		{query: "sort=id", wantErr: true},
+		{query: "filter[name]=go&filter[created_on][gte]=1", want: &QuerySpec{Filters: []QueryFilter{
+			{Field: "created_on", Op: OpGte, Value: "1"},
+			{Field: "name", Op: OpEq, Value: "go"},
+		}}},
+		{query: "filter[state]=1", wantErr: true},
+		{query: "filter[name][gt]=a", wantErr: true},
+		{query: "filter[name][in]=" + strings.Repeat("a,", maxInValues) + "a", wantErr: true},
+		{query: "fields=id,name", want: &QuerySpec{Fields: []string{"id", "name"}}},
+		{query: "fields=content", wantErr: true},
+		{query: "cursor=", want: &QuerySpec{keyset: true}},
+		{query: "cursor=" + EncodeCursor(Cursor{CreatedOn: 10, ID: 3}), want: &QuerySpec{Cursor: &Cursor{CreatedOn: 10, ID: 3}, keyset: true}},
+		{query: "cursor=bad", wantErr: true},
+		{query: "cursor=" + EncodeCursor(Cursor{CreatedOn: 10}), wantErr: true},
+		{query: "cursor=&sort=name", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, errs := bindQuery(tt.query, testQueryRule)
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("%q: errs = %v, wantErr %v", tt.query, errs, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: spec = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+	if _, errs := bindQuery("cursor=", &QueryRule{}); len(errs) == 0 {
+		t.Error("cursor without Keyset: errs = nil")
+	}
+}
+
+type queryRow struct {
+	ID        uint32 `json:"id"`
+	Name      string `json:"name"`
+	CreatedOn uint32 `json:"created_on"`
+}
+
+func (queryRow) TableName() string {
+	return "query_row"
+}
+
+func newQueryDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("gorm.Open err: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.DB().SetMaxOpenConns(1)
+	if err := db.CreateTable(&queryRow{}).Error; err != nil {
+		t.Fatalf("CreateTable err: %v", err)
+	}
+	for _, row := range []queryRow{
+		{ID: 1, Name: "go", CreatedOn: 10},
+		{ID: 2, Name: "100%", CreatedOn: 20},
+		{ID: 3, Name: "a_b", CreatedOn: 20},
+		{ID: 4, Name: "axb", CreatedOn: 30},
+	} {
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatalf("Create err: %v", err)
+		}
+	}
+	return db
+}
+
+func rowIDs(rows []queryRow) []uint32 {
+	var ids []uint32
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	return ids
+}
+
+// 在 SQLite 中执行生成的筛选与排序条件
+func TestQuerySpecWhereOrder(t *testing.T) {
+	db := newQueryDB(t)
+	tests := []struct {
+		query string
+		want  []uint32
+	}{
+		{query: "sort=name", want: []uint32{2, 3, 4, 1}},
+		{query: "sort=-created_on,name", want: []uint32{4, 2, 3, 1}},
+		// LIKE 中的 % 与 _ 按普通字符匹配
+		{query: "filter[name][like]=%25", want: []uint32{2}},
+		{query: "filter[name][like]=_", want: []uint32{3}},
+		{query: "filter[name][in]=go,axb", want: []uint32{1, 4}},
+		{query: "filter[created_on][gte]=20&filter[created_on][lt]=30", want: []uint32{2, 3}},
+	}
+	for _, tt := range tests {
+		spec, errs := bindQuery(tt.query, testQueryRule)
+		if len(errs) > 0 {
+			t.Fatalf("%q: errs = %v", tt.query, errs)
+		}
+		var rows []queryRow
+		if err := db.Scopes(spec.Where, spec.Order).Order("id").Find(&rows).Error; err != nil {
+			t.Errorf("%q: Find err: %v", tt.query, err)
+			continue
+		}
+		if ids := rowIDs(rows); !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%q: ids = %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+}
+
+// 按游标逐页读取，created_on 相同时按 id 倒序
+func TestQuerySpecKeyset(t *testing.T) {
+	db := newQueryDB(t)
+	wantPages := [][]uint32{{4, 3}, {2, 1}}
+	cursor := ""
+	for i, want := range wantPages {
+		spec, errs := bindQuery("cursor="+cursor, testQueryRule)
+		if len(errs) > 0 {
+			t.Fatalf("page %d: errs = %v", i+1, errs)
+		}
+		var rows []queryRow
+		if err := db.Scopes(spec.Paginate(0, 2)).Find(&rows).Error; err != nil {
+			t.Fatalf("page %d: Find err: %v", i+1, err)
+		}
+		n, next := spec.NextCursor(len(rows), 2, func(i int) Cursor {
+			return Cursor{CreatedOn: rows[i].CreatedOn, ID: rows[i].ID}
+		})
+		if ids := rowIDs(rows[:n]); !reflect.DeepEqual(ids, want) {
+			t.Errorf("page %d: ids = %v, want %v", i+1, ids, want)
+		}
+		if last := i == len(wantPages)-1; last != (next == "") {
+			t.Errorf("page %d: next cursor = %q", i+1, next)
+		}
+		cursor = next
+	}
+}
+
+func TestQuerySpecPaginate(t *testing.T) {
+	db := newQueryDB(t)
+	var spec *QuerySpec
+	var rows []queryRow
+	if err := db.Scopes(spec.Paginate(1, 2)).Order("id").Find(&rows).Error; err != nil {
+		t.Fatalf("Find err: %v", err)
+	}
+	if ids := rowIDs(rows); !reflect.DeepEqual(ids, []uint32{2, 3}) {
+		t.Errorf("ids = %v, want [2 3]", ids)
+	}
+	if n, next := spec.NextCursor(3, 2, nil); n != 3 || next != "" {
+		t.Errorf("NextCursor without keyset = %d, %q", n, next)
+	}
+}
+
+func TestProject(t *testing.T) {
+	rows := []queryRow{{ID: 1, Name: "go", CreatedOn: 10}}
+	spec := &QuerySpec{Fields: []string{"id", "name"}}
+	got, err := spec.Project(rows)
+	if err != nil {
+		t.Fatalf("Project err: %v", err)
+	}
+	body, _ := json.Marshal(got)
+	if string(body) != `[{"id":1,"name":"go"}]` {
+		t.Errorf("Project = %s", body)
+	}
+	if got, _ := (&QuerySpec{}).Project(rows); !reflect.DeepEqual(got, rows) {
+		t.Errorf("Project without fields = %v", got)
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got, want := escapeLike("a!b%c_d"), "a!!b!%c!_d"; got != want {
+		t.Errorf("escapeLike = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 缓存中不存在或已过期
+var ErrNotFound = errors.New("cache: not found")
+
+// Cache 为缓存后端的通用接口，值为序列化后的字节，调用方修改读取到的值不会影响缓存
+type Cache interface {
+	// 缓存不存在或已过期时返回 ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// ttl 小于等于 0 时不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// 删除不存在的键不返回错误
+	Delete(ctx context.Context, keys ...string) error
+}
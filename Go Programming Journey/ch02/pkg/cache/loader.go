@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"golang.org/x/sync/singleflight"
+	"time"
+)
+
+// 各 Loader 的命中、未命中与错误次数，通过 /debug/vars 查看
+var stats = expvar.NewMap("cache")
+
+// Loader 在缓存未命中时加载数据并以 JSON 写入缓存，同一个键并发的未命中只加载一次
+type Loader struct {
+	group  singleflight.Group
+	hits   *expvar.Int
+	misses *expvar.Int
+	errors *expvar.Int // 访问缓存失败的次数，失败时直接加载数据
+}
+
+// name 为 /debug/vars 中 cache 下的指标名称，e.g. tag_list
+func NewLoader(name string) *Loader {
+	l := &Loader{hits: new(expvar.Int), misses: new(expvar.Int), errors: new(expvar.Int)}
+	m := new(expvar.Map).Init()
+	m.Set("hits", l.hits)
+	m.Set("misses", l.misses)
+	m.Set("errors", l.errors)
+	stats.Set(name, m)
+	return l
+}
+
+// 从缓存 c 中读取 key 并解析到 v，未命中时调用 load 加载并写入缓存，load 返回错误时不写入缓存
+// c 为 nil 时不使用缓存，每次都调用 load
+func (l *Loader) Load(ctx context.Context, c Cache, key string, ttl time.Duration, v interface{}, load func() (interface{}, error)) error {
+	if c == nil {
+		return l.load(ctx, nil, key, ttl, v, load)
+	}
+	value, err := c.Get(ctx, key)
+	if err == nil {
+		if err = json.Unmarshal(value, v); err == nil {
+			l.hits.Add(1)
+			return nil
+		}
+	}
+	if err == ErrNotFound {
+		l.misses.Add(1)
+	} else {
+		l.errors.Add(1)
+	}
+	return l.load(ctx, c, key, ttl, v, load)
+}
+
+func (l *Loader) load(ctx context.Context, c Cache, key string, ttl time.Duration, v interface{}, load func() (interface{}, error)) error {
+	value, err, _ := l.group.Do(key, func() (interface{}, error) {
+		data, err := load()
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			if err := c.Set(ctx, key, value, ttl); err != nil {
+				l.errors.Add(1)
+			}
+		}
+		return value, nil
+	})
+	if err != nil {
+		return err
+	}
+	// 共享同一次加载结果的调用方各自解析，互不影响
+	return json.Unmarshal(value.([]byte), v)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type item struct {
+	Name string
+}
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+	l := NewLoader("test")
+	loads := 0
+	load := func() (interface{}, error) {
+		loads++
+		return item{Name: "a"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		var v item
+		if err := l.Load(ctx, c, "k", time.Minute, &v, load); err != nil || v.Name != "a" {
+			t.Errorf("Load #%d = %+v, %v", i+1, v, err)
+		}
+	}
+	if loads != 1 || l.hits.Value() != 1 || l.misses.Value() != 1 {
+		t.Errorf("loads = %d, hits = %d, misses = %d, want 1, 1, 1", loads, l.hits.Value(), l.misses.Value())
+	}
+
+	// 缓存中的值无法解析时重新加载
+	c.Set(ctx, "k", []byte("{"), 0)
+	var v item
+	if err := l.Load(ctx, c, "k", time.Minute, &v, load); err != nil || v.Name != "a" || loads != 2 {
+		t.Errorf("Load with corrupt cache = %+v, %v, loads = %d", v, err, loads)
+	}
+}
+
+// load 返回错误时不写入缓存
+func TestLoaderError(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+	l := NewLoader("test_error")
+	errLoad := errors.New("load failed")
+	var v item
+	if err := l.Load(ctx, c, "k", time.Minute, &v, func() (interface{}, error) { return nil, errLoad }); err != errLoad {
+		t.Errorf("Load err = %v, want %v", err, errLoad)
+	}
+	if _, err := c.Get(ctx, "k"); err != ErrNotFound {
+		t.Errorf("Get err = %v, want ErrNotFound", err)
+	}
+}
+
+// 未配置缓存时每次都加载
+func TestLoaderWithoutCache(t *testing.T) {
+	l := NewLoader("test_nil")
+	loads := 0
+	for i := 0; i < 2; i++ {
+		var v item
+		l.Load(context.Background(), nil, "k", time.Minute, &v, func() (interface{}, error) {
+			loads++
+			return item{Name: "a"}, nil
+		})
+	}
+	if loads != 2 {
+		t.Errorf("loads = %d, want 2", loads)
+	}
+}
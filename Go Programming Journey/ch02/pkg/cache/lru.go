@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU 为进程内的缓存，超出容量时淘汰最久未访问的键
+// 删除操作仅在本进程内生效，部署多个服务实例时应使用 Redis
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List // 表头为最近访问的键
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time // 为零值时不过期
+}
+
+// size 为最多缓存的键数量
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expireAt.IsZero() && !time.Now().Before(entry.expireAt) {
+		c.remove(element)
+		return nil, ErrNotFound
+	}
+	c.ll.MoveToFront(element)
+	return append([]byte(nil), entry.value...), nil
+}
+
+func (c *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value = append([]byte(nil), value...)
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl)
+	}
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expireAt = value, expireAt
+		c.ll.MoveToFront(element)
+		return nil
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expireAt: expireAt})
+	for c.size > 0 && c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+	return nil
+}
+
+func (c *LRU) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.items[key]; ok {
+			c.remove(element)
+		}
+	}
+	return nil
+}
+
+// 调用方需持有锁
+func (c *LRU) remove(element *list.Element) {
+	c.ll.Remove(element)
+	delete(c.items, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), 0)
+	// 访问 a 后 b 成为最久未访问的键
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), 0)
+	// 更新已有的键不淘汰其他键
+	c.Set(ctx, "c", []byte("4"), 0)
+	c.Delete(ctx, "missing")
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr error
+	}{
+		{key: "a", want: "1"},
+		{key: "b", wantErr: ErrNotFound},
+		{key: "c", want: "4"},
+	}
+	for _, tt := range tests {
+		value, err := c.Get(ctx, tt.key)
+		if err != tt.wantErr || string(value) != tt.want {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, value, err, tt.want, tt.wantErr)
+		}
+	}
+
+	c.Delete(ctx, "a", "c")
+	if c.ll.Len() != 0 || len(c.items) != 0 {
+		t.Errorf("Delete left %d entries", c.ll.Len())
+	}
+}
+
+func TestLRUExpire(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(0)
+	c.Set(ctx, "short", []byte("1"), 20*time.Millisecond)
+	c.Set(ctx, "forever", []byte("2"), 0)
+	time.Sleep(30 * time.Millisecond)
+	if _, err := c.Get(ctx, "short"); err != ErrNotFound {
+		t.Errorf("Get expired key err = %v, want ErrNotFound", err)
+	}
+	if _, ok := c.items["short"]; ok {
+		t.Error("expired key was not removed")
+	}
+	if _, err := c.Get(ctx, "forever"); err != nil {
+		t.Errorf("Get key without ttl err = %v", err)
+	}
+}
+
+// 调用方修改写入或读取到的值不影响缓存
+func TestLRUCopy(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(1)
+	value := []byte("abc")
+	c.Set(ctx, "k", value, 0)
+	value[0] = 'x'
+	got, _ := c.Get(ctx, "k")
+	got[1] = 'x'
+	if got, _ := c.Get(ctx, "k"); string(got) != "abc" {
+		t.Errorf("Get = %q, want %q", got, "abc")
+	}
+}
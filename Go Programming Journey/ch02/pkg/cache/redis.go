@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"sync"
+	"time"
+)
+
+// Redis 不可用后直接返回错误，每隔该时间重新尝试访问 Redis，避免每次请求都等待连接超时
+const DefaultRetryInterval = 5 * time.Second
+
+var errUnavailable = errors.New("cache: redis unavailable")
+
+// Redis 使用 Redis 保存缓存，多个服务实例共享同一份缓存，删除操作对所有实例生效
+type Redis struct {
+	client        redis.Cmdable
+	prefix        string
+	retryInterval time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// prefix 为缓存在 Redis 中的键前缀，e.g. blog-service:cache:
+func NewRedis(client redis.Cmdable, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix, retryInterval: DefaultRetryInterval}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	if !c.available() {
+		return nil, errUnavailable
+	}
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	return value, c.check(err)
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if !c.available() {
+		return errUnavailable
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+	return c.check(c.client.Set(ctx, c.prefix+key, value, ttl).Err())
+}
+
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	// 删除失败会使缓存在过期前保持旧值，因此 Redis 被标记为不可用时仍然尝试删除
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.check(c.client.Del(ctx, prefixed...).Err())
+}
+
+func (c *Redis) available() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !time.Now().Before(c.downUntil)
+}
+
+// 连接类错误时在 retryInterval 内不再访问 Redis
+func (c *Redis) check(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return err
+	}
+	c.mu.Lock()
+	c.downUntil = time.Now().Add(c.retryInterval)
+	c.mu.Unlock()
+	return fmt.Errorf("cache: %w", err)
+}
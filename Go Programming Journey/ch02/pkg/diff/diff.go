@@ -0,0 +1,159 @@
+package diff
+
+import "strings"
+
+// 差异类型
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Line 为差异结果中的一行，OldLine、NewLine 为从 1 开始的行号，不存在时为 0
+type Line struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// 差异统计
+type Stat struct {
+	Insertions int `json:"insertions"`
+	Deletions  int `json:"deletions"`
+}
+
+// 最短编辑脚本中编辑次数的上限，回溯需要保存每一步的状态，内存占用与编辑次数的平方成正比
+// 超过上限时不再寻找最短编辑脚本，保留首尾相同的行，中间不同的部分整体作为删除与新增
+const MaxEdits = 1000
+
+// 按行比较两段文本，使用 Myers 差分算法得到最短编辑脚本
+func Lines(a, b string) []Line {
+	return diff(splitLines(a), splitLines(b))
+}
+
+// 统计新增与删除的行数
+func Count(lines []Line) Stat {
+	var stat Stat
+	for _, line := range lines {
+		switch line.Op {
+		case OpInsert:
+			stat.Insertions++
+		case OpDelete:
+			stat.Deletions++
+		}
+	}
+	return stat
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func diff(a, b []string) []Line {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	// v[k] 为对角线 k 上能到达的最远 x，trace 保存每一步开始前 v 中 [-d-1, d+1] 的部分用于回溯
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	for d := 0; d <= max && d <= MaxEdits; d++ {
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return replace(a, b)
+}
+
+// 不计算最短编辑脚本，将首尾相同的行之外的部分整体替换
+func replace(a, b []string) []Line {
+	n, m := len(a), len(b)
+	prefix := 0
+	for prefix < n && prefix < m && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && a[n-suffix-1] == b[m-suffix-1] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, n+m-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: OpEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+	for i := prefix; i < n-suffix; i++ {
+		lines = append(lines, Line{Op: OpDelete, Text: a[i], OldLine: i + 1})
+	}
+	for j := prefix; j < m-suffix; j++ {
+		lines = append(lines, Line{Op: OpInsert, Text: b[j], NewLine: j + 1})
+	}
+	for i := suffix; i > 0; i-- {
+		lines = append(lines, Line{Op: OpEqual, Text: a[n-i], OldLine: n - i + 1, NewLine: m - i + 1})
+	}
+	return lines
+}
+
+// 从终点沿 trace 回溯得到编辑脚本
+func backtrack(a, b []string, trace [][]int) []Line {
+	x, y := len(a), len(b)
+	var lines []Line
+	for d := len(trace) - 1; d >= 0; d-- {
+		// trace[d] 中下标 i 对应对角线 i-d-1
+		v, offset := trace[d], d+1
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, Line{Op: OpEqual, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, Line{Op: OpInsert, Text: b[y-1], NewLine: y})
+			} else {
+				lines = append(lines, Line{Op: OpDelete, Text: a[x-1], OldLine: x})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	// 回溯得到的结果为倒序
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 将差异结果按 " a"、"+b"、"-c" 的格式连接，便于比较
+func format(lines []Line) string {
+	prefix := map[string]string{OpEqual: " ", OpInsert: "+", OpDelete: "-"}
+	var parts []string
+	for _, line := range lines {
+		parts = append(parts, prefix[line.Op]+line.Text)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "empty", a: "", b: "", want: ""},
+		{name: "insert all", a: "", b: "a\nb", want: "+a,+b"},
+		{name: "delete all", a: "a\nb\n", b: "", want: "-a,-b"},
+		{name: "equal", a: "a\nb", b: "a\nb\n", want: " a, b"},
+		{name: "crlf", a: "a\r\nb", b: "a\nb", want: " a, b"},
+		{name: "replace", a: "a\nb\nc", b: "a\nx\nc", want: " a,-b,+x, c"},
+		{name: "append", a: "a", b: "a\nb", want: " a,+b"},
+	}
+	for _, tt := range tests {
+		if got := format(Lines(tt.a, tt.b)); got != tt.want {
+			t.Errorf("%s: Lines = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 结果中的相同行与删除行组成原文，相同行与新增行组成新文，行号连续
+func checkScript(t *testing.T, a, b string, lines []Line) {
+	t.Helper()
+	var oldLines, newLines []string
+	for _, line := range lines {
+		if line.Op != OpInsert {
+			oldLines = append(oldLines, line.Text)
+			if line.OldLine != len(oldLines) {
+				t.Errorf("%.20q -> %.20q: OldLine = %d, want %d", a, b, line.OldLine, len(oldLines))
+			}
+		}
+		if line.Op != OpDelete {
+			newLines = append(newLines, line.Text)
+			if line.NewLine != len(newLines) {
+				t.Errorf("%.20q -> %.20q: NewLine = %d, want %d", a, b, line.NewLine, len(newLines))
+			}
+		}
+	}
+	if got := strings.Join(oldLines, "\n"); got != a {
+		t.Errorf("%.20q -> %.20q: old text = %.20q", a, b, got)
+	}
+	if got := strings.Join(newLines, "\n"); got != b {
+		t.Errorf("%.20q -> %.20q: new text = %.20q", a, b, got)
+	}
+}
+
+// 编辑次数最少
+func TestLinesScript(t *testing.T) {
+	tests := []struct {
+		a, b      string
+		wantEdits int
+	}{
+		{a: "A\nB\nC\nA\nB\nB\nA", b: "C\nB\nA\nB\nA\nC", wantEdits: 5},
+		{a: "a\nb\nc\nd", b: "d\nc\nb\na", wantEdits: 6},
+		{a: "x\ny", b: "y\nx\ny", wantEdits: 1},
+	}
+	for _, tt := range tests {
+		lines := Lines(tt.a, tt.b)
+		checkScript(t, tt.a, tt.b, lines)
+		stat := Count(lines)
+		if edits := stat.Insertions + stat.Deletions; edits != tt.wantEdits {
+			t.Errorf("%q -> %q: edits = %d, want %d", tt.a, tt.b, edits, tt.wantEdits)
+		}
+	}
+}
+
+func numberedLines(prefix string, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = prefix + strconv.Itoa(i)
+	}
+	return lines
+}
+
+// 编辑次数超过上限时保留首尾相同的行，中间部分整体替换
+func TestLinesMaxEdits(t *testing.T) {
+	head, tail := numberedLines("head", 3), numberedLines("tail", 2)
+	tests := []struct {
+		name           string
+		a, b           []string
+		wantInsertions int
+		wantDeletions  int
+	}{
+		{name: "within limit", a: numberedLines("a", MaxEdits/2), b: numberedLines("b", MaxEdits/2), wantInsertions: MaxEdits / 2, wantDeletions: MaxEdits / 2},
+		{name: "over limit", a: numberedLines("a", MaxEdits), b: numberedLines("b", MaxEdits), wantInsertions: MaxEdits, wantDeletions: MaxEdits},
+		{
+			name:           "common prefix and suffix",
+			a:              append(append(append([]string{}, head...), numberedLines("a", MaxEdits)...), tail...),
+			b:              append(append(append([]string{}, head...), numberedLines("b", MaxEdits+1)...), tail...),
+			wantInsertions: MaxEdits + 1,
+			wantDeletions:  MaxEdits,
+		},
+		{name: "insert only", a: nil, b: numberedLines("b", MaxEdits+1), wantInsertions: MaxEdits + 1},
+	}
+	for _, tt := range tests {
+		a, b := strings.Join(tt.a, "\n"), strings.Join(tt.b, "\n")
+		lines := Lines(a, b)
+		checkScript(t, a, b, lines)
+		if stat := Count(lines); stat.Insertions != tt.wantInsertions || stat.Deletions != tt.wantDeletions {
+			t.Errorf("%s: Count = %+v, want %d insertions, %d deletions", tt.name, stat, tt.wantInsertions, tt.wantDeletions)
+		}
+	}
+}
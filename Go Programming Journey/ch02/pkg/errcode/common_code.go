@@ -10,4 +10,7 @@ var (
 	UnauthorizedTokenTimeout  = NewError(10000005, "鉴权失败，Token 超时")
 	UnauthorizedTokenGenerate = NewError(10000006, "鉴权失败，Token 生成失败")
 	TooManyRequests           = NewError(10000007, "请求过多")
+	Forbidden                 = NewError(10000008, "没有访问权限")
+	UnauthorizedUserError     = NewError(10000009, "鉴权失败，用户名或密码错误")
+	PreconditionFailed        = NewError(10000010, "资源已被修改，请重新获取后再更新")
 )
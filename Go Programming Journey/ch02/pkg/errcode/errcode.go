@@ -6,9 +6,9 @@ import (
 )
 
 type Error struct {
-	code    int      `json:"code"`
-	msg     string   `json:"msg"`
-	details []string `json:"details"`
+	code    int
+	msg     string
+	details []string
 }
 
 var codes = map[int]string{}
@@ -60,6 +60,8 @@ func (e *Error) StatusCode() int {
 		return http.StatusInternalServerError
 	case InvalidParams.Code():
 		return http.StatusBadRequest
+	case NotFound.Code():
+		return http.StatusNotFound
 	case UnauthorizedAuthNotExist.Code():
 		fallthrough
 	case UnauthorizedTokenError.Code():
@@ -67,9 +69,23 @@ func (e *Error) StatusCode() int {
 	case UnauthorizedTokenGenerate.Code():
 		fallthrough
 	case UnauthorizedTokenTimeout.Code():
+		fallthrough
+	case UnauthorizedUserError.Code():
 		return http.StatusUnauthorized
+	case Forbidden.Code():
+		return http.StatusForbidden
 	case TooManyRequests.Code():
 		return http.StatusTooManyRequests
+	case PreconditionFailed.Code():
+		return http.StatusPreconditionFailed
+	case ErrorArticleStateTransition.Code():
+		return http.StatusConflict
+	case ErrorUploadFileTooLarge.Code():
+		return http.StatusRequestEntityTooLarge
+	case ErrorUploadFileType.Code():
+		return http.StatusUnsupportedMediaType
+	case ErrorUploadImageSize.Code():
+		return http.StatusBadRequest
 	}
 	return http.StatusInternalServerError
 }
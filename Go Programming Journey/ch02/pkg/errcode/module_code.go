@@ -6,12 +6,39 @@ var (
 	ErrorUpdateTagFail  = NewError(20010003, "更新标签失败")
 	ErrorDeleteTagFail  = NewError(20010004, "删除标签失败")
 	ErrorCountTagFail   = NewError(20010005, "统计标签失败")
+	ErrorGetTagFail     = NewError(20010006, "获取标签失败")
 
 	ErrorGetArticleFail    = NewError(20020001, "获取单个文章失败")
 	ErrorGetArticlesFail   = NewError(20020002, "获取多个文章失败")
 	ErrorCreateArticleFail = NewError(20020003, "创建文章失败")
 	ErrorUpdateArticleFail = NewError(20020004, "更新文章失败")
 	ErrorDeleteArticleFail = NewError(20020005, "删除文章失败")
+	ErrorSearchArticleFail = NewError(20020006, "搜索文章失败")
 
-	ErrorUploadFileFail = NewError(20030001, "上传文件失败")
+	ErrorGetArticleRevisionsFail    = NewError(20020007, "获取文章修订历史失败")
+	ErrorGetArticleRevisionFail     = NewError(20020008, "获取文章修订版本失败")
+	ErrorDiffArticleRevisionFail    = NewError(20020009, "比较文章修订版本失败")
+	ErrorRestoreArticleRevisionFail = NewError(20020010, "恢复文章修订版本失败")
+	ErrorArticleStateTransition     = NewError(20020011, "文章状态变更不合法")
+
+	ErrorUploadFileFail     = NewError(20030001, "上传文件失败")
+	ErrorUploadFileTooLarge = NewError(20030002, "上传文件超过大小限制")
+	ErrorUploadFileType     = NewError(20030003, "上传文件类型不支持")
+	ErrorUploadImageSize    = NewError(20030004, "上传图片尺寸超过限制")
+
+	ErrorGetCommentListFail           = NewError(20040001, "获取评论列表失败")
+	ErrorCreateCommentFail            = NewError(20040002, "发表评论失败")
+	ErrorDeleteCommentFail            = NewError(20040003, "删除评论失败")
+	ErrorGetCommentModerationListFail = NewError(20040004, "获取评论审核队列失败")
+	ErrorModerateCommentFail          = NewError(20040005, "审核评论失败")
+
+	ErrorGetUserListFail = NewError(20050001, "获取用户列表失败")
+	ErrorCreateUserFail  = NewError(20050002, "创建用户失败")
+	ErrorUpdateUserFail  = NewError(20050003, "更新用户失败")
+	ErrorDeleteUserFail  = NewError(20050004, "删除用户失败")
+
+	ErrorGetAppKeyListFail = NewError(20060001, "获取 app_key 列表失败")
+	ErrorCreateAppKeyFail  = NewError(20060002, "创建 app_key 失败")
+	ErrorUpdateAppKeyFail  = NewError(20060003, "更新 app_key 失败")
+	ErrorRotateAppKeyFail  = NewError(20060004, "轮换 app_key 的 secret 失败")
 )
@@ -0,0 +1,72 @@
+package limiter
+
+import (
+	"time"
+)
+
+// 限流算法，未配置时为令牌桶
+// 各算法均使用 LimiterBucketRule 中的参数:
+//   - token_bucket: 容量为 Capacity 的令牌桶，每隔 FillInterval 放入 Quantum 个令牌
+//   - sliding_window_log: 记录每次请求的时间，任意 FillInterval 时间内最多消耗 Capacity 个令牌
+//   - sliding_window_counter: 按上一个窗口的计数加权估算，任意 FillInterval 时间内约消耗 Capacity 个令牌
+//   - gcra: 通用信元速率算法，每隔 FillInterval 允许 Quantum 个令牌，最多突发 Capacity 个令牌
+const (
+	AlgorithmTokenBucket          = "token_bucket"
+	AlgorithmSlidingWindowLog     = "sliding_window_log"
+	AlgorithmSlidingWindowCounter = "sliding_window_counter"
+	AlgorithmGCRA                 = "gcra"
+)
+
+// Bucket 为限流算法的通用接口，实现需并发安全
+type Bucket interface {
+	// 尝试消耗 cost 个令牌，不会阻塞，令牌不足时不消耗任何令牌
+	// cost 为 0 时仅返回当前的限流状态
+	Take(cost int64) Result
+}
+
+// Result 为一次 Take 的结果，用于生成 RateLimit-Limit、RateLimit-Remaining 与 Retry-After 响应头
+type Result struct {
+	Allowed    bool
+	Limit      int64         // 令牌总数
+	Remaining  int64         // 本次消耗后剩余的令牌数
+	RetryAfter time.Duration // 被拒绝时距离令牌足够所需的时间
+}
+
+// 根据规则中的算法创建 Bucket，未知的算法按令牌桶处理
+func NewBucket(rule LimiterBucketRule) Bucket {
+	switch rule.Algorithm {
+	case AlgorithmSlidingWindowLog:
+		return newSlidingWindowLog(rule.FillInterval, rule.Capacity)
+	case AlgorithmSlidingWindowCounter:
+		return newSlidingWindowCounter(rule.FillInterval, rule.Capacity)
+	case AlgorithmGCRA:
+		return newGCRA(rule.FillInterval, rule.Capacity, rule.Quantum)
+	default:
+		return newTokenBucket(rule.FillInterval, rule.Capacity, rule.Quantum)
+	}
+}
+
+// weightedBucket 使每次请求消耗 cost 个令牌，用于开销不同的路由
+type weightedBucket struct {
+	Bucket
+	cost int64
+}
+
+func (b weightedBucket) Take(n int64) Result {
+	return b.Bucket.Take(n * b.cost)
+}
+
+// cost 小于等于 1 时返回原 Bucket
+func withCost(bucket Bucket, cost int64) Bucket {
+	if cost <= 1 {
+		return bucket
+	}
+	return weightedBucket{Bucket: bucket, cost: cost}
+}
+
+func positive(n int64) int64 {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,155 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+)
+
+var algorithms = []string{
+	AlgorithmTokenBucket,
+	AlgorithmSlidingWindowLog,
+	AlgorithmSlidingWindowCounter,
+	AlgorithmGCRA,
+}
+
+func TestBucketTake(t *testing.T) {
+	tests := []struct {
+		cost          int64
+		wantAllowed   bool
+		wantRemaining int64
+	}{
+		{cost: 0, wantAllowed: true, wantRemaining: 3},
+		{cost: 1, wantAllowed: true, wantRemaining: 2},
+		{cost: 3, wantAllowed: false, wantRemaining: 2},
+		{cost: 2, wantAllowed: true, wantRemaining: 0},
+		{cost: 1, wantAllowed: false, wantRemaining: 0},
+		{cost: 0, wantAllowed: true, wantRemaining: 0},
+	}
+	for _, algorithm := range algorithms {
+		bucket := NewBucket(LimiterBucketRule{Algorithm: algorithm, FillInterval: time.Hour, Capacity: 3, Quantum: 3})
+		for i, tt := range tests {
+			result := bucket.Take(tt.cost)
+			if result.Allowed != tt.wantAllowed || result.Remaining != tt.wantRemaining || result.Limit != 3 {
+				t.Errorf("%s: Take #%d(%d) = %+v, want Allowed %v Remaining %d",
+					algorithm, i+1, tt.cost, result, tt.wantAllowed, tt.wantRemaining)
+			}
+			// 滑动窗口计数需等当前窗口的计数在下一个窗口内衰减，最长为两个窗口
+			if !result.Allowed && (result.RetryAfter <= 0 || result.RetryAfter > 2*time.Hour) {
+				t.Errorf("%s: Take #%d RetryAfter = %v, want (0, 2h]", algorithm, i+1, result.RetryAfter)
+			}
+			if result.Allowed && result.RetryAfter != 0 {
+				t.Errorf("%s: Take #%d RetryAfter = %v, want 0", algorithm, i+1, result.RetryAfter)
+			}
+		}
+	}
+}
+
+// 等待 RetryAfter 后令牌足够
+func TestBucketRetryAfter(t *testing.T) {
+	for _, algorithm := range algorithms {
+		bucket := NewBucket(LimiterBucketRule{Algorithm: algorithm, FillInterval: 50 * time.Millisecond, Capacity: 2, Quantum: 2})
+		bucket.Take(2)
+		result := bucket.Take(1)
+		if result.Allowed {
+			t.Errorf("%s: Take on an empty bucket allowed", algorithm)
+			continue
+		}
+		time.Sleep(result.RetryAfter + 5*time.Millisecond)
+		if result := bucket.Take(1); !result.Allowed {
+			t.Errorf("%s: Take after RetryAfter = %+v, want allowed", algorithm, result)
+		}
+	}
+}
+
+func TestSlidingWindowLogRetryAfter(t *testing.T) {
+	b := newSlidingWindowLog(time.Hour, 3)
+	start := time.Now()
+	b.entries = []logEntry{
+		{at: start.Add(-50 * time.Minute), cost: 1},
+		{at: start.Add(-40 * time.Minute), cost: 2},
+	}
+	b.used = 3
+	tests := []struct {
+		cost int64
+		want time.Duration
+	}{
+		{cost: 1, want: 10 * time.Minute},
+		{cost: 2, want: 20 * time.Minute},
+		{cost: 3, want: 20 * time.Minute},
+	}
+	for _, tt := range tests {
+		got := b.Take(tt.cost).RetryAfter
+		if got > tt.want || got < tt.want-time.Second {
+			t.Errorf("Take(%d) RetryAfter = %v, want about %v", tt.cost, got, tt.want)
+		}
+	}
+}
+
+func TestSlidingWindowCounterWeight(t *testing.T) {
+	b := newSlidingWindowCounter(time.Hour, 10)
+	// 进入当前窗口 45 分钟，上一个窗口计数的 1/4 仍计入滑动窗口
+	b.start = time.Now().Add(-45 * time.Minute)
+	b.prev = 8
+	b.curr = 4
+	if result := b.Take(0); result.Remaining != 4 {
+		t.Errorf("Take(0) Remaining = %d, want 4", result.Remaining)
+	}
+	result := b.Take(5)
+	if result.Allowed {
+		t.Fatalf("Take(5) allowed with 6 used of 10")
+	}
+	// 8*(60-t)/60 + 4 + 5 <= 10 => t >= 52.5 分钟
+	want := 7*time.Minute + 30*time.Second
+	if got := result.RetryAfter; got > want || got < want-time.Second {
+		t.Errorf("Take(5) RetryAfter = %v, want about %v", got, want)
+	}
+	// 超过两个窗口后计数清零
+	b.start = time.Now().Add(-2 * time.Hour)
+	if result := b.Take(10); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Take(10) after two windows = %+v, want allowed", result)
+	}
+}
+
+func TestGCRARate(t *testing.T) {
+	// 每小时 6 个令牌，最多突发 3 个
+	b := newGCRA(time.Hour, 3, 6)
+	for i := 0; i < 3; i++ {
+		b.Take(1)
+	}
+	result := b.Take(1)
+	if result.Allowed {
+		t.Fatal("Take after the burst allowed")
+	}
+	if want := 10 * time.Minute; result.RetryAfter > want || result.RetryAfter < want-time.Second {
+		t.Errorf("RetryAfter = %v, want about %v", result.RetryAfter, want)
+	}
+}
+
+func TestWithCost(t *testing.T) {
+	tests := []struct {
+		cost     int64
+		wrapped  bool
+		takes    int
+		wantLast int64
+	}{
+		{cost: 0, wrapped: false, takes: 1, wantLast: 9},
+		{cost: 1, wrapped: false, takes: 2, wantLast: 8},
+		{cost: 3, wrapped: true, takes: 3, wantLast: 1},
+	}
+	for _, tt := range tests {
+		bucket := withCost(newGCRA(time.Hour, 10, 10), tt.cost)
+		if _, ok := bucket.(weightedBucket); ok != tt.wrapped {
+			t.Errorf("withCost(%d) wrapped = %v, want %v", tt.cost, ok, tt.wrapped)
+		}
+		var result Result
+		for i := 0; i < tt.takes; i++ {
+			result = bucket.Take(1)
+		}
+		if !result.Allowed || result.Remaining != tt.wantLast {
+			t.Errorf("withCost(%d): Take = %+v, want Remaining %d", tt.cost, result, tt.wantLast)
+		}
+		if tt.wrapped && bucket.Take(1).Allowed {
+			t.Errorf("withCost(%d): Take with too few tokens allowed", tt.cost)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package limiter
+
+import (
+	"demo/ch02/pkg/app"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 令牌桶默认的闲置清理时间
+const DefaultBucketTTL = 10 * time.Minute
+
+// KeyFunc 从请求中提取客户端标识，无法识别客户端时返回空字符串
+type KeyFunc func(c *gin.Context) string
+
+// 按客户端 IP 区分，代理头部仅在请求来自 gin 配置的可信代理时生效，见 gin.Engine.SetTrustedProxies
+func ClientIP() KeyFunc {
+	return func(c *gin.Context) string {
+		return c.ClientIP()
+	}
+}
+
+// 按 JWT 中的调用方区分，app_key 认证时为 app_key，用户登录时为用户名
+// 在 JWT 中间件之前执行时自行校验请求中的 token，token 无效时返回空字符串
+func AppKey() KeyFunc {
+	return func(c *gin.Context) string {
+		if name := app.GetPrincipal(c).Name; name != "" {
+			return name
+		}
+		token := app.GetRequestToken(c)
+		if token == "" {
+			return ""
+		}
+		claims, err := app.ParseToken(token)
+		if err != nil || claims.TokenType != app.TokenTypeAccess {
+			return ""
+		}
+		return claims.Name
+	}
+}
+
+// 组合多个标识，任一标识为空时使用 - 占位，全部为空时返回空字符串
+func Composite(keyFuncs ...KeyFunc) KeyFunc {
+	return func(c *gin.Context) string {
+		parts := make([]string, len(keyFuncs))
+		empty := true
+		for i, keyFunc := range keyFuncs {
+			parts[i] = keyFunc(c)
+			if parts[i] != "" {
+				empty = false
+			} else {
+				parts[i] = "-"
+			}
+		}
+		if empty {
+			return ""
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// 按客户端划分的令牌桶，令牌桶在客户端首次请求时创建，闲置超过 TTL 后清理，避免客户端过多时占用的内存无限增长
+type clientBuckets struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+type clientBucket struct {
+	bucket   Bucket
+	rule     RouteRule     // 创建令牌桶的规则
+	idle     time.Duration // 闲置多久后清理，不小于令牌桶从空到满所需的时间
+	lastSeen time.Time
+}
+
+// 每隔 TTL 清理一次闲置的令牌桶，调用方需持有锁
+func (b *clientBuckets) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < b.ttl {
+		return
+	}
+	for key, entry := range b.entries {
+		if now.Sub(entry.lastSeen) >= entry.idle {
+			delete(b.entries, key)
+		}
+	}
+	b.lastSweep = now
+}
+
+// 令牌桶从空到满所需的时间，滑动窗口算法为已消耗的令牌全部滑出窗口所需的时间
+func refillDuration(rule LimiterBucketRule) time.Duration {
+	switch rule.Algorithm {
+	case AlgorithmSlidingWindowLog:
+		return rule.FillInterval
+	case AlgorithmSlidingWindowCounter:
+		// 上一个窗口的计数在之后的一个窗口内仍会参与估算
+		return 2 * rule.FillInterval
+	}
+	quantum := rule.Quantum
+	if rule.Algorithm == AlgorithmGCRA && quantum <= 0 {
+		quantum = rule.Capacity
+	}
+	if quantum <= 0 {
+		return 0
+	}
+	fills := (rule.Capacity + quantum - 1) / quantum
+	return time.Duration(fills) * rule.FillInterval
+}
@@ -0,0 +1,215 @@
+package limiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"demo/ch02/global"
+	"demo/ch02/pkg/app"
+	"demo/ch02/pkg/setting"
+	"github.com/gin-gonic/gin"
+)
+
+// 代理头部仅在请求来自可信代理时生效
+func TestClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		trusted    []string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "no proxy", remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+		{name: "untrusted proxy", remoteAddr: "192.0.2.1:1234", forwarded: "198.51.100.7", want: "192.0.2.1"},
+		{name: "trusted proxy", trusted: []string{"192.0.2.0/24"}, remoteAddr: "192.0.2.1:1234", forwarded: "198.51.100.7", want: "198.51.100.7"},
+		// 最右侧的不可信地址为客户端地址，客户端自行添加的地址无效
+		{name: "spoofed header", trusted: []string{"192.0.2.1"}, remoteAddr: "192.0.2.1:1234", forwarded: "203.0.113.9, 198.51.100.7", want: "198.51.100.7"},
+		{name: "other proxy", trusted: []string{"192.0.2.1"}, remoteAddr: "192.0.2.2:1234", forwarded: "198.51.100.7", want: "192.0.2.2"},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		if err := r.SetTrustedProxies(tt.trusted); err != nil {
+			t.Fatalf("%s: SetTrustedProxies err: %v", tt.name, err)
+		}
+		var got string
+		r.GET("/", func(c *gin.Context) { got = ClientIP()(c) })
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if got != tt.want {
+			t.Errorf("%s: ClientIP = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAppKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	oldSettings := global.LoadSettings()
+	t.Cleanup(func() { global.StoreSettings(oldSettings) })
+	global.UpdateSettings(func(s *global.Settings) {
+		s.JWT = &setting.JWTSettingS{Secret: "secret", Expire: time.Hour, RefreshExpire: time.Hour}
+	})
+	pair, err := app.GenerateTokenPair(app.Principal{Name: "reader", Role: "app"})
+	if err != nil {
+		t.Fatalf("GenerateTokenPair err: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		principal *app.Principal
+		token     string
+		want      string
+	}{
+		// 在 JWT 中间件之后使用已写入上下文的调用方
+		{name: "principal", principal: &app.Principal{Name: "admin"}, token: pair.AccessToken, want: "admin"},
+		{name: "access token", token: pair.AccessToken, want: "reader"},
+		{name: "refresh token", token: pair.RefreshToken, want: ""},
+		{name: "invalid token", token: "token", want: ""},
+		{name: "no token", want: ""},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.token != "" {
+			c.Request.Header.Set("token", tt.token)
+		}
+		if tt.principal != nil {
+			app.SetPrincipal(c, tt.principal)
+		}
+		if got := AppKey()(c); got != tt.want {
+			t.Errorf("%s: AppKey = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComposite(t *testing.T) {
+	key := func(s string) KeyFunc {
+		return func(*gin.Context) string { return s }
+	}
+	tests := []struct {
+		name     string
+		keyFuncs []KeyFunc
+		want     string
+	}{
+		{name: "all", keyFuncs: []KeyFunc{key("admin"), key("192.0.2.1")}, want: "admin|192.0.2.1"},
+		{name: "first empty", keyFuncs: []KeyFunc{key(""), key("192.0.2.1")}, want: "-|192.0.2.1"},
+		{name: "last empty", keyFuncs: []KeyFunc{key("admin"), key("")}, want: "admin|-"},
+		{name: "all empty", keyFuncs: []KeyFunc{key(""), key("")}, want: ""},
+		{name: "none", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Composite(tt.keyFuncs...)(nil); got != tt.want {
+			t.Errorf("%s: Composite = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 闲置的令牌桶每隔 TTL 清理一次，未填满的令牌桶保留到填满为止
+func TestClientBucketsSweep(t *testing.T) {
+	l := NewRouteLimiter()
+	err := l.SetRules([]RouteRule{
+		{Path: "/fast", FillInterval: time.Second, Capacity: 10},
+		// 每小时填充 1 个令牌，从空到满需要 10 小时
+		{Path: "/slow", FillInterval: time.Hour, Capacity: 10, Quantum: 1},
+	})
+	if err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	fast, slow, recent := routeRuleKey("", "/fast")+"\ta", routeRuleKey("", "/slow")+"\ta", routeRuleKey("", "/fast")+"\tb"
+	for _, key := range []string{fast, slow, recent} {
+		if _, ok := l.GetBucket(key); !ok {
+			t.Fatalf("GetBucket(%q) not found", key)
+		}
+	}
+
+	start := l.buckets.lastSweep
+	tests := []struct {
+		name   string
+		after  time.Duration
+		seen   []string // 清理前访问过的令牌桶
+		remain []string
+	}{
+		// 距上次清理不足 TTL 时不清理
+		{name: "before ttl", after: DefaultBucketTTL - time.Second, seen: []string{recent}, remain: []string{fast, slow, recent}},
+		{name: "after ttl", after: DefaultBucketTTL + time.Second, remain: []string{slow, recent}},
+		{name: "after refill", after: 11 * time.Hour, remain: nil},
+	}
+	for _, tt := range tests {
+		now := start.Add(tt.after)
+		l.buckets.mu.Lock()
+		for _, key := range tt.seen {
+			l.buckets.entries[key].lastSeen = now
+		}
+		l.buckets.sweep(now)
+		remain := make(map[string]bool, len(l.buckets.entries))
+		for key := range l.buckets.entries {
+			remain[key] = true
+		}
+		l.buckets.mu.Unlock()
+
+		if len(remain) != len(tt.remain) {
+			t.Errorf("%s: %d buckets remain, want %v", tt.name, len(remain), tt.remain)
+		}
+		for _, key := range tt.remain {
+			if !remain[key] {
+				t.Errorf("%s: bucket %q was swept", tt.name, key)
+			}
+		}
+	}
+}
+
+// 未携带有效 token 的请求按客户端 IP 区分，不会绕过限流
+func TestRouteLimiterKeyByAppKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	oldSettings := global.LoadSettings()
+	t.Cleanup(func() { global.StoreSettings(oldSettings) })
+	global.UpdateSettings(func(s *global.Settings) {
+		s.JWT = &setting.JWTSettingS{Secret: "secret", Expire: time.Hour}
+	})
+	token, err := app.GenerateToken(app.Principal{Name: "reader", Role: "app"}, app.TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("GenerateToken err: %v", err)
+	}
+
+	l := NewRouteLimiter()
+	err = l.SetRules([]RouteRule{
+		{Path: "/app", FillInterval: time.Second, Capacity: 1, KeyBy: KeyByAppKey},
+		{Path: "/composite", FillInterval: time.Second, Capacity: 1, KeyBy: KeyByComposite},
+	})
+	if err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	var key string
+	r := gin.New()
+	r.GET("/app", func(c *gin.Context) { key = l.Key(c) })
+	r.GET("/composite", func(c *gin.Context) { key = l.Key(c) })
+
+	tests := []struct {
+		path  string
+		token string
+		want  string
+	}{
+		{path: "/app", token: token, want: "* /app\treader"},
+		{path: "/app", want: "* /app\tip:192.0.2.1"},
+		{path: "/app", token: "token", want: "* /app\tip:192.0.2.1"},
+		{path: "/composite", token: token, want: "* /composite\treader|192.0.2.1"},
+		{path: "/composite", want: "* /composite\t-|192.0.2.1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if tt.token != "" {
+			req.Header.Set("token", tt.token)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if key != tt.want {
+			t.Errorf("%s token %q: Key = %q, want %q", tt.path, tt.token, key, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// gcra 为通用信元速率算法(Generic Cell Rate Algorithm)，效果与令牌桶相同，但只需保存一个时间
+// 每个令牌对应 interval 的时间，tat(theoretical arrival time)为按该速率消耗完已消耗的令牌的时间，
+// 请求在 tat 超出当前时间不多于 limit 个 interval 时被允许
+type gcra struct {
+	mu       sync.Mutex
+	interval time.Duration
+	limit    int64
+	tat      time.Time
+}
+
+// 每隔 period 允许 quantum 个令牌，最多突发 burst 个令牌，quantum 小于等于 0 时与 burst 相同
+func newGCRA(period time.Duration, burst, quantum int64) *gcra {
+	if quantum <= 0 {
+		quantum = burst
+	}
+	interval := period / time.Duration(quantum)
+	if interval <= 0 {
+		interval = 1
+	}
+	return &gcra{interval: interval, limit: burst}
+}
+
+func (b *gcra) Take(cost int64) Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	tat := b.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(time.Duration(cost) * b.interval)
+	allowAt := newTat.Add(-time.Duration(b.limit) * b.interval)
+	if now.Before(allowAt) {
+		return Result{Limit: b.limit, Remaining: b.remaining(now, tat), RetryAfter: allowAt.Sub(now)}
+	}
+	b.tat = newTat
+	return Result{Allowed: true, Limit: b.limit, Remaining: b.remaining(now, newTat)}
+}
+
+// 调用方需持有锁
+func (b *gcra) remaining(now, tat time.Time) int64 {
+	used := (tat.Sub(now) + b.interval - 1) / b.interval
+	return positive(b.limit - int64(used))
+}
@@ -2,7 +2,6 @@ package limiter
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/juju/ratelimit"
 	"time"
 )
 
@@ -11,15 +10,15 @@ import (
 type LimiterIface interface {
 	// 获取对应限流器的键值对名称
 	Key(c *gin.Context) string
-	// 获取令牌桶
-	GetBucket(key string) (*ratelimit.Bucket, bool)
+	// 获取令牌桶，返回的 Bucket 每次 Take(1) 消耗规则中 Cost 个令牌
+	GetBucket(key string) (Bucket, bool)
 	// 新增多个令牌桶
 	AddBucket(rules ...LimiterBucketRule) LimiterIface
 }
 
 type Limiter struct {
 	// 存储令牌桶和键值对名称的映射关系
-	limiterBuckets map[string]*ratelimit.Bucket
+	limiterBuckets map[string]Bucket
 }
 
 // 定义 LimiterBucketRule 结构体用于存储令牌桶的规则属性
@@ -32,4 +31,8 @@ type LimiterBucketRule struct {
 	Capacity int64
 	// 每次到达间隔时间后所释放的具体令牌数量
 	Quantum int64
+	// 限流算法，为空时为令牌桶，见 AlgorithmTokenBucket 等
+	Algorithm string
+	// 每次请求消耗的令牌数，小于等于 1 时为 1
+	Cost int64
 }
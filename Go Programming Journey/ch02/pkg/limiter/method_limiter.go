@@ -2,7 +2,6 @@ package limiter
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/juju/ratelimit"
 	"strings"
 )
 
@@ -12,7 +11,7 @@ type MethodLimiter struct {
 
 func NewMethodLimiter() LimiterIface {
 	return MethodLimiter{
-		Limiter: &Limiter{limiterBuckets: make(map[string]*ratelimit.Bucket)},
+		Limiter: &Limiter{limiterBuckets: make(map[string]Bucket)},
 	}
 }
 
@@ -30,7 +29,7 @@ func (l MethodLimiter) Key(c *gin.Context) string {
 }
 
 // 获取 Bucket 方法实现
-func (l MethodLimiter) GetBucket(key string) (*ratelimit.Bucket, bool) {
+func (l MethodLimiter) GetBucket(key string) (Bucket, bool) {
 	bucket, ok := l.limiterBuckets[key]
 	return bucket, ok
 }
@@ -39,7 +38,7 @@ func (l MethodLimiter) GetBucket(key string) (*ratelimit.Bucket, bool) {
 func (l MethodLimiter) AddBucket(rules ...LimiterBucketRule) LimiterIface {
 	for _, rule := range rules {
 		if _, ok := l.limiterBuckets[rule.Key]; !ok {
-			l.limiterBuckets[rule.Key] = ratelimit.NewBucketWithQuantum(rule.FillInterval, rule.Capacity, rule.Quantum)
+			l.limiterBuckets[rule.Key] = withCost(NewBucket(rule), rule.Cost)
 		}
 	}
 
@@ -0,0 +1,263 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// 单次访问 Redis 的超时时间，限流不应明显增加请求的耗时
+	DefaultStoreTimeout = 100 * time.Millisecond
+	// Redis 不可用后改为本地限流，每隔该时间重新尝试访问 Redis
+	DefaultStoreRetryInterval = 5 * time.Second
+)
+
+var errUnexpectedScriptResult = errors.New("unexpected redis script result")
+
+// Store 为保存令牌桶状态的外部存储，使多个服务实例共享同一份限流状态
+type Store interface {
+	// 按规则创建键为 key 的令牌桶，存储不可用时使用 local 限流
+	Bucket(key string, rule LimiterBucketRule, local Bucket) Bucket
+}
+
+// RedisStore 使用 Redis 保存令牌桶状态，每种算法均为一个 Lua 脚本，读取、计算与写入在 Redis 中原子执行
+// 时间取自 Redis 的 TIME 命令，各服务实例之间的时钟误差不影响限流结果
+// 访问 Redis 失败时各实例改为使用本地的令牌桶限流，此时总的限流数量为实例数乘以规则中的数量
+type RedisStore struct {
+	client        redis.Scripter
+	prefix        string
+	timeout       time.Duration
+	retryInterval time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// prefix 为令牌桶在 Redis 中的键前缀，e.g. blog-service:ratelimit:
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{
+		client:        client,
+		prefix:        prefix,
+		timeout:       DefaultStoreTimeout,
+		retryInterval: DefaultStoreRetryInterval,
+	}
+}
+
+func (s *RedisStore) Bucket(key string, rule LimiterBucketRule, local Bucket) Bucket {
+	script, ok := redisScripts[rule.Algorithm]
+	if !ok {
+		script = redisScripts[AlgorithmTokenBucket]
+	}
+	quantum := rule.Quantum
+	if quantum <= 0 {
+		quantum = rule.Capacity
+	}
+	// 键中包含算法，算法变化后不会读取到其他算法的状态
+	// 使用 {} 包含的 hash tag 使同一令牌桶的多个键在 Redis Cluster 中位于同一个槽
+	algorithm := rule.Algorithm
+	if algorithm == "" {
+		algorithm = AlgorithmTokenBucket
+	}
+	redisKey := s.prefix + algorithm + ":{" + key + "}"
+	return &redisBucket{
+		store:  s,
+		script: script,
+		keys:   []string{redisKey, redisKey + ":seq"},
+		args: []interface{}{
+			rule.FillInterval.Microseconds(),
+			rule.Capacity,
+			quantum,
+			0, // cost
+			// 令牌全部恢复后状态不再有用，过期时间多保留一秒
+			(refillDuration(rule) + time.Second).Milliseconds(),
+		},
+		local: local,
+	}
+}
+
+func (s *RedisStore) available(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !now.Before(s.downUntil)
+}
+
+func (s *RedisStore) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 同一段时间内仅记录一次错误
+	if !time.Now().Before(s.downUntil) {
+		log.Printf("limiter: redis store unavailable, falling back to local buckets for %s: %v", s.retryInterval, err)
+	}
+	s.downUntil = time.Now().Add(s.retryInterval)
+}
+
+type redisBucket struct {
+	store  *RedisStore
+	script *redis.Script
+	keys   []string
+	args   []interface{}
+	local  Bucket
+}
+
+func (b *redisBucket) Take(cost int64) Result {
+	if !b.store.available(time.Now()) {
+		return b.local.Take(cost)
+	}
+	args := make([]interface{}, len(b.args))
+	copy(args, b.args)
+	args[3] = cost
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.store.timeout)
+	defer cancel()
+	values, err := b.script.Run(ctx, b.store.client, b.keys, args...).Int64Slice()
+	if err == nil && len(values) != 4 {
+		err = errUnexpectedScriptResult
+	}
+	if err != nil {
+		b.store.fail(err)
+		return b.local.Take(cost)
+	}
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      values[1],
+		Remaining:  values[2],
+		RetryAfter: time.Duration(values[3]) * time.Microsecond,
+	}
+}
+
+// 各脚本的参数相同:
+// KEYS[1] 令牌桶状态的键，KEYS[2] 滑动窗口日志使用的序号键
+// ARGV[1] FillInterval(微秒)，ARGV[2] Capacity，ARGV[3] Quantum，ARGV[4] cost，ARGV[5] 键的过期时间(毫秒)
+// 返回 {是否允许, Limit, Remaining, RetryAfter(微秒)}
+// cost 为 0 时不修改状态
+var redisScripts = map[string]*redis.Script{
+	AlgorithmTokenBucket:          redis.NewScript(redisScriptPrelude + redisTokenBucketScript),
+	AlgorithmSlidingWindowLog:     redis.NewScript(redisScriptPrelude + redisSlidingWindowLogScript),
+	AlgorithmSlidingWindowCounter: redis.NewScript(redisScriptPrelude + redisSlidingWindowCounterScript),
+	AlgorithmGCRA:                 redis.NewScript(redisScriptPrelude + redisGCRAScript),
+}
+
+// Redis 5 之前的版本需要开启命令复制后才能在调用 TIME 之后写入
+// Lua 中的数字默认按 %.14g 转换为字符串，微秒时间戳需使用 int() 转换后再写入
+const redisScriptPrelude = `
+if redis.replicate_commands then redis.replicate_commands() end
+local function int(n) return string.format('%d', n) end
+local interval = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local quantum = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local ttl = tonumber(ARGV[5])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+`
+
+// 每隔 interval 放入 quantum 个令牌，tick 为最近一次放入令牌的时间
+const redisTokenBucketScript = `
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'tick')
+local tokens = tonumber(state[1])
+local tick = tonumber(state[2])
+if tokens == nil or tick == nil then
+	tokens = capacity
+	tick = now
+end
+local fills = math.floor((now - tick) / interval)
+if fills > 0 then
+	tokens = math.min(capacity, tokens + fills * quantum)
+	tick = tick + fills * interval
+end
+if tokens < cost then
+	local retry = math.ceil((cost - tokens) / quantum) * interval - (now - tick)
+	return {0, capacity, tokens, retry}
+end
+if cost > 0 then
+	tokens = tokens - cost
+	redis.call('HSET', KEYS[1], 'tokens', int(tokens), 'tick', int(tick))
+	redis.call('PEXPIRE', KEYS[1], int(ttl))
+end
+return {1, capacity, tokens, 0}
+`
+
+// 每个令牌为有序集合中的一个成员，分数为消耗的时间
+const redisSlidingWindowLogScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', int(now - interval))
+local used = redis.call('ZCARD', KEYS[1])
+if used + cost > capacity then
+	local retry = interval
+	local need = used + cost - capacity
+	if cost <= capacity then
+		local oldest = redis.call('ZRANGE', KEYS[1], need - 1, need - 1, 'WITHSCORES')
+		retry = tonumber(oldest[2]) + interval - now
+	end
+	return {0, capacity, capacity - used, retry}
+end
+if cost > 0 then
+	local seq = redis.call('INCRBY', KEYS[2], cost)
+	for i = 1, cost do
+		redis.call('ZADD', KEYS[1], int(now), int(seq - cost + i))
+	end
+	redis.call('PEXPIRE', KEYS[1], int(ttl))
+	redis.call('PEXPIRE', KEYS[2], int(ttl))
+end
+return {1, capacity, capacity - used - cost, 0}
+`
+
+// 与 slidingWindowCounter 相同，按上一个固定窗口的计数加权估算
+const redisSlidingWindowCounterScript = `
+local state = redis.call('HMGET', KEYS[1], 'start', 'prev', 'curr')
+local start = tonumber(state[1]) or now
+local prev = tonumber(state[2]) or 0
+local curr = tonumber(state[3]) or 0
+local n = math.floor((now - start) / interval)
+if n > 0 then
+	if n == 1 then prev = curr else prev = 0 end
+	curr = 0
+	start = start + n * interval
+end
+local elapsed = now - start
+local used = prev * (interval - elapsed) / interval + curr
+if used + cost > capacity then
+	local retry
+	local room = capacity - curr - cost
+	if room >= 0 and prev > 0 then
+		retry = interval - interval * room / prev - elapsed
+	else
+		retry = interval - elapsed
+		room = capacity - cost
+		if room < 0 then
+			retry = retry + interval
+		elseif curr > 0 then
+			retry = retry + math.max(0, interval - interval * room / curr)
+		end
+	end
+	return {0, capacity, math.max(0, capacity - math.ceil(used)), math.ceil(retry)}
+end
+if cost > 0 then
+	curr = curr + cost
+	used = used + cost
+	redis.call('HSET', KEYS[1], 'start', int(start), 'prev', int(prev), 'curr', int(curr))
+	redis.call('PEXPIRE', KEYS[1], int(ttl))
+end
+return {1, capacity, math.max(0, capacity - math.ceil(used)), 0}
+`
+
+// 与 gcra 相同，仅保存 tat
+const redisGCRAScript = `
+local emission = math.max(1, math.floor(interval / quantum))
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+if tat < now then tat = now end
+local newTat = tat + cost * emission
+local allowAt = newTat - capacity * emission
+if now < allowAt then
+	local remaining = capacity - math.ceil((tat - now) / emission)
+	return {0, capacity, math.max(0, remaining), allowAt - now}
+end
+if cost > 0 then
+	redis.call('SET', KEYS[1], int(newTat), 'PX', int(ttl))
+end
+local remaining = capacity - math.ceil((newTat - now) / emission)
+return {1, capacity, math.max(0, remaining), 0}
+`
@@ -0,0 +1,132 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestRedisStore(t *testing.T) (*miniredis.Miniredis, *RedisStore) {
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return m, NewRedisStore(client, "test:")
+}
+
+func TestRedisStoreScripts(t *testing.T) {
+	takes := []struct {
+		cost          int64
+		wantAllowed   bool
+		wantRemaining int64
+	}{
+		{cost: 0, wantAllowed: true, wantRemaining: 3},
+		{cost: 1, wantAllowed: true, wantRemaining: 2},
+		{cost: 3, wantAllowed: false, wantRemaining: 2},
+		{cost: 2, wantAllowed: true, wantRemaining: 0},
+		{cost: 0, wantAllowed: true, wantRemaining: 0},
+	}
+	tests := []struct {
+		algorithm      string
+		wantKeys       []string
+		wantRetryAfter time.Duration // 令牌耗尽后 Take(1) 的 RetryAfter
+	}{
+		{algorithm: "", wantKeys: []string{"test:token_bucket:{k}"}, wantRetryAfter: time.Hour},
+		{algorithm: AlgorithmTokenBucket, wantKeys: []string{"test:token_bucket:{k}"}, wantRetryAfter: time.Hour},
+		{algorithm: AlgorithmSlidingWindowLog, wantKeys: []string{"test:sliding_window_log:{k}", "test:sliding_window_log:{k}:seq"}, wantRetryAfter: time.Hour},
+		{algorithm: AlgorithmSlidingWindowCounter, wantKeys: []string{"test:sliding_window_counter:{k}"}, wantRetryAfter: 80 * time.Minute},
+		{algorithm: AlgorithmGCRA, wantKeys: []string{"test:gcra:{k}"}, wantRetryAfter: 20 * time.Minute},
+	}
+	for _, tt := range tests {
+		m, store := newTestRedisStore(t)
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		m.SetTime(now)
+		rule := LimiterBucketRule{Algorithm: tt.algorithm, FillInterval: time.Hour, Capacity: 3, Quantum: 3}
+		local := NewBucket(rule)
+		bucket := store.Bucket("k", rule, local)
+
+		for i, take := range takes {
+			result := bucket.Take(take.cost)
+			if result.Allowed != take.wantAllowed || result.Remaining != take.wantRemaining || result.Limit != 3 {
+				t.Errorf("%s: Take #%d(%d) = %+v, want Allowed %v Remaining %d",
+					tt.algorithm, i+1, take.cost, result, take.wantAllowed, take.wantRemaining)
+			}
+		}
+		if result := bucket.Take(1); result.Allowed || result.RetryAfter != tt.wantRetryAfter {
+			t.Errorf("%s: Take on an empty bucket = %+v, want RetryAfter %v", tt.algorithm, result, tt.wantRetryAfter)
+		}
+		// 脚本执行成功时不使用本地的令牌桶
+		if result := local.Take(0); result.Remaining != 3 {
+			t.Errorf("%s: local bucket used, Remaining = %d", tt.algorithm, result.Remaining)
+		}
+		for _, key := range tt.wantKeys {
+			if !m.Exists(key) {
+				t.Errorf("%s: key %q not found in %v", tt.algorithm, key, m.Keys())
+			} else if ttl, want := m.TTL(key), refillDuration(rule)+time.Second; ttl != want {
+				t.Errorf("%s: TTL(%q) = %v, want %v", tt.algorithm, key, ttl, want)
+			}
+		}
+
+		// 经过两个窗口后令牌全部恢复
+		m.SetTime(now.Add(2 * time.Hour))
+		if result := bucket.Take(3); !result.Allowed || result.Remaining != 0 {
+			t.Errorf("%s: Take(3) after refill = %+v, want allowed", tt.algorithm, result)
+		}
+	}
+}
+
+// cost 为 0 时不写入状态
+func TestRedisStoreTakeZero(t *testing.T) {
+	for _, algorithm := range algorithms {
+		m, store := newTestRedisStore(t)
+		rule := LimiterBucketRule{Algorithm: algorithm, FillInterval: time.Hour, Capacity: 3, Quantum: 3}
+		bucket := store.Bucket("k", rule, NewBucket(rule))
+		if result := bucket.Take(0); !result.Allowed || result.Remaining != 3 {
+			t.Errorf("%s: Take(0) = %+v, want allowed with 3 remaining", algorithm, result)
+		}
+		if keys := m.Keys(); len(keys) != 0 {
+			t.Errorf("%s: Take(0) wrote keys %v", algorithm, keys)
+		}
+	}
+}
+
+func TestRedisStoreFallback(t *testing.T) {
+	m, store := newTestRedisStore(t)
+	store.retryInterval = 50 * time.Millisecond
+	rule := LimiterBucketRule{FillInterval: time.Hour, Capacity: 3, Quantum: 3}
+	local := NewBucket(rule)
+	bucket := store.Bucket("k", rule, local)
+
+	// Redis 出错时使用本地的令牌桶
+	m.SetError("LOADING")
+	if result := bucket.Take(1); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("Take with redis down = %+v, want local result", result)
+	}
+	if store.available(time.Now()) {
+		t.Error("store available after an error")
+	}
+
+	// 重试间隔内即使 Redis 已恢复也继续使用本地的令牌桶
+	m.SetError("")
+	if result := bucket.Take(1); result.Remaining != 1 {
+		t.Errorf("Take within retry interval = %+v, want local result", result)
+	}
+	if keys := m.Keys(); len(keys) != 0 {
+		t.Errorf("redis used within retry interval, keys %v", keys)
+	}
+
+	time.Sleep(store.retryInterval)
+	if result := bucket.Take(1); result.Remaining != 2 {
+		t.Errorf("Take after retry interval = %+v, want redis result", result)
+	}
+	if result := local.Take(0); result.Remaining != 1 {
+		t.Errorf("local bucket Remaining = %d, want 1", result.Remaining)
+	}
+
+	// Redis 无法连接时同样使用本地的令牌桶
+	m.Close()
+	if result := bucket.Take(1); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Take with redis closed = %+v, want local result", result)
+	}
+}
@@ -0,0 +1,269 @@
+package limiter
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+	"time"
+)
+
+// 令牌桶的划分方式
+const (
+	KeyByIP        = "ip"        // 每个客户端 IP 独立
+	KeyByAppKey    = "app_key"   // 每个调用方独立，未携带有效 token 的请求按客户端 IP 区分
+	KeyByComposite = "composite" // 每个调用方在每个客户端 IP 上独立
+	KeyByRoute     = "route"     // 所有客户端共享
+)
+
+// RouteRule 为按 HTTP 方法与路由模板匹配的限流规则
+type RouteRule struct {
+	Method       string // 为空时匹配全部方法
+	Path         string // gin 注册的路由路径，e.g. /api/v1/tags/:id
+	KeyBy        string // 为空时为 KeyByIP
+	FillInterval time.Duration
+	Capacity     int64
+	Quantum      int64  // 为 0 时与 Capacity 相同
+	Algorithm    string // 为空时为 AlgorithmTokenBucket
+	Cost         int64  // 每次请求消耗的令牌数，为 0 时为 1，不能大于 Capacity
+}
+
+func (r RouteRule) bucketRule() LimiterBucketRule {
+	return LimiterBucketRule{
+		Key:          r.Path,
+		FillInterval: r.FillInterval,
+		Capacity:     r.Capacity,
+		Quantum:      r.Quantum,
+		Algorithm:    r.Algorithm,
+		Cost:         r.Cost,
+	}
+}
+
+// RouteLimiter 按 HTTP 方法与 c.FullPath() 匹配规则，规则可在运行时通过 SetRules 替换
+// 替换规则时保留令牌桶中已消耗的令牌，不会因配置变更使客户端重新获得全部令牌
+type RouteLimiter struct {
+	rules   map[string]RouteRule
+	buckets *clientBuckets
+	store   Store // 为 nil 时令牌桶仅保存在本进程中
+}
+
+func NewRouteLimiter() *RouteLimiter {
+	return &RouteLimiter{
+		rules: make(map[string]RouteRule),
+		buckets: &clientBuckets{
+			ttl:       DefaultBucketTTL,
+			entries:   make(map[string]*clientBucket),
+			lastSweep: time.Now(),
+		},
+	}
+}
+
+// 规则的键为 "方法 路径"，未指定方法时为 "* 路径"
+func routeRuleKey(method, path string) string {
+	if method == "" {
+		method = "*"
+	}
+	return strings.ToUpper(method) + " " + path
+}
+
+// 规则键与客户端标识之间使用制表符分隔，e.g. "POST /auth\t127.0.0.1"
+func (l *RouteLimiter) Key(c *gin.Context) string {
+	path := c.FullPath()
+	if path == "" {
+		return ""
+	}
+	l.buckets.mu.Lock()
+	ruleKey := routeRuleKey(c.Request.Method, path)
+	rule, ok := l.rules[ruleKey]
+	if !ok {
+		ruleKey = routeRuleKey("", path)
+		rule, ok = l.rules[ruleKey]
+	}
+	l.buckets.mu.Unlock()
+	if !ok {
+		return ""
+	}
+
+	var client string
+	switch rule.KeyBy {
+	case KeyByRoute:
+		client = "*"
+	case KeyByAppKey:
+		if client = AppKey()(c); client == "" {
+			client = "ip:" + c.ClientIP()
+		}
+	case KeyByComposite:
+		client = Composite(AppKey(), ClientIP())(c)
+	default:
+		client = c.ClientIP()
+	}
+	return ruleKey + "\t" + client
+}
+
+func (l *RouteLimiter) GetBucket(key string) (Bucket, bool) {
+	l.buckets.mu.Lock()
+	defer l.buckets.mu.Unlock()
+
+	now := time.Now()
+	l.buckets.sweep(now)
+	if entry, ok := l.buckets.entries[key]; ok {
+		entry.lastSeen = now
+		return withCost(entry.bucket, entry.rule.Cost), true
+	}
+	ruleKey := key
+	if index := strings.Index(key, "\t"); index != -1 {
+		ruleKey = key[:index]
+	}
+	rule, ok := l.rules[ruleKey]
+	if !ok {
+		return nil, false
+	}
+	entry := &clientBucket{
+		bucket:   l.newBucket(key, rule),
+		rule:     rule,
+		lastSeen: now,
+	}
+	entry.idle = l.bucketIdle(rule)
+	l.buckets.entries[key] = entry
+	return withCost(entry.bucket, rule.Cost), true
+}
+
+// 按路径添加全部方法的规则，令牌桶按客户端 IP 划分，已存在的规则不会被覆盖
+func (l *RouteLimiter) AddBucket(rules ...LimiterBucketRule) LimiterIface {
+	l.buckets.mu.Lock()
+	defer l.buckets.mu.Unlock()
+
+	for _, rule := range rules {
+		key := routeRuleKey("", rule.Key)
+		if _, ok := l.rules[key]; !ok {
+			l.rules[key] = RouteRule{
+				Path:         rule.Key,
+				KeyBy:        KeyByIP,
+				FillInterval: rule.FillInterval,
+				Capacity:     rule.Capacity,
+				Quantum:      rule.Quantum,
+				Algorithm:    rule.Algorithm,
+				Cost:         rule.Cost,
+			}
+		}
+	}
+	return l
+}
+
+// 替换全部规则，规则不合法时返回错误且保持原有规则不变
+// 参数未变化的令牌桶原样保留，参数或算法变化的令牌桶按新规则重建并扣除已消耗的令牌，已删除规则的令牌桶被清理
+func (l *RouteLimiter) SetRules(rules []RouteRule) error {
+	next := make(map[string]RouteRule, len(rules))
+	for _, rule := range rules {
+		if err := validateRouteRule(&rule); err != nil {
+			return err
+		}
+		key := routeRuleKey(rule.Method, rule.Path)
+		if _, ok := next[key]; ok {
+			return fmt.Errorf("rate limit rule %s: duplicate", key)
+		}
+		next[key] = rule
+	}
+
+	l.buckets.mu.Lock()
+	defer l.buckets.mu.Unlock()
+
+	l.rules = next
+	for key, entry := range l.buckets.entries {
+		ruleKey := key
+		if index := strings.Index(key, "\t"); index != -1 {
+			ruleKey = key[:index]
+		}
+		rule, ok := next[ruleKey]
+		// 划分方式变化后原有的键不会再被使用
+		if !ok || rule.KeyBy != entry.rule.KeyBy {
+			delete(l.buckets.entries, key)
+			continue
+		}
+		if rule == entry.rule {
+			continue
+		}
+		// 外部存储中的状态由各实例共享，按新规则访问即可，无需迁移
+		if l.store != nil {
+			entry.bucket, entry.rule, entry.idle = l.newBucket(key, rule), rule, l.bucketIdle(rule)
+			continue
+		}
+		bucket := NewBucket(rule.bucketRule())
+		state := entry.bucket.Take(0)
+		if used := state.Limit - state.Remaining; used > 0 {
+			if used > rule.Capacity {
+				used = rule.Capacity
+			}
+			bucket.Take(used)
+		}
+		entry.bucket, entry.rule, entry.idle = bucket, rule, l.bucketIdle(rule)
+	}
+	return nil
+}
+
+// 设置保存令牌桶状态的外部存储，为 nil 时仅在本进程中限流
+// 切换存储后已有的令牌桶被清理，客户端在新的存储中重新获得全部令牌
+func (l *RouteLimiter) SetStore(store Store) {
+	l.buckets.mu.Lock()
+	defer l.buckets.mu.Unlock()
+
+	if l.store == store {
+		return
+	}
+	l.store = store
+	l.buckets.entries = make(map[string]*clientBucket)
+}
+
+// 调用方需持有锁
+func (l *RouteLimiter) newBucket(key string, rule RouteRule) Bucket {
+	bucket := NewBucket(rule.bucketRule())
+	if l.store != nil {
+		bucket = l.store.Bucket(key, rule.bucketRule(), bucket)
+	}
+	return bucket
+}
+
+// 调用方需持有锁
+func (l *RouteLimiter) bucketIdle(rule RouteRule) time.Duration {
+	idle := l.buckets.ttl
+	// 令牌桶未填满前被清理会使客户端提前获得全部令牌
+	if refill := refillDuration(rule.bucketRule()); refill > idle {
+		idle = refill
+	}
+	return idle
+}
+
+// 校验规则并补全默认值
+func validateRouteRule(rule *RouteRule) error {
+	rule.Method = strings.ToUpper(rule.Method)
+	key := routeRuleKey(rule.Method, rule.Path)
+	if !strings.HasPrefix(rule.Path, "/") {
+		return fmt.Errorf("rate limit rule %s: path must start with /", key)
+	}
+	if rule.FillInterval <= 0 || rule.Capacity <= 0 || rule.Quantum < 0 {
+		return fmt.Errorf("rate limit rule %s: FillInterval and Capacity must be positive", key)
+	}
+	if rule.Quantum == 0 {
+		rule.Quantum = rule.Capacity
+	}
+	if rule.Cost == 0 {
+		rule.Cost = 1
+	}
+	if rule.Cost < 0 || rule.Cost > rule.Capacity {
+		return fmt.Errorf("rate limit rule %s: Cost must be between 1 and Capacity", key)
+	}
+	switch rule.KeyBy {
+	case "":
+		rule.KeyBy = KeyByIP
+	case KeyByIP, KeyByAppKey, KeyByComposite, KeyByRoute:
+	default:
+		return fmt.Errorf("rate limit rule %s: unknown KeyBy %q", key, rule.KeyBy)
+	}
+	switch rule.Algorithm {
+	case "":
+		rule.Algorithm = AlgorithmTokenBucket
+	case AlgorithmTokenBucket, AlgorithmSlidingWindowLog, AlgorithmSlidingWindowCounter, AlgorithmGCRA:
+	default:
+		return fmt.Errorf("rate limit rule %s: unknown Algorithm %q", key, rule.Algorithm)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package limiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetRulesValidation(t *testing.T) {
+	valid := RouteRule{Path: "/a", FillInterval: time.Second, Capacity: 10}
+	tests := []struct {
+		name  string
+		rules []RouteRule
+	}{
+		{name: "relative path", rules: []RouteRule{{Path: "a", FillInterval: time.Second, Capacity: 1}}},
+		{name: "zero capacity", rules: []RouteRule{{Path: "/a", FillInterval: time.Second}}},
+		{name: "zero interval", rules: []RouteRule{{Path: "/a", Capacity: 1}}},
+		{name: "negative quantum", rules: []RouteRule{{Path: "/a", FillInterval: time.Second, Capacity: 1, Quantum: -1}}},
+		{name: "cost over capacity", rules: []RouteRule{{Path: "/a", FillInterval: time.Second, Capacity: 1, Cost: 2}}},
+		{name: "unknown key", rules: []RouteRule{{Path: "/a", FillInterval: time.Second, Capacity: 1, KeyBy: "user"}}},
+		{name: "unknown algorithm", rules: []RouteRule{{Path: "/a", FillInterval: time.Second, Capacity: 1, Algorithm: "leaky"}}},
+		{name: "duplicate", rules: []RouteRule{valid, {Method: "*", Path: "/a", FillInterval: time.Minute, Capacity: 1}}},
+	}
+	l := NewRouteLimiter()
+	if err := l.SetRules([]RouteRule{valid}); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	for _, tt := range tests {
+		if err := l.SetRules(tt.rules); err == nil {
+			t.Errorf("%s: SetRules err = nil, want error", tt.name)
+		}
+	}
+	// 规则不合法时保持原有规则不变
+	if _, ok := l.GetBucket(routeRuleKey("", "/a") + "\tclient"); !ok {
+		t.Error("invalid SetRules replaced the existing rules")
+	}
+	rule := l.rules[routeRuleKey("", "/a")]
+	if rule.KeyBy != KeyByIP || rule.Algorithm != AlgorithmTokenBucket || rule.Quantum != 10 || rule.Cost != 1 {
+		t.Errorf("defaults not filled: %+v", rule)
+	}
+}
+
+func TestRouteLimiterKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	l := NewRouteLimiter()
+	err := l.SetRules([]RouteRule{
+		{Method: "get", Path: "/tags/:id", FillInterval: time.Second, Capacity: 10},
+		{Path: "/tags/:id", FillInterval: time.Second, Capacity: 5, KeyBy: KeyByRoute},
+	})
+	if err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+
+	var key string
+	r := gin.New()
+	r.Any("/tags/:id", func(c *gin.Context) { key = l.Key(c) })
+	r.Any("/other", func(c *gin.Context) { key = l.Key(c) })
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{method: http.MethodGet, path: "/tags/1", want: "GET /tags/:id\t192.0.2.1"},
+		{method: http.MethodGet, path: "/tags/2?x=1", want: "GET /tags/:id\t192.0.2.1"},
+		{method: http.MethodDelete, path: "/tags/1", want: "* /tags/:id\t*"},
+		{method: http.MethodGet, path: "/other", want: ""},
+	}
+	for _, tt := range tests {
+		key = "unset"
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if key != tt.want {
+			t.Errorf("%s %s: Key = %q, want %q", tt.method, tt.path, key, tt.want)
+		}
+	}
+}
+
+// 热更新规则时保留已消耗的令牌
+func TestSetRulesKeepsConsumedTokens(t *testing.T) {
+	rule := RouteRule{Path: "/a", FillInterval: time.Hour, Capacity: 5}
+	key := routeRuleKey("", "/a") + "\t192.0.2.1"
+	l := NewRouteLimiter()
+	if err := l.SetRules([]RouteRule{rule}); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	bucket, _ := l.GetBucket(key)
+	for i := 0; i < 3; i++ {
+		bucket.Take(1)
+	}
+
+	// 参数未变化时令牌桶原样保留
+	if err := l.SetRules([]RouteRule{rule}); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	bucket, _ = l.GetBucket(key)
+	if got := bucket.Take(0).Remaining; got != 2 {
+		t.Errorf("unchanged rule: Remaining = %d, want 2", got)
+	}
+
+	// 容量与算法变化时按新规则重建并扣除已消耗的令牌
+	rule.Capacity, rule.Algorithm = 10, AlgorithmGCRA
+	if err := l.SetRules([]RouteRule{rule}); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	bucket, _ = l.GetBucket(key)
+	if got := bucket.Take(0).Remaining; got != 7 {
+		t.Errorf("resized rule: Remaining = %d, want 7", got)
+	}
+
+	// 已消耗的令牌超过新的容量时令牌桶为空
+	rule.Capacity = 2
+	if err := l.SetRules([]RouteRule{rule}); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	bucket, _ = l.GetBucket(key)
+	if result := bucket.Take(1); result.Allowed || result.Remaining != 0 {
+		t.Errorf("shrunk rule: Take = %+v, want rejected", result)
+	}
+
+	// 划分方式变化后原有的令牌桶被清理
+	rule.KeyBy = KeyByRoute
+	if err := l.SetRules([]RouteRule{rule}); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	if _, ok := l.buckets.entries[key]; ok {
+		t.Error("bucket with a different KeyBy was not removed")
+	}
+
+	// 删除规则后不再限流
+	if err := l.SetRules(nil); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	if _, ok := l.GetBucket(key); ok {
+		t.Error("GetBucket for a removed rule ok = true")
+	}
+}
+
+func TestRouteLimiterCost(t *testing.T) {
+	l := NewRouteLimiter()
+	if err := l.SetRules([]RouteRule{{Path: "/upload", FillInterval: time.Hour, Capacity: 10, Cost: 4}}); err != nil {
+		t.Fatalf("SetRules err: %v", err)
+	}
+	bucket, _ := l.GetBucket(routeRuleKey("", "/upload") + "\tc")
+	for i, want := range []bool{true, true, false} {
+		if got := bucket.Take(1).Allowed; got != want {
+			t.Errorf("Take #%d Allowed = %v, want %v", i+1, got, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// slidingWindowLog 记录窗口内每次请求的时间与消耗，结果精确但占用的内存与 limit 成正比
+type slidingWindowLog struct {
+	mu      sync.Mutex
+	window  time.Duration
+	limit   int64
+	used    int64
+	entries []logEntry // 按时间先后排列
+}
+
+type logEntry struct {
+	at   time.Time
+	cost int64
+}
+
+func newSlidingWindowLog(window time.Duration, limit int64) *slidingWindowLog {
+	return &slidingWindowLog{window: window, limit: limit}
+}
+
+func (b *slidingWindowLog) Take(cost int64) Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.evict(now)
+	if b.used+cost <= b.limit {
+		if cost > 0 {
+			b.entries = append(b.entries, logEntry{at: now, cost: cost})
+			b.used += cost
+		}
+		return Result{Allowed: true, Limit: b.limit, Remaining: b.limit - b.used}
+	}
+
+	// 等待最早的记录依次滑出窗口，直到剩余的令牌足够
+	retryAfter := b.window
+	need := b.used + cost - b.limit
+	var freed int64
+	for _, entry := range b.entries {
+		if freed += entry.cost; freed >= need {
+			retryAfter = entry.at.Add(b.window).Sub(now)
+			break
+		}
+	}
+	return Result{Limit: b.limit, Remaining: b.limit - b.used, RetryAfter: retryAfter}
+}
+
+// 清理已滑出窗口的记录，调用方需持有锁
+func (b *slidingWindowLog) evict(now time.Time) {
+	i := 0
+	for ; i < len(b.entries) && !b.entries[i].at.Add(b.window).After(now); i++ {
+		b.used -= b.entries[i].cost
+	}
+	b.entries = b.entries[i:]
+}
+
+// slidingWindowCounter 只保存当前与上一个固定窗口的计数，
+// 假设上一个窗口内的请求均匀分布，按其与滑动窗口的重叠比例估算滑动窗口内的消耗
+type slidingWindowCounter struct {
+	mu     sync.Mutex
+	window time.Duration
+	limit  int64
+	start  time.Time // 当前固定窗口的开始时间
+	prev   int64
+	curr   int64
+}
+
+func newSlidingWindowCounter(window time.Duration, limit int64) *slidingWindowCounter {
+	return &slidingWindowCounter{window: window, limit: limit, start: time.Now()}
+}
+
+func (b *slidingWindowCounter) Take(cost int64) Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.advance(now)
+	elapsed := now.Sub(b.start)
+	used := float64(b.prev)*float64(b.window-elapsed)/float64(b.window) + float64(b.curr)
+	if used+float64(cost) <= float64(b.limit) {
+		b.curr += cost
+		used += float64(cost)
+		return Result{Allowed: true, Limit: b.limit, Remaining: positive(b.limit - int64(math.Ceil(used)))}
+	}
+	return Result{
+		Limit:      b.limit,
+		Remaining:  positive(b.limit - int64(math.Ceil(used))),
+		RetryAfter: b.retryAfter(elapsed, cost),
+	}
+}
+
+// 进入新的固定窗口时轮换计数，调用方需持有锁
+func (b *slidingWindowCounter) advance(now time.Time) {
+	n := now.Sub(b.start) / b.window
+	if n <= 0 {
+		return
+	}
+	if n == 1 {
+		b.prev = b.curr
+	} else {
+		b.prev = 0
+	}
+	b.curr = 0
+	b.start = b.start.Add(n * b.window)
+}
+
+// 计算估算的消耗降到足以消耗 cost 个令牌所需的时间，调用方需持有锁
+func (b *slidingWindowCounter) retryAfter(elapsed time.Duration, cost int64) time.Duration {
+	// 当前窗口内: prev*(window-t)/window + curr + cost <= limit
+	if room := b.limit - b.curr - cost; room >= 0 && b.prev > 0 {
+		at := b.window - time.Duration(float64(b.window)*float64(room)/float64(b.prev))
+		return at - elapsed
+	}
+	// 下一个窗口内: curr*(window-t)/window + cost <= limit
+	remain := b.window - elapsed
+	room := b.limit - cost
+	if room < 0 {
+		return remain + b.window
+	}
+	if b.curr == 0 {
+		return remain
+	}
+	at := b.window - time.Duration(float64(b.window)*float64(room)/float64(b.curr))
+	if at < 0 {
+		at = 0
+	}
+	return remain + at
+}
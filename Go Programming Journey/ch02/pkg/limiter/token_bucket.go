@@ -0,0 +1,38 @@
+package limiter
+
+import (
+	"github.com/juju/ratelimit"
+	"time"
+)
+
+// tokenBucket 为基于 juju/ratelimit 的令牌桶
+type tokenBucket struct {
+	bucket       *ratelimit.Bucket
+	fillInterval time.Duration
+	capacity     int64
+	quantum      int64
+}
+
+func newTokenBucket(fillInterval time.Duration, capacity, quantum int64) *tokenBucket {
+	return &tokenBucket{
+		bucket:       ratelimit.NewBucketWithQuantum(fillInterval, capacity, quantum),
+		fillInterval: fillInterval,
+		capacity:     capacity,
+		quantum:      quantum,
+	}
+}
+
+func (b *tokenBucket) Take(cost int64) Result {
+	// maxWait 为 0 时只在令牌足够时消耗，不会像 TakeAvailable 一样只消耗部分令牌
+	if _, ok := b.bucket.TakeMaxDuration(cost, 0); ok {
+		return Result{Allowed: true, Limit: b.capacity, Remaining: positive(b.bucket.Available())}
+	}
+	available := positive(b.bucket.Available())
+	// juju/ratelimit 未暴露下一次放入令牌的时间，按完整的 FillInterval 计算，结果不小于实际所需的时间
+	fills := (cost - available + b.quantum - 1) / b.quantum
+	return Result{
+		Limit:      b.capacity,
+		Remaining:  available,
+		RetryAfter: time.Duration(fills) * b.fillInterval,
+	}
+}
@@ -0,0 +1,347 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 记录已执行迁移的表
+const DefaultTable = "schema_migrations"
+
+// 迁移文件命名规则: {版本号}_{名称}.{up|down}.sql，e.g. 000001_baseline.up.sql
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// 单个版本的迁移
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 文件内容的 sha256，用于发现已执行的迁移文件被修改
+}
+
+// 迁移状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedOn int64
+	// 已执行的迁移与当前文件的 checksum 不一致
+	Dirty bool
+	// 已执行但找不到对应的迁移文件
+	Missing bool
+}
+
+type appliedRecord struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedOn int64
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    string
+	table      string
+	migrations []*Migration
+}
+
+// 创建迁移执行器，files 中的迁移文件按数据库类型存放在不同目录下，e.g. mysql/000001_baseline.up.sql
+func NewMigrator(db *sql.DB, dialect string, files fs.FS) (*Migrator, error) {
+	sub, err := fs.Sub(files, dialect)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := load(sub)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations found for dialect %s", dialect)
+	}
+	return &Migrator{db: db, dialect: dialect, table: DefaultTable, migrations: migrations}, nil
+}
+
+// 读取目录下的所有迁移文件并按版本号排序
+func load(files fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(matches[1], 10, 64)
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := versions[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			versions[version] = m
+		}
+		if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s and %s", version, m.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(versions))
+	for _, m := range versions {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s is missing its up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// 执行所有未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up() ([]*Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verify(applied); err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := m.exec(migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(m.bind("INSERT INTO "+m.table+" (version, name, checksum, applied_on) VALUES (?, ?, ?, ?)"),
+				migration.Version, migration.Name, migration.Checksum, time.Now().Unix())
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s up: %v", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// 回滚最近一次执行的迁移，没有可回滚的迁移时返回 nil
+func (m *Migrator) Down() (*Migration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	var latest int64
+	for version := range applied {
+		if version > latest {
+			latest = version
+		}
+	}
+	var migration *Migration
+	for _, item := range m.migrations {
+		if item.Version == latest {
+			migration = item
+		}
+	}
+	if migration == nil {
+		return nil, fmt.Errorf("migration %d is applied but its file is missing", latest)
+	}
+	if migration.Down == "" {
+		return nil, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+	}
+
+	err = m.exec(migration.Down, func(tx *sql.Tx) error {
+		_, err := tx.Exec(m.bind("DELETE FROM "+m.table+" WHERE version = ?"), migration.Version)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("migration %d_%s down: %v", migration.Version, migration.Name, err)
+	}
+	return migration, nil
+}
+
+// 获取所有迁移的执行状态
+func (m *Migrator) Status() ([]*Status, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*Status
+	for _, migration := range m.migrations {
+		status := &Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedOn = record.AppliedOn
+			status.Dirty = record.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		statuses = append(statuses, &Status{Version: record.Version, Name: record.Name, Applied: true, AppliedOn: record.AppliedOn, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// 已执行的迁移文件不允许再修改，需通过新增迁移的方式变更表结构
+func (m *Migrator) verify(applied map[int64]*appliedRecord) error {
+	for _, migration := range m.migrations {
+		if record, ok := applied[migration.Version]; ok && record.Checksum != migration.Checksum {
+			return fmt.Errorf("migration %d_%s has been modified after it was applied (checksum %s, expected %s)",
+				migration.Version, migration.Name, migration.Checksum, record.Checksum)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec("CREATE TABLE IF NOT EXISTS " + m.table + " (" +
+		"version BIGINT NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(255) NOT NULL, " +
+		"checksum VARCHAR(64) NOT NULL, " +
+		"applied_on BIGINT NOT NULL)")
+	return err
+}
+
+func (m *Migrator) applied() (map[int64]*appliedRecord, error) {
+	rows, err := m.db.Query("SELECT version, name, checksum, applied_on FROM " + m.table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make(map[int64]*appliedRecord)
+	for rows.Next() {
+		r := &appliedRecord{}
+		if err := rows.Scan(&r.Version, &r.Name, &r.Checksum, &r.AppliedOn); err != nil {
+			return nil, err
+		}
+		records[r.Version] = r
+	}
+	return records, rows.Err()
+}
+
+// 在同一个事务中执行迁移语句与迁移记录的变更
+// 注意 MySQL 中的 DDL 语句会隐式提交事务，迁移失败时可能需要手动处理
+func (m *Migrator) exec(script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, statement := range SplitStatements(script) {
+		if _, err := tx.Exec(statement); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := record(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// PostgreSQL 使用 $1、$2 作为占位符
+func (m *Migrator) bind(query string) string {
+	if m.dialect != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// 按分号切分 SQL 脚本，忽略引号与注释中的分号
+// MySQL 驱动默认不允许在一次调用中执行多条语句
+func SplitStatements(script string) []string {
+	var statements []string
+	var b strings.Builder
+	var quote rune
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if quote != 0 {
+			b.WriteRune(c)
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// 跳过单行注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// 跳过多行注释，循环结束时 i 指向 */ 中的 /
+			for i += 2; i < len(runes) && !(runes[i-1] == '*' && runes[i] == '/'); i++ {
+			}
+			continue
+		case c == ';':
+			if s := strings.TrimSpace(b.String()); s != "" {
+				statements = append(statements, s)
+			}
+			b.Reset()
+			continue
+		}
+		b.WriteRune(c)
+	}
+	if s := strings.TrimSpace(b.String()); s != "" {
+		statements = append(statements, s)
+	}
+	return statements
+}
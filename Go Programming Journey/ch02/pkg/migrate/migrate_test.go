@@ -0,0 +1,187 @@
+package migrate
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{name: "single", script: "CREATE TABLE a (id int)", want: []string{"CREATE TABLE a (id int)"}},
+		{name: "multiple", script: "CREATE TABLE a (id int);\n\nCREATE TABLE b (id int);\n", want: []string{"CREATE TABLE a (id int)", "CREATE TABLE b (id int)"}},
+		{name: "quoted semicolon", script: "INSERT INTO a VALUES ('x;y'); INSERT INTO `b;c` VALUES (\"1;2\")", want: []string{"INSERT INTO a VALUES ('x;y')", "INSERT INTO `b;c` VALUES (\"1;2\")"}},
+		{name: "line comment", script: "-- drop a; not a statement\nDROP TABLE a;", want: []string{"DROP TABLE a"}},
+		{name: "block comment", script: "/* a; b */ DROP TABLE a; /* trailing; */", want: []string{"DROP TABLE a"}},
+		{name: "empty", script: " ; ;\n", want: nil},
+	}
+	for _, tt := range tests {
+		if got := SplitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: SplitStatements = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{name: "missing up", files: fstest.MapFS{"sqlite3/000001_a.down.sql": {Data: []byte("DROP TABLE a")}}},
+		{name: "conflicting names", files: fstest.MapFS{
+			"sqlite3/000001_a.up.sql":   {Data: []byte("CREATE TABLE a (id int)")},
+			"sqlite3/000001_b.down.sql": {Data: []byte("DROP TABLE a")},
+		}},
+		{name: "no migrations", files: fstest.MapFS{"sqlite3/README.md": {Data: []byte("x")}}},
+		{name: "unknown dialect", files: fstest.MapFS{"mysql/000001_a.up.sql": {Data: []byte("CREATE TABLE a (id int)")}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewMigrator(nil, "sqlite3", tt.files); err == nil {
+			t.Errorf("%s: NewMigrator err = nil, want error", tt.name)
+		}
+	}
+}
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"sqlite3/000001_a.up.sql":   {Data: []byte("CREATE TABLE a (id int);\nINSERT INTO a VALUES (1);")},
+		"sqlite3/000001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"sqlite3/000002_b.up.sql":   {Data: []byte("CREATE TABLE b (id int);")},
+		"sqlite3/000002_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		// 文件名不符合规则的文件被忽略
+		"sqlite3/notes.txt": {Data: []byte("ignored")},
+	}
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open err: %v", err)
+	}
+	// 内存数据库仅在同一个连接中可见
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+		t.Fatalf("query sqlite_master err: %v", err)
+	}
+	return count > 0
+}
+
+func TestMigrator(t *testing.T) {
+	db := openDB(t)
+	files := testFiles()
+	m, err := NewMigrator(db, "sqlite3", files)
+	if err != nil {
+		t.Fatalf("NewMigrator err: %v", err)
+	}
+
+	done, err := m.Up()
+	if err != nil || len(done) != 2 || done[0].Version != 1 || done[1].Version != 2 {
+		t.Fatalf("Up = %v, %v, want versions 1 and 2", done, err)
+	}
+	if !tableExists(t, db, "a") || !tableExists(t, db, "b") {
+		t.Fatal("Up did not create tables a and b")
+	}
+	if done, err = m.Up(); err != nil || len(done) != 0 {
+		t.Fatalf("second Up = %v, %v, want no change", done, err)
+	}
+
+	statuses, err := m.Status()
+	if err != nil || len(statuses) != 2 || !statuses[0].Applied || !statuses[1].Applied {
+		t.Fatalf("Status = %v, %v, want two applied migrations", statuses, err)
+	}
+
+	down, err := m.Down()
+	if err != nil || down == nil || down.Version != 2 {
+		t.Fatalf("Down = %v, %v, want version 2", down, err)
+	}
+	if tableExists(t, db, "b") || !tableExists(t, db, "a") {
+		t.Fatal("Down should only drop table b")
+	}
+	statuses, _ = m.Status()
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Fatalf("Status after Down = %+v %+v", statuses[0], statuses[1])
+	}
+
+	// 已执行的迁移文件被修改后拒绝继续执行
+	files["sqlite3/000001_a.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id bigint);")}
+	modified, err := NewMigrator(db, "sqlite3", files)
+	if err != nil {
+		t.Fatalf("NewMigrator err: %v", err)
+	}
+	if _, err = modified.Up(); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Fatalf("Up with modified migration err = %v, want checksum error", err)
+	}
+	statuses, _ = modified.Status()
+	if !statuses[0].Dirty {
+		t.Error("Status should report the modified migration as dirty")
+	}
+
+	// 已执行但文件被删除的迁移
+	missing, err := NewMigrator(db, "sqlite3", fstest.MapFS{"sqlite3/000002_b.up.sql": files["sqlite3/000002_b.up.sql"]})
+	if err != nil {
+		t.Fatalf("NewMigrator err: %v", err)
+	}
+	statuses, _ = missing.Status()
+	if len(statuses) != 2 || !statuses[0].Missing || statuses[1].Applied {
+		t.Errorf("Status with missing file = %+v, %+v", statuses[0], statuses[1])
+	}
+	if _, err = missing.Down(); err == nil {
+		t.Error("Down with missing file err = nil, want error")
+	}
+
+	if down, err = m.Down(); err != nil || down == nil || down.Version != 1 {
+		t.Fatalf("Down = %v, %v, want version 1", down, err)
+	}
+	if down, err = m.Down(); err != nil || down != nil {
+		t.Fatalf("Down with nothing applied = %v, %v, want nil", down, err)
+	}
+}
+
+// 迁移失败时迁移语句与迁移记录一同回滚
+func TestMigratorUpFailure(t *testing.T) {
+	db := openDB(t)
+	m, err := NewMigrator(db, "sqlite3", fstest.MapFS{
+		"sqlite3/000001_a.up.sql": {Data: []byte("CREATE TABLE a (id int);")},
+		"sqlite3/000002_b.up.sql": {Data: []byte("CREATE TABLE b (id int); INSERT INTO missing VALUES (1);")},
+	})
+	if err != nil {
+		t.Fatalf("NewMigrator err: %v", err)
+	}
+	done, err := m.Up()
+	if err == nil || len(done) != 1 {
+		t.Fatalf("Up = %v, %v, want version 1 applied and an error", done, err)
+	}
+	if tableExists(t, db, "b") {
+		t.Error("failed migration should be rolled back")
+	}
+	statuses, _ := m.Status()
+	if !statuses[0].Applied || statuses[1].Applied {
+		t.Errorf("Status = %+v %+v", statuses[0], statuses[1])
+	}
+}
+
+func TestBind(t *testing.T) {
+	query := "INSERT INTO t (a, b) VALUES (?, ?)"
+	if got := (&Migrator{dialect: "postgres"}).bind(query); got != "INSERT INTO t (a, b) VALUES ($1, $2)" {
+		t.Errorf("postgres bind = %q", got)
+	}
+	if got := (&Migrator{dialect: "mysql"}).bind(query); got != query {
+		t.Errorf("mysql bind = %q", got)
+	}
+}
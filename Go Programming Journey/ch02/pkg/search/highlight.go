@@ -0,0 +1,84 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// 高亮标签
+const (
+	PreTag  = "<em>"
+	PostTag = "</em>"
+)
+
+// 截取文本中包含查询词的片段，并使用 PreTag 与 PostTag 包裹命中的查询词
+// 片段最多包含 size 个字符，文本中的 HTML 字符会被转义
+func Highlight(text string, terms []string, size int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for n, r := range runes {
+		lower[n] = unicode.ToLower(r)
+	}
+
+	// 标记命中查询词的字符
+	matched := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for n := 0; n+len(t) <= len(lower); n++ {
+			if !hasPrefix(lower[n:], t) {
+				continue
+			}
+			for m := n; m < n+len(t); m++ {
+				matched[m] = true
+			}
+			if first == -1 || n < first {
+				first = n
+			}
+		}
+	}
+
+	// 以第一个命中位置为参照截取片段，在命中位置之前保留少量上下文
+	start := 0
+	if first > size/4 {
+		start = first - size/4
+	}
+	end := start + size
+	if end > len(runes) {
+		end = len(runes)
+		if start = end - size; start < 0 {
+			start = 0
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("...")
+	}
+	for n := start; n < end; n++ {
+		if matched[n] && (n == start || !matched[n-1]) {
+			b.WriteString(PreTag)
+		}
+		b.WriteString(html.EscapeString(string(runes[n])))
+		if matched[n] && (n == end-1 || !matched[n+1]) {
+			b.WriteString(PostTag)
+		}
+	}
+	if end < len(runes) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+func hasPrefix(s, prefix []rune) bool {
+	for n, r := range prefix {
+		if s[n] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Index 为内存中的倒排索引，用于数据库不支持全文检索时的文章搜索
+// 每个文档由多个字段组成，字段权重在创建索引时指定，e.g. 标题的权重高于正文
+type Index struct {
+	mu      sync.RWMutex
+	weights []float64
+	// 索引词 => 文档 ID => 按字段权重累加后的词频
+	postings map[string]map[uint32]float64
+	// 文档 ID => 文档包含的索引词，用于更新与删除文档
+	docs map[uint32][]string
+}
+
+// 搜索命中的文档及其相关度得分
+type Hit struct {
+	ID    uint32  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+func NewIndex(weights ...float64) *Index {
+	return &Index{
+		weights:  weights,
+		postings: make(map[string]map[uint32]float64),
+		docs:     make(map[uint32][]string),
+	}
+}
+
+// 添加文档，fields 与创建索引时的 weights 一一对应，文档已存在时进行替换
+func (i *Index) Add(id uint32, fields ...string) {
+	freqs := make(map[string]float64)
+	for n, field := range fields {
+		weight := 1.0
+		if n < len(i.weights) {
+			weight = i.weights[n]
+		}
+		for _, token := range Tokenize(field) {
+			freqs[token] += weight
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+	terms := make([]string, 0, len(freqs))
+	for term, freq := range freqs {
+		postings, ok := i.postings[term]
+		if !ok {
+			postings = make(map[uint32]float64)
+			i.postings[term] = postings
+		}
+		postings[id] = freq
+		terms = append(terms, term)
+	}
+	i.docs[id] = terms
+}
+
+func (i *Index) Remove(id uint32) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+}
+
+func (i *Index) remove(id uint32) {
+	for _, term := range i.docs[id] {
+		postings := i.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.docs, id)
+}
+
+// 文档数量
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// 搜索包含任意查询词的文档，按 TF-IDF 得分从高到低排序，得分相同时 ID 大的在前
+func (i *Index) Search(query string) []Hit {
+	terms := Terms(query)
+
+	i.mu.RLock()
+	scores := make(map[uint32]float64)
+	total := float64(len(i.docs))
+	for _, term := range terms {
+		postings := i.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + total/float64(len(postings)))
+		for id, freq := range postings {
+			// 对词频取对数，避免正文中重复出现的词得分过高
+			scores[id] += (1 + math.Log(freq)) * idf
+		}
+	}
+	i.mu.RUnlock()
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ID > hits[b].ID
+	})
+	return hits
+}
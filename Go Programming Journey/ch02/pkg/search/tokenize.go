@@ -0,0 +1,65 @@
+package search
+
+import "unicode"
+
+// 将文本切分为索引词，英文与数字按单词切分并转为小写，中日韩文字按相邻两字切分（bigram）
+// e.g. "Go 语言全文搜索" => ["go", "语言", "言全", "全文", "文搜", "搜索"]
+// 与 MySQL ngram 解析器的默认行为（ngram_token_size=2）保持一致
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// 去除重复的索引词，保持原有顺序
+func Terms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, token := range Tokenize(query) {
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+		terms = append(terms, token)
+	}
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
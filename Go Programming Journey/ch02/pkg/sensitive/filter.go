@@ -0,0 +1,115 @@
+package sensitive
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Filter 基于前缀树的敏感词过滤器，匹配时忽略大小写
+// 敏感词中间夹杂的空白与标点符号也会被匹配，e.g. 敏感词 "abc" 可以匹配 "a b-c"
+type Filter struct {
+	root *node
+}
+
+type node struct {
+	children map[rune]*node
+	// 以该节点结尾的敏感词，非结尾节点为空
+	word string
+}
+
+func NewFilter(words []string) *Filter {
+	f := &Filter{root: &node{}}
+	for _, word := range words {
+		f.add(word)
+	}
+	return f
+}
+
+func (f *Filter) add(word string) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return
+	}
+	n := f.root
+	for _, r := range word {
+		if skip(r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if n.children == nil {
+			n.children = make(map[rune]*node)
+		}
+		child, ok := n.children[r]
+		if !ok {
+			child = &node{}
+			n.children[r] = child
+		}
+		n = child
+	}
+	if n != f.root {
+		n.word = word
+	}
+}
+
+// 返回文本中命中的敏感词，重复命中的敏感词只返回一次
+func (f *Filter) Find(text string) []string {
+	_, words := f.Replace(text, 0)
+	return words
+}
+
+// 将文本中命中的敏感词逐字替换为 mask，mask 为 0 时不替换，同时返回命中的敏感词
+// 同一位置存在多个敏感词时优先匹配最长的敏感词
+func (f *Filter) Replace(text string, mask rune) (string, []string) {
+	runes := []rune(text)
+	var words []string
+	seen := make(map[string]bool)
+	for i := 0; i < len(runes); {
+		if skip(runes[i]) {
+			i++
+			continue
+		}
+		end, word := f.match(runes, i)
+		if end < 0 {
+			i++
+			continue
+		}
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+		if mask != 0 {
+			for j := i; j < end; j++ {
+				if !skip(runes[j]) {
+					runes[j] = mask
+				}
+			}
+		}
+		i = end
+	}
+	return string(runes), words
+}
+
+// 从 start 开始匹配最长的敏感词，返回匹配结束的位置，未命中时返回 -1
+func (f *Filter) match(runes []rune, start int) (int, string) {
+	end, word := -1, ""
+	n := f.root
+	for i := start; i < len(runes); i++ {
+		if skip(runes[i]) {
+			continue
+		}
+		child, ok := n.children[unicode.ToLower(runes[i])]
+		if !ok {
+			break
+		}
+		n = child
+		if n.word != "" {
+			end, word = i+1, n.word
+		}
+	}
+	return end, word
+}
+
+// 匹配时忽略的字符
+func skip(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
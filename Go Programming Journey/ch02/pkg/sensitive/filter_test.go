@@ -0,0 +1,39 @@
+package sensitive
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterReplace(t *testing.T) {
+	f := NewFilter([]string{"abc", "ab", "赌博", " ", "坏人"})
+	tests := []struct {
+		text      string
+		want      string
+		wantWords []string
+	}{
+		{text: "hello", want: "hello"},
+		{text: "xabcx", want: "x***x", wantWords: []string{"abc"}},
+		{text: "xabx", want: "x**x", wantWords: []string{"ab"}},
+		{text: "ABC abc", want: "*** ***", wantWords: []string{"abc"}},
+		{text: "a b-c", want: "* *-*", wantWords: []string{"abc"}},
+		{text: "网上赌 博的坏人", want: "网上* *的**", wantWords: []string{"赌博", "坏人"}},
+		{text: "", want: ""},
+	}
+	for _, tt := range tests {
+		got, words := f.Replace(tt.text, '*')
+		if got != tt.want || !reflect.DeepEqual(words, tt.wantWords) {
+			t.Errorf("Replace(%q) = %q, %q, want %q, %q", tt.text, got, words, tt.want, tt.wantWords)
+		}
+	}
+}
+
+func TestFilterFind(t *testing.T) {
+	f := NewFilter([]string{"foo"})
+	if words := f.Find("a FOO b foo"); !reflect.DeepEqual(words, []string{"foo"}) {
+		t.Errorf("Find = %q, want [foo]", words)
+	}
+	if words := NewFilter(nil).Find("anything"); words != nil {
+		t.Errorf("empty filter Find = %q, want nil", words)
+	}
+}
@@ -1,6 +1,10 @@
 package setting
 
-import "time"
+import (
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"time"
+)
 
 // 声明配置属性的结构体
 // 服务配置结构体
@@ -9,6 +13,8 @@ type ServerSettingS struct {
 	HttpPort     string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+	// 可信代理的 IP 或 CIDR，仅来自可信代理的请求使用 X-Forwarded-For、X-Real-IP 作为客户端 IP
+	TrustedProxies []string
 }
 
 // 应用配置结构体
@@ -23,27 +29,56 @@ type AppSettingS struct {
 	UploadImageMaxSize    int
 	UploadImageAllowExts  []string
 	DefaultContextTimeout time.Duration
+	// 定时发布任务的执行间隔(秒)
+	ArticlePublishInterval time.Duration
+	// 图片的最大宽度与高度(像素)，为 0 时不限制
+	UploadImageMaxWidth  int
+	UploadImageMaxHeight int
+	// 缩略图的最长边(像素)，小于原图最长边的尺寸才会生成
+	UploadImageThumbnailSizes []int
+	// 是否为图片及其缩略图生成 WebP 格式的版本，需要启用 cgo 编译
+	UploadImageWebP bool
+	// 文档、视频与音频所允许的最大空间(MB)与文件后缀
+	UploadDocumentMaxSize   int
+	UploadDocumentAllowExts []string
+	UploadVideoMaxSize      int
+	UploadVideoAllowExts    []string
+	UploadAudioMaxSize      int
+	UploadAudioAllowExts    []string
 }
 
 // 数据库配置结构体
 type DatabaseSettingS struct {
-	DBType       string
+	DBType       string // 数据库类型: mysql、postgres、sqlite3
 	UserName     string
 	Password     string
 	Host         string
-	DBName       string
-	TablePrefix  string
+	DBName       string // 数据库名称，sqlite3 中为数据库文件路径
+	TablePrefix  string // 仅支持 blog_，为空时同样使用 blog_，见 model.TablePrefix
 	Charset      string
 	ParseTime    bool
+	SSLMode      string // postgres 的 sslmode，默认为 disable
 	MaxIdleConns int
 	MaxOpenConns int
+	AutoMigrate  bool // 启动时是否自动执行数据库迁移，等同于 -migrate=auto
 }
 
 // JWT 配置结构体
 type JWTSettingS struct {
-	Secret string
-	Issuer string
-	Expire time.Duration
+	Secret        string // 未配置 SigningKeyID 时使用 HS256 签名的密钥
+	Issuer        string
+	Expire        time.Duration // access token 有效期(秒)
+	RefreshExpire time.Duration // refresh token 有效期(秒)
+	SigningKeyID  string        // 用于签名的密钥 ID，需在 Keys 中存在且配置了私钥
+	Keys          []JWTKeyS
+}
+
+// JWT 非对称密钥，轮换时新增密钥并修改 SigningKeyID，旧密钥保留至其签发的 token 全部过期
+type JWTKeyS struct {
+	ID             string // 写入 token 头部的 kid
+	Algorithm      string // RS256 或 ES256
+	PrivateKeyFile string // PEM 格式私钥，已下线的密钥可不配置
+	PublicKeyFile  string // PEM 格式公钥，配置了私钥时可不配置
 }
 
 // Email 结构体
@@ -57,30 +92,77 @@ type EmailSettingS struct {
 	To       []string
 }
 
-var sections = make(map[string]interface{})
+// Redis 配置结构体
+type RedisSettingS struct {
+	Addr        string // 为空时不连接 Redis
+	Password    string
+	DB          int
+	PoolSize    int
+	DialTimeout time.Duration // 连接超时时间(秒)
+}
 
-// 读取相应配置的配置方法
-func (s *Setting) ReadSection(k string, v interface{}) error {
-	// 将配置文件 按照 父节点读取到相应的struct中
-	err := s.vp.UnmarshalKey(k, v)
-	if err != nil {
-		return err
-	}
+// 缓存配置结构体
+type CacheSettingS struct {
+	Backend   string        // 缓存后端: memory、redis，为空时不使用缓存
+	Size      int           // memory 最多缓存的键数量
+	TTL       time.Duration // 缓存有效期(秒)
+	KeyPrefix string        // 缓存在 Redis 中的键前缀
+}
 
-	// 针对重载应用配置项，新增处理方法
-	if _, ok := sections[k]; !ok {
-		sections[k] = v
-	}
-	return nil
+// 上传文件存储配置结构体
+type StorageSettingS struct {
+	Backend         string        // 存储后端: local、s3、memory，为空时为 local
+	SignSecret      string        // local 与 memory 下载地址的签名密钥
+	SignedURLExpire time.Duration // 私有文件下载地址的有效期(秒)
+	S3              StorageS3SettingS
+}
+
+// S3 兼容的对象存储配置
+type StorageS3SettingS struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool   // 使用 Endpoint/Bucket/key 形式的地址
+	PublicURL       string // 公开文件的访问地址前缀，为空时使用 Endpoint
+}
+
+// 评论配置结构体
+type CommentSettingS struct {
+	RequireReview  bool     // 是否所有评论都需要审核通过后才展示
+	SensitiveWords []string // 敏感词，命中的部分以 * 替换后进入审核队列
+}
+
+// 限流配置，修改后通过配置热更新生效
+type RateLimitSettingS struct {
+	Store     string // 令牌桶的存储: memory、redis，使用 redis 时多个服务实例共享限流状态，默认为 memory
+	KeyPrefix string // 令牌桶在 Redis 中的键前缀
+	Rules     []RateLimitRuleS
 }
 
-// 重新读取配置
-func (s *Setting) ReloadAllSection() error {
-	for k, v := range sections {
-		err := s.ReadSection(k, v)
-		if err != nil {
-			return err
-		}
+// 限流规则，按 HTTP 方法与 gin 注册的路由路径匹配请求
+type RateLimitRuleS struct {
+	Method       string        // 为空时匹配全部方法
+	Path         string        // e.g. /api/v1/tags/:id
+	KeyBy        string        // 令牌桶的划分方式: ip、app_key、composite、route，默认为 ip
+	FillInterval time.Duration // 每隔 FillInterval(秒)放入 Quantum 个令牌
+	Capacity     int64
+	Quantum      int64  // 未配置时与 Capacity 相同
+	Algorithm    string // 限流算法: token_bucket、sliding_window_log、sliding_window_counter、gcra，默认为 token_bucket
+	Cost         int64  // 每次请求消耗的令牌数，默认为 1
+}
+
+// 读取相应配置的配置方法
+// v 为指向配置指针的指针，e.g. **ServerSettingS，每次读取都创建新的结构体再写入指针，
+// 重新读取时不会残留已删除的配置项，调用方可以在新的结构体准备好之后再替换正在使用的配置
+func (s *Setting) ReadSection(k string, v interface{}) error {
+	// 配置节不存在时 ZeroFields 会将配置置为 nil
+	if !s.vp.IsSet(k) {
+		return fmt.Errorf("setting section %s not found", k)
 	}
-	return nil
+	// 将配置文件 按照 父节点读取到相应的struct中
+	return s.vp.UnmarshalKey(k, v, func(c *mapstructure.DecoderConfig) {
+		c.ZeroFields = true
+	})
 }
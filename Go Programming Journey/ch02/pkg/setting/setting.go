@@ -3,10 +3,13 @@ package setting
 import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"sync"
 )
 
 type Setting struct {
-	vp *viper.Viper
+	vp       *viper.Viper
+	mu       sync.Mutex
+	onReload []func()
 }
 
 // 用于初始化项目的基本配置
@@ -28,18 +31,30 @@ func NewSetting(configs ...string) (*Setting, error) {
 	if err != nil {
 		return nil, err
 	}
-	s := &Setting{vp}
+	s := &Setting{vp: vp}
 	s.WatchSettingChange()
 	return s, nil
 }
 
 // 新增热更新的监听和变更处理
+// 先注册 OnConfigChange 再开始监听，WatchConfig 会在自己的 goroutine 中读取变更并调用回调
 func (s *Setting) WatchSettingChange() {
-	go func() {
-		s.vp.WatchConfig()
-		// 如果配置文件发生了改变就重新读取配置项
-		s.vp.OnConfigChange(func(in fsnotify.Event) {
-			_ = s.ReloadAllSection()
-		})
-	}()
+	// 如果配置文件发生了改变就重新读取配置项
+	// 由回调读取新的配置，全部配置节读取并转换完成后再替换正在使用的配置
+	s.vp.OnConfigChange(func(in fsnotify.Event) {
+		s.mu.Lock()
+		callbacks := s.onReload
+		s.mu.Unlock()
+		for _, fn := range callbacks {
+			fn()
+		}
+	})
+	s.vp.WatchConfig()
+}
+
+// 注册配置文件变更后的回调，用于重新读取配置并使依赖配置的组件生效
+func (s *Setting) OnReload(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onReload = append(s.onReload, fn)
 }
@@ -0,0 +1,99 @@
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+Server:
+  RunMode: debug
+  HttpPort: 8000
+  ReadTimeout: 60
+Comment:
+  RequireReview: true
+  SensitiveWords:
+    - foo
+    - bar
+`
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "config.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("os.WriteFile err: %v", err)
+	}
+}
+
+func TestReadSection(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, testConfig)
+	s, err := NewSetting(dir)
+	if err != nil {
+		t.Fatalf("NewSetting err: %v", err)
+	}
+
+	var server *ServerSettingS
+	if err = s.ReadSection("Server", &server); err != nil {
+		t.Fatalf("ReadSection err: %v", err)
+	}
+	if server.RunMode != "debug" || server.HttpPort != "8000" || server.ReadTimeout != 60 {
+		t.Errorf("Server = %+v", server)
+	}
+
+	// 每次读取都创建新的结构体，已读取的配置不会被修改
+	old := server
+	old.ReadTimeout *= time.Second
+	if err = s.ReadSection("Server", &server); err != nil {
+		t.Fatalf("ReadSection err: %v", err)
+	}
+	if server == old || server.ReadTimeout != 60 || old.ReadTimeout != 60*time.Second {
+		t.Errorf("second ReadSection reused the previous struct: %+v, %+v", server, old)
+	}
+
+	var comment *CommentSettingS
+	if err = s.ReadSection("Comment", &comment); err != nil {
+		t.Fatalf("ReadSection err: %v", err)
+	}
+	if !comment.RequireReview || len(comment.SensitiveWords) != 2 {
+		t.Errorf("Comment = %+v", comment)
+	}
+
+	var app *AppSettingS
+	if err = s.ReadSection("App", &app); err == nil {
+		t.Error("ReadSection of a missing section err = nil, want error")
+	}
+}
+
+func TestOnReload(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, testConfig)
+	s, err := NewSetting(dir)
+	if err != nil {
+		t.Fatalf("NewSetting err: %v", err)
+	}
+
+	reloaded := make(chan *CommentSettingS, 10)
+	s.OnReload(func() {
+		var comment *CommentSettingS
+		if err := s.ReadSection("Comment", &comment); err == nil {
+			reloaded <- comment
+		}
+	})
+	// 等待 WatchConfig 开始监听
+	time.Sleep(100 * time.Millisecond)
+	writeConfig(t, dir, "Comment:\n  SensitiveWords:\n    - baz\n")
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case comment := <-reloaded:
+			if len(comment.SensitiveWords) == 1 && comment.SensitiveWords[0] == "baz" && !comment.RequireReview {
+				return
+			}
+		case <-timeout:
+			t.Fatal("OnReload callback was not called with the new config")
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Local 将对象保存在本地目录中，key 即相对于目录的路径
+type Local struct {
+	urlSigner
+	dir string
+}
+
+// baseURL 为下载接口的地址前缀，e.g. http://127.0.0.1:8000/static，secret 为下载地址的签名密钥
+func NewLocal(dir, baseURL, secret string) (*Local, error) {
+	// os.MkdirAll 若目录已存在则不会进行任何操作
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &Local{urlSigner: urlSigner{baseURL: baseURL, secret: []byte(secret)}, dir: dir}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	key = cleanKey(key)
+	if key == "" {
+		return "", ErrNotExist
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// 内容先写入同目录下的临时文件，完成后重命名
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dst, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	// 重命名成功后临时文件已不存在，Remove 不会产生影响
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	// 临时文件默认仅所有者可读
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (l *Local) Exists(ctx context.Context, key string) (bool, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return false, nil
+	}
+	info, err := os.Stat(p)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return nil
+	}
+	if err = os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(ctx context.Context, key string, expire time.Duration) (string, error) {
+	return l.url(key, expire), nil
+}
+
+// 目录按不存在处理，不提供目录列表
+func (l *Local) Open(ctx context.Context, key string) (io.ReadSeekCloser, time.Time, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, time.Time{}, ErrNotExist
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, time.Time{}, ErrNotExist
+	}
+	return f, info.ModTime(), nil
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+// Memory 将对象保存在内存中，服务重启后丢失，用于测试与本地开发
+type Memory struct {
+	urlSigner
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+func NewMemory(baseURL, secret string) *Memory {
+	return &Memory{
+		urlSigner: urlSigner{baseURL: baseURL, secret: []byte(secret)},
+		objects:   make(map[string]memoryObject),
+	}
+}
+
+func (m *Memory) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key = cleanKey(key)
+	if key == "" {
+		return ErrNotExist
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = memoryObject{data: data, modTime: time.Now()}
+	return nil
+}
+
+func (m *Memory) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.objects[cleanKey(key)]
+	return ok, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, cleanKey(key))
+	return nil
+}
+
+func (m *Memory) URL(ctx context.Context, key string, expire time.Duration) (string, error) {
+	return m.url(key, expire), nil
+}
+
+// 保存后的对象不会被修改，读取时无需复制
+func (m *Memory) Open(ctx context.Context, key string) (io.ReadSeekCloser, time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	object, ok := m.objects[cleanKey(key)]
+	if !ok {
+		return nil, time.Time{}, ErrNotExist
+	}
+	return nopCloser{bytes.NewReader(object.data)}, object.modTime, nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
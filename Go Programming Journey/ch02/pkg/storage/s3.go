@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// 签名地址的最长有效期
+	s3MaxPresignExpire = 7 * 24 * time.Hour
+)
+
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com、http://127.0.0.1:9000
+	Region          string // 为空时为 us-east-1
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool   // 使用 Endpoint/Bucket/key 形式的地址，MinIO 等需开启
+	PublicURL       string // 公开对象的访问地址前缀，e.g. CDN 地址，为空时使用对象在 Endpoint 上的地址
+}
+
+// S3 将对象保存在 S3 兼容的对象存储中，请求使用 Signature Version 4 签名
+// 公开对象的访问地址需在存储桶上配置公开读取的权限，私有对象使用预签名地址访问
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3(config S3Config) (*S3, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("storage: s3 bucket is not configured")
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 endpoint: %w", err)
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: s3 endpoint %q must be an absolute URL", config.Endpoint)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+	return &S3{config: config, endpoint: endpoint, client: &http.Client{}}, nil
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := &url.URL{Scheme: s.endpoint.Scheme, Host: s.endpoint.Host}
+	if s.config.PathStyle {
+		u.Path = "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	// S3 要求路径按 RFC 3986 编码，与 url.URL 默认的编码方式不同
+	u.RawPath = s3Escape(u.Path, false)
+	return u
+}
+
+// 以 UNSIGNED-PAYLOAD 签名，无需为计算摘要额外读取一次内容
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return s.do(req, key)
+}
+
+func (s *S3) Exists(ctx context.Context, key string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key).String(), nil)
+	if err != nil {
+		return false, err
+	}
+	err = s.do(req, key)
+	if err == ErrNotExist {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	err = s.do(req, key)
+	if err == ErrNotExist {
+		return nil
+	}
+	return err
+}
+
+// 私有对象以及 expire 大于 0 时返回预签名地址，有效期最长为 7 天
+func (s *S3) URL(ctx context.Context, key string, expire time.Duration) (string, error) {
+	if expire <= 0 && !IsPrivate(key) {
+		if s.config.PublicURL != "" {
+			return s.config.PublicURL + "/" + s3Escape(key, false), nil
+		}
+		return s.objectURL(key).String(), nil
+	}
+	if expire <= 0 || expire > s3MaxPresignExpire {
+		expire = s3MaxPresignExpire
+	}
+	return s.presign(http.MethodGet, s.objectURL(key), expire, time.Now()), nil
+}
+
+// 签名并发送请求，404 时返回 ErrNotExist
+func (s *S3) do(req *http.Request, key string) error {
+	s.sign(req, s3UnsignedPayload, time.Now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotExist
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("storage: s3 %s %s: %s %s", req.Method, key, resp.Status, body)
+	}
+	// 读取完响应体后连接才能被复用
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// 在请求头中添加 Authorization，签名包含 Host、Content-Type、Range 与全部 X-Amz-* 请求头
+func (s *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") || name == "range" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	scope := s.scope(amzDate)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	signature := s.signature(amzDate, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// 生成预签名地址，签名仅包含 Host
+func (s *S3) presign(method string, u *url.URL, expire time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := s.scope(amzDate)
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.config.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expire/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	canonicalQuery := s3CanonicalQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	signature := s.signature(amzDate, scope, canonicalRequest)
+	signed := *u
+	signed.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+	return signed.String()
+}
+
+func (s *S3) scope(amzDate string) string {
+	return amzDate[:8] + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+func (s *S3) signature(amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := s3HMAC([]byte("AWS4"+s.config.SecretAccessKey), amzDate[:8])
+	key = s3HMAC(key, s.config.Region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// 参数按编码后的名称排序，名称相同时按值排序，名称与值均按 RFC 3986 编码
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	escaped := make(map[string]string, len(query))
+	for name := range query {
+		escaped[name] = s3Escape(name, true)
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return escaped[names[i]] < escaped[names[j]] })
+	var pairs []string
+	for _, name := range names {
+		values := make([]string, 0, len(query[name]))
+		for _, value := range query[name] {
+			values = append(values, s3Escape(value, true))
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escaped[name]+"="+value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// 仅保留 RFC 3986 中的非保留字符，encodeSlash 为 false 时保留 /
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
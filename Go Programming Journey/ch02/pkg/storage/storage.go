@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 以该前缀开头的对象为私有对象，只能通过签名的下载地址访问
+const PrivatePrefix = "private/"
+
+var ErrNotExist = errors.New("storage: object does not exist")
+
+// Storage 为上传文件的存储后端，对象以 / 分隔的 key 标识，e.g. private/0a1b2c.png
+type Storage interface {
+	// 保存对象，key 已存在时覆盖，读取方不会读取到写入了一半的对象
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	// 对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// 对象的访问地址，expire 大于 0 时返回在 expire 后失效的签名地址，私有对象必须使用签名地址访问
+	URL(ctx context.Context, key string, expire time.Duration) (string, error)
+}
+
+// Server 由对象保存在本服务中的存储实现，对象通过本服务的下载接口访问
+type Server interface {
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, time.Time, error)
+	// 校验下载地址中的签名，签名无效或已过期时返回 false
+	Verify(key string, query url.Values) bool
+}
+
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(key, PrivatePrefix)
+}
+
+// 去除 key 中的 . 与 .. 路径，避免访问存储目录以外的文件，结果为空时 key 不合法
+func cleanKey(key string) string {
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+// urlSigner 为本服务提供下载的存储生成下载地址，签名为 key 与过期时间的 HMAC-SHA256
+type urlSigner struct {
+	baseURL string
+	secret  []byte
+}
+
+func (s urlSigner) url(key string, expire time.Duration) string {
+	u := s.baseURL + "/" + (&url.URL{Path: key}).EscapedPath()
+	if expire <= 0 {
+		return u
+	}
+	expires := strconv.FormatInt(time.Now().Add(expire).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return u + "?" + query.Encode()
+}
+
+func (s urlSigner) Verify(key string, query url.Values) bool {
+	expires := query.Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(query.Get("signature")), []byte(s.sign(key, expires)))
+}
+
+func (s urlSigner) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSignerVerify(t *testing.T) {
+	signer := urlSigner{baseURL: "http://127.0.0.1:8000/static", secret: []byte("secret")}
+	signed, err := url.Parse(signer.url("private/a b.png", time.Minute))
+	if err != nil {
+		t.Fatalf("url.Parse err: %v", err)
+	}
+	if signed.Path != "/static/private/a b.png" {
+		t.Errorf("Path = %q", signed.Path)
+	}
+	valid := signed.Query()
+	expired := strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10)
+
+	tests := []struct {
+		name   string
+		signer urlSigner
+		key    string
+		query  url.Values
+		want   bool
+	}{
+		{name: "valid", signer: signer, key: "private/a b.png", query: valid, want: true},
+		{name: "other key", signer: signer, key: "private/b.png", query: valid, want: false},
+		{name: "other secret", signer: urlSigner{secret: []byte("other")}, key: "private/a b.png", query: valid, want: false},
+		{name: "tampered signature", signer: signer, key: "private/a b.png",
+			query: url.Values{"expires": valid["expires"], "signature": {strings.Repeat("0", 64)}}, want: false},
+		{name: "extended expires", signer: signer, key: "private/a b.png",
+			query: url.Values{"expires": {strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)}, "signature": valid["signature"]}, want: false},
+		{name: "expired", signer: signer, key: "private/a b.png",
+			query: url.Values{"expires": {expired}, "signature": {signer.sign("private/a b.png", expired)}}, want: false},
+		{name: "missing expires", signer: signer, key: "private/a b.png", query: url.Values{"signature": valid["signature"]}, want: false},
+		{name: "missing signature", signer: signer, key: "private/a b.png", query: url.Values{"expires": valid["expires"]}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.signer.Verify(tt.key, tt.query); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 未指定有效期时返回不带签名的地址
+	if got := signer.url("a.png", 0); got != "http://127.0.0.1:8000/static/a.png" {
+		t.Errorf("url without expire = %q", got)
+	}
+}
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "a.png", want: "a.png"},
+		{key: "/private/a.png", want: "private/a.png"},
+		{key: "../../etc/passwd", want: "etc/passwd"},
+		{key: "private/../a.png", want: "a.png"},
+		{key: "..", want: ""},
+		{key: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := cleanKey(tt.key); got != tt.want {
+			t.Errorf("cleanKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+type serverStorage interface {
+	Storage
+	Server
+}
+
+func TestServerStorages(t *testing.T) {
+	local, err := NewLocal(t.TempDir(), "http://localhost/static", "secret")
+	if err != nil {
+		t.Fatalf("NewLocal err: %v", err)
+	}
+	storages := map[string]serverStorage{
+		"local":  local,
+		"memory": NewMemory("http://localhost/static", "secret"),
+	}
+	ctx := context.Background()
+	for name, s := range storages {
+		if err := s.Put(ctx, "private/../dir/a.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+			t.Fatalf("%s: Put err: %v", name, err)
+		}
+		if err := s.Put(ctx, "..", strings.NewReader("x"), 1, ""); err != ErrNotExist {
+			t.Errorf("%s: Put invalid key err = %v, want ErrNotExist", name, err)
+		}
+		// 覆盖已有的对象
+		if err := s.Put(ctx, "dir/a.txt", strings.NewReader("world"), 5, "text/plain"); err != nil {
+			t.Fatalf("%s: Put err: %v", name, err)
+		}
+
+		f, modTime, err := s.Open(ctx, "dir/a.txt")
+		if err != nil {
+			t.Fatalf("%s: Open err: %v", name, err)
+		}
+		body, _ := ioutil.ReadAll(f)
+		f.Close()
+		if string(body) != "world" || modTime.IsZero() {
+			t.Errorf("%s: Open = %q, %v", name, body, modTime)
+		}
+		if _, _, err := s.Open(ctx, "dir"); err != ErrNotExist {
+			t.Errorf("%s: Open directory err = %v, want ErrNotExist", name, err)
+		}
+
+		if ok, err := s.Exists(ctx, "dir/a.txt"); !ok || err != nil {
+			t.Errorf("%s: Exists = %v, %v, want true", name, ok, err)
+		}
+		if err := s.Delete(ctx, "dir/a.txt"); err != nil {
+			t.Errorf("%s: Delete err: %v", name, err)
+		}
+		if err := s.Delete(ctx, "dir/a.txt"); err != nil {
+			t.Errorf("%s: Delete missing object err: %v", name, err)
+		}
+		if ok, _ := s.Exists(ctx, "dir/a.txt"); ok {
+			t.Errorf("%s: Exists after Delete = true", name)
+		}
+		if _, _, err := s.Open(ctx, "dir/a.txt"); err != ErrNotExist {
+			t.Errorf("%s: Open after Delete err = %v, want ErrNotExist", name, err)
+		}
+
+		u, _ := s.URL(ctx, "private/b.txt", time.Minute)
+		signed, _ := url.Parse(u)
+		if !s.Verify("private/b.txt", signed.Query()) {
+			t.Errorf("%s: Verify(URL) = false", name)
+		}
+	}
+}
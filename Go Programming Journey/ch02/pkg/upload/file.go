@@ -1,11 +1,15 @@
 package upload
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"demo/ch02/global"
-	"demo/ch02/pkg/util"
+	"demo/ch02/pkg/storage"
+	"encoding/hex"
+	"errors"
 	"io"
-	"io/ioutil"
-	"mime/multipart"
+	"net/http"
 	"os"
 	"path"
 	"strings"
@@ -15,15 +19,72 @@ import (
 type FileType int
 
 // 使用 FileType 作为类别表示的基础类型
-const TypeImage FileType = iota + 1 // TypeImage = 1
+const (
+	TypeImage    FileType = iota + 1 // TypeImage = 1
+	TypeDocument                     // TypeDocument = 2
+	TypeVideo                        // TypeVideo = 3
+	TypeAudio                        // TypeAudio = 4
+)
 
-// 返回经过加密处理后的文件名
-func GetFileName(name string) string {
-	ext := GetFileExt(name)
-	// 返回没有后缀的文件名
-	fileName := strings.TrimSuffix(name, ext)
-	fileName = util.EncodeMD5(fileName)
-	return fileName + ext
+var fileTypes = []FileType{TypeImage, TypeDocument, TypeVideo, TypeAudio}
+
+// 请求体中除文件内容外的 multipart 边界、头部与其他字段所允许的大小
+const multipartOverhead = 1 << 20
+
+// http.DetectContentType 最多读取的字节数
+const sniffLen = 512
+
+var (
+	ErrUnsupportedExt  = errors.New("file suffix is not supported.")
+	ErrContentMismatch = errors.New("file content does not match its suffix.")
+	ErrFileTooLarge    = errors.New("exceeded maximum file limit.")
+)
+
+// File 为保存后的文件，图片另有缩略图等版本
+type File struct {
+	Key         string
+	ContentType string
+	Width       int // 仅图片
+	Height      int // 仅图片
+	Variants    []Variant
+}
+
+// Variant 为由图片生成的其他尺寸或格式的版本
+type Variant struct {
+	Name        string // e.g. thumb_200、webp、thumb_200_webp
+	Key         string
+	ContentType string
+	Width       int
+	Height      int
+}
+
+type fileFormat struct {
+	sniffed     string // http.DetectContentType 的识别结果，不含参数
+	contentType string // 保存时使用的 Content-Type
+	ext         string // 保存时使用的后缀，相同内容以不同后缀上传时保存为同一个文件
+}
+
+// 文件后缀对应的格式，后缀未在其中的文件无法通过内容校验
+// docx 等 Office 文档的内容为 zip 压缩包，只能识别为 application/zip
+var fileFormats = map[string]fileFormat{
+	".jpg":  {"image/jpeg", "image/jpeg", ".jpg"},
+	".jpeg": {"image/jpeg", "image/jpeg", ".jpg"},
+	".png":  {"image/png", "image/png", ".png"},
+	".gif":  {"image/gif", "image/gif", ".gif"},
+	".bmp":  {"image/bmp", "image/bmp", ".bmp"},
+	".webp": {"image/webp", "image/webp", ".webp"},
+	".pdf":  {"application/pdf", "application/pdf", ".pdf"},
+	".txt":  {"text/plain", "text/plain; charset=utf-8", ".txt"},
+	".md":   {"text/plain", "text/markdown; charset=utf-8", ".md"},
+	".docx": {"application/zip", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+	".xlsx": {"application/zip", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx"},
+	".pptx": {"application/zip", "application/vnd.openxmlformats-officedocument.presentationml.presentation", ".pptx"},
+	".mp4":  {"video/mp4", "video/mp4", ".mp4"},
+	".webm": {"video/webm", "video/webm", ".webm"},
+	".avi":  {"video/avi", "video/x-msvideo", ".avi"},
+	".mp3":  {"audio/mpeg", "audio/mpeg", ".mp3"},
+	".wav":  {"audio/wave", "audio/wav", ".wav"},
+	".ogg":  {"application/ogg", "audio/ogg", ".ogg"},
 }
 
 func GetFileExt(name string) string {
@@ -31,16 +92,19 @@ func GetFileExt(name string) string {
 	return path.Ext(name)
 }
 
-func GetSavePath() string {
-	// 返回配置中的文件保存目录
-	return global.AppSetting.UploadSavePath
-}
-
-// 检测路径是否存在
-func CheckSavePath(dst string) bool {
-	// 利用 os.Stat() 方法所返回的 error 值与系统所定义的 oserror.ErrNotExist 是否相等
-	_, err := os.Stat(dst)
-	return os.IsNotExist(err)
+// 文件类型在配置中允许的文件后缀
+func allowExts(t FileType) []string {
+	switch t {
+	case TypeImage:
+		return global.AppSetting().UploadImageAllowExts
+	case TypeDocument:
+		return global.AppSetting().UploadDocumentAllowExts
+	case TypeVideo:
+		return global.AppSetting().UploadVideoAllowExts
+	case TypeAudio:
+		return global.AppSetting().UploadAudioAllowExts
+	}
+	return nil
 }
 
 // 检测文件后缀是否满足设置条件
@@ -48,64 +112,111 @@ func CheckContainExt(t FileType, name string) bool {
 	ext := GetFileExt(name)
 	// 同一转换为大写进行匹配
 	ext = strings.ToUpper(ext)
-	switch t {
-	case TypeImage:
-		// 与配置文件中设置的允许的文件后缀名进行比较
-		for _, allowExt := range global.AppSetting.UploadImageAllowExts {
-			if strings.ToUpper(allowExt) == ext {
-				return true
-			}
+	// 与配置文件中设置的允许的文件后缀名进行比较
+	for _, allowExt := range allowExts(t) {
+		if strings.ToUpper(allowExt) == ext {
+			return true
 		}
 	}
 	return false
 }
 
-// 检测最大大小是否超出最大限制
-func CheckMaxSize(t FileType, f multipart.File) bool {
-	content, _ := ioutil.ReadAll(f)
-	size := len(content)
+// 文件类型所允许的最大字节数，未知类型为 0
+func MaxSize(t FileType) int64 {
+	var size int
 	switch t {
 	case TypeImage:
-		if size >= global.AppSetting.UploadImageMaxSize*1024*1024 {
-			return true
-		}
+		size = global.AppSetting().UploadImageMaxSize
+	case TypeDocument:
+		size = global.AppSetting().UploadDocumentMaxSize
+	case TypeVideo:
+		size = global.AppSetting().UploadVideoMaxSize
+	case TypeAudio:
+		size = global.AppSetting().UploadAudioMaxSize
 	}
-	return false
+	return int64(size) * 1024 * 1024
 }
 
-// 检测文件权限是否足够
-func CheckPermission(dst string) bool {
-	// 与 CheckSavePath() 类似，与 oserror.ErrPermission 判断是否相等
-	_, err := os.Stat(dst)
-	return os.IsPermission(err)
+// 上传请求的请求体所允许的最大字节数，读取表单前无法得知文件类型，按最大的文件类型计算
+func MaxRequestSize() int64 {
+	var size int64
+	for _, t := range fileTypes {
+		if s := MaxSize(t); s > size {
+			size = s
+		}
+	}
+	return size + multipartOverhead
 }
 
-// 创建上传文件时所使用的保存目录
-func CreateSavePath(dst string, perm os.FileMode) error {
-	// os.MkdirAll 会根据传入的 os.FileMode 权限位递归创所需的所有目录结构
-	// 若目录已存在则不会进行任何操作，直接返回 nil
-	err := os.MkdirAll(dst, perm)
-	if err != nil {
-		return err
+// 将上传的文件保存到 store，private 为 true 时保存为私有对象
+// 根据文件头部的内容识别文件类型，与文件后缀不符时拒绝保存，读取时边写入边计算大小，超过限制后立即停止
+// 文件名为内容的 SHA-256 摘要，内容相同的文件只保存一份
+// 内容先写入本地的临时文件，校验通过并得到摘要后再保存到 store，图片另经 processImage 处理
+func SaveFile(ctx context.Context, store storage.Storage, t FileType, name string, src io.Reader, private bool) (*File, error) {
+	format, ok := fileFormats[strings.ToLower(GetFileExt(name))]
+	if !ok || !CheckContainExt(t, name) {
+		return nil, ErrUnsupportedExt
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	sniffed := http.DetectContentType(head)
+	if index := strings.Index(sniffed, ";"); index != -1 {
+		sniffed = sniffed[:index]
+	}
+	if sniffed != format.sniffed {
+		return nil, ErrContentMismatch
 	}
-	return nil
-}
 
-// 保存所上传的文件
-func SaveFile(file *multipart.FileHeader, dst string) error {
-	// 通过 file.Open 打开源地址的文件
-	src, err := file.Open()
+	tmp, err := os.CreateTemp("", "upload-*")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer src.Close()
-	// 通过 os.Create 创建目标地址的文件
-	out, err := os.Create(dst)
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	maxSize := MaxSize(t)
+	hash := sha256.New()
+	// 多读取一个字节用于判断是否超过限制
+	body := io.LimitReader(io.MultiReader(bytes.NewReader(head), src), maxSize+1)
+	written, err := io.Copy(io.MultiWriter(tmp, hash), body)
 	if err != nil {
+		return nil, err
+	}
+	if written > maxSize {
+		return nil, ErrFileTooLarge
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if private {
+		prefix = storage.PrivatePrefix
+	}
+	if t == TypeImage {
+		return saveImage(ctx, store, tmp, format, prefix)
+	}
+
+	file := &File{
+		Key:         prefix + hex.EncodeToString(hash.Sum(nil)) + format.ext,
+		ContentType: format.contentType,
+	}
+	if err = putIfNotExists(ctx, store, file.Key, tmp, written, file.ContentType); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// 对象已存在时不再保存，内容相同的文件只保存一份
+func putIfNotExists(ctx context.Context, store storage.Storage, key string, r io.Reader, size int64, contentType string) error {
+	exists, err := store.Exists(ctx, key)
+	if err != nil || exists {
 		return err
 	}
-	defer out.Close()
-	// 结合 io.Copy 实现两者之间的文件内容拷贝
-	_, err = io.Copy(out, src)
-	return err
+	return store.Put(ctx, key, r, size, contentType)
 }
@@ -0,0 +1,255 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"demo/ch02/global"
+	"demo/ch02/pkg/storage"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	jpegQuality = 85
+	webpQuality = 80
+)
+
+var (
+	ErrInvalidImage    = errors.New("file is not a valid image.")
+	ErrImageDimensions = errors.New("image dimensions exceed the limit.")
+)
+
+// 由原图生成的版本，像素在需要时才生成，已保存的版本不会重复生成
+type variantSpec struct {
+	name        string
+	suffix      string // 追加在原图摘要之后的后缀，e.g. _200.jpg
+	contentType string
+	width       int
+	height      int
+}
+
+// 处理并保存图片，返回原图及其各个版本
+// 先读取图片头部校验尺寸，避免解码尺寸过大的图片占用大量内存
+// 保存前去除 EXIF 等元数据，文件名为处理后内容的摘要
+func saveImage(ctx context.Context, store storage.Storage, r io.Reader, format fileFormat, prefix string) (*File, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if err = checkDimensions(config.Width, config.Height); err != nil {
+		return nil, err
+	}
+	data, orientation, err := stripMetadata(format.contentType, data)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	width, height := config.Width, config.Height
+	var img image.Image
+	// 元数据中的方向随 EXIF 一同去除，按方向旋转后重新编码，图片仍以正确的方向显示
+	if orientation > 1 {
+		if img, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+			return nil, ErrInvalidImage
+		}
+		img = orient(img, orientation)
+		var buf bytes.Buffer
+		if err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+		width, height = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+
+	sum := sha256.Sum256(data)
+	name := prefix + hex.EncodeToString(sum[:])
+	file := &File{Key: name + format.ext, ContentType: format.contentType, Width: width, Height: height}
+	if err = putIfNotExists(ctx, store, file.Key, bytes.NewReader(data), int64(len(data)), file.ContentType); err != nil {
+		return nil, err
+	}
+
+	for _, spec := range imageVariants(format, width, height) {
+		variant := Variant{
+			Name:        spec.name,
+			Key:         name + spec.suffix,
+			ContentType: spec.contentType,
+			Width:       spec.width,
+			Height:      spec.height,
+		}
+		exists, err := store.Exists(ctx, variant.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if img == nil {
+				if img, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+					return nil, ErrInvalidImage
+				}
+			}
+			var buf bytes.Buffer
+			if err = encodeImage(&buf, resize(img, spec.width, spec.height), spec.contentType); err != nil {
+				return nil, err
+			}
+			if err = store.Put(ctx, variant.Key, &buf, int64(buf.Len()), variant.ContentType); err != nil {
+				return nil, err
+			}
+		}
+		file.Variants = append(file.Variants, variant)
+	}
+	return file, nil
+}
+
+func checkDimensions(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return ErrInvalidImage
+	}
+	s := global.AppSetting()
+	maxWidth, maxHeight := s.UploadImageMaxWidth, s.UploadImageMaxHeight
+	if maxWidth > 0 && width > maxWidth || maxHeight > 0 && height > maxHeight {
+		return ErrImageDimensions
+	}
+	return nil
+}
+
+// 按配置生成 WebP 格式的原图以及各尺寸的缩略图，缩略图为 JPEG 或 PNG，不放大原图
+func imageVariants(format fileFormat, width, height int) []variantSpec {
+	thumbType, thumbExt := "image/png", ".png"
+	if format.contentType == "image/jpeg" {
+		thumbType, thumbExt = "image/jpeg", ".jpg"
+	}
+	s := global.AppSetting()
+	webp := s.UploadImageWebP && webpSupported
+
+	var specs []variantSpec
+	if webp && format.contentType != "image/webp" {
+		specs = append(specs, variantSpec{name: "webp", suffix: ".webp", contentType: "image/webp", width: width, height: height})
+	}
+	longest := width
+	if height > longest {
+		longest = height
+	}
+	sizes := append([]int(nil), s.UploadImageThumbnailSizes...)
+	sort.Ints(sizes)
+	for i, size := range sizes {
+		if size <= 0 || size >= longest || i > 0 && size == sizes[i-1] {
+			continue
+		}
+		w, h := fit(width, height, size)
+		specs = append(specs, variantSpec{
+			name:        fmt.Sprintf("thumb_%d", size),
+			suffix:      fmt.Sprintf("_%d%s", size, thumbExt),
+			contentType: thumbType,
+			width:       w,
+			height:      h,
+		})
+		if webp {
+			specs = append(specs, variantSpec{
+				name:        fmt.Sprintf("thumb_%d_webp", size),
+				suffix:      fmt.Sprintf("_%d.webp", size),
+				contentType: "image/webp",
+				width:       w,
+				height:      h,
+			})
+		}
+	}
+	return specs
+}
+
+// 按比例缩放使最长边为 size
+func fit(width, height, size int) (int, int) {
+	if width >= height {
+		return size, max1(height * size / width)
+	}
+	return max1(width * size / height), size
+}
+
+func max1(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+func resize(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() == width && bounds.Dy() == height {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encodeImage(w io.Writer, img image.Image, contentType string) error {
+	switch contentType {
+	case "image/jpeg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		return png.Encode(w, img)
+	case "image/webp":
+		return encodeWebP(w, img)
+	}
+	return fmt.Errorf("upload: unsupported image content type %s", contentType)
+}
+
+// 按 EXIF 方向(2-8)翻转或旋转图片
+func orient(img image.Image, orientation int) image.Image {
+	src := toRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := x, y
+			switch orientation {
+			case 2: // 水平翻转
+				dx = w - 1 - x
+			case 3: // 旋转 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dy = h - 1 - y
+			case 5: // 沿左上至右下的对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90°
+				dx, dy = h-1-y, x
+			case 7: // 沿右上至左下的对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转 90°
+				dx, dy = y, w-1-x
+			}
+			si := y*src.Stride + x*4
+			di := dy*dst.Stride + dx*4
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,112 @@
+package upload
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"demo/ch02/global"
+	"demo/ch02/pkg/setting"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		width, height, size int
+		wantW, wantH        int
+	}{
+		{width: 800, height: 400, size: 200, wantW: 200, wantH: 100},
+		{width: 400, height: 800, size: 200, wantW: 100, wantH: 200},
+		{width: 500, height: 500, size: 100, wantW: 100, wantH: 100},
+		{width: 1000, height: 1, size: 100, wantW: 100, wantH: 1},
+	}
+	for _, tt := range tests {
+		if w, h := fit(tt.width, tt.height, tt.size); w != tt.wantW || h != tt.wantH {
+			t.Errorf("fit(%d, %d, %d) = %d, %d, want %d, %d", tt.width, tt.height, tt.size, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestImageVariants(t *testing.T) {
+	oldSettings := global.LoadSettings()
+	defer global.StoreSettings(oldSettings)
+	global.UpdateSettings(func(s *global.Settings) {
+		s.App = &setting.AppSettingS{UploadImageThumbnailSizes: []int{200, 100, 100, 0, 800}}
+	})
+
+	jpegFormat := fileFormat{contentType: "image/jpeg", ext: ".jpg"}
+	pngFormat := fileFormat{contentType: "image/png", ext: ".png"}
+	tests := []struct {
+		name   string
+		format fileFormat
+		webp   bool
+		width  int
+		height int
+		want   []variantSpec
+	}{
+		{name: "jpeg", format: jpegFormat, width: 800, height: 400, want: []variantSpec{
+			{name: "thumb_100", suffix: "_100.jpg", contentType: "image/jpeg", width: 100, height: 50},
+			{name: "thumb_200", suffix: "_200.jpg", contentType: "image/jpeg", width: 200, height: 100},
+		}},
+		{name: "png smaller than thumbnails", format: pngFormat, width: 90, height: 180, want: []variantSpec{
+			{name: "thumb_100", suffix: "_100.png", contentType: "image/png", width: 50, height: 100},
+		}},
+		{name: "webp", format: pngFormat, webp: true, width: 150, height: 100, want: []variantSpec{
+			{name: "webp", suffix: ".webp", contentType: "image/webp", width: 150, height: 100},
+			{name: "thumb_100", suffix: "_100.png", contentType: "image/png", width: 100, height: 66},
+			{name: "thumb_100_webp", suffix: "_100.webp", contentType: "image/webp", width: 100, height: 66},
+		}},
+	}
+	for _, tt := range tests {
+		if tt.webp && !webpSupported {
+			continue
+		}
+		global.AppSetting().UploadImageWebP = tt.webp
+		got := imageVariants(tt.format, tt.width, tt.height)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: imageVariants = %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: variant %d = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2x3 的图片，像素的红色分量为其编号
+	//   0 1
+	//   2 3
+	//   4 5
+	src := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	for i := 0; i < 6; i++ {
+		src.Set(i%2, i/2, color.RGBA{R: uint8(i), A: 255})
+	}
+	tests := []struct {
+		orientation int
+		want        [][]uint8
+	}{
+		{orientation: 2, want: [][]uint8{{1, 0}, {3, 2}, {5, 4}}},
+		{orientation: 3, want: [][]uint8{{5, 4}, {3, 2}, {1, 0}}},
+		{orientation: 4, want: [][]uint8{{4, 5}, {2, 3}, {0, 1}}},
+		{orientation: 5, want: [][]uint8{{0, 2, 4}, {1, 3, 5}}},
+		{orientation: 6, want: [][]uint8{{4, 2, 0}, {5, 3, 1}}},
+		{orientation: 7, want: [][]uint8{{5, 3, 1}, {4, 2, 0}}},
+		{orientation: 8, want: [][]uint8{{1, 3, 5}, {0, 2, 4}}},
+	}
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation).(*image.RGBA)
+		if dst.Rect.Dy() != len(tt.want) || dst.Rect.Dx() != len(tt.want[0]) {
+			t.Errorf("orientation %d: size = %v", tt.orientation, dst.Rect.Size())
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if got := dst.RGBAAt(x, y).R; got != want {
+					t.Errorf("orientation %d: pixel (%d, %d) = %d, want %d", tt.orientation, x, y, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformedImage = errors.New("malformed image")
+
+// 去除图片中的 EXIF、XMP 与文本等元数据，仅删除元数据所在的段或块，不重新编码图片
+// 返回 JPEG 的 EXIF 中记录的方向(1-8)，没有记录时为 1
+func stripMetadata(contentType string, data []byte) ([]byte, int, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		data, err := stripPNG(data)
+		return data, 1, err
+	case "image/webp":
+		data, err := stripWebP(data)
+		return data, 1, err
+	}
+	return data, 1, nil
+}
+
+// 删除 APP1(EXIF、XMP)、APP13(IPTC) 与注释段，SOS 之后的图像数据原样保留
+func stripJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, errMalformedImage
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	orientation := 1
+	for i := 2; ; {
+		if i+1 >= len(data) || data[i] != 0xFF {
+			return nil, 0, errMalformedImage
+		}
+		marker := data[i+1]
+		// 标记前可以有多个 0xFF 填充字节
+		if marker == 0xFF {
+			i++
+			continue
+		}
+		// 没有长度字段的标记
+		if marker == 0x01 || marker >= 0xD0 && marker <= 0xD7 {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+		if marker == 0xD9 || marker == 0xDA {
+			return append(out, data[i:]...), orientation, nil
+		}
+		if i+4 > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end < i+4 || end > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		switch marker {
+		case 0xE1:
+			if o := exifOrientation(data[i+4 : end]); o != 0 {
+				orientation = o
+			}
+		case 0xED, 0xFE:
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+}
+
+// 读取 EXIF 中 IFD0 的 Orientation(0x0112)，不是 EXIF 或没有记录时返回 0
+func exifOrientation(b []byte) int {
+	if !bytes.HasPrefix(b, []byte("Exif\x00\x00")) {
+		return 0
+	}
+	tiff := b[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for k := 0; k < count; k++ {
+		entry := offset + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+			return o
+		}
+		return 0
+	}
+	return 0
+}
+
+// PNG 中保存元数据的块
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// 删除元数据块，IEND 之后的数据一并删除
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, errMalformedImage
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, signature...)
+	for i := len(signature); ; {
+		if i+12 > len(data) {
+			return nil, errMalformedImage
+		}
+		// 块由长度、类型、数据与 CRC 组成，CRC 仅覆盖块自身，删除整个块不影响其他块
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end < i+12 || end > len(data) {
+			return nil, errMalformedImage
+		}
+		chunkType := string(data[i+4 : i+8])
+		if !pngMetadataChunks[chunkType] {
+			out = append(out, data[i:end]...)
+		}
+		if chunkType == "IEND" {
+			return out, nil
+		}
+		i = end
+	}
+}
+
+// 删除 EXIF 与 XMP 块，并清除 VP8X 中对应的标志位
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformedImage
+	}
+	end := 8 + int(binary.LittleEndian.Uint32(data[4:]))
+	if end < 12 || end > len(data) {
+		return nil, errMalformedImage
+	}
+	out := make([]byte, 0, end)
+	out = append(out, data[:12]...)
+	for i := 12; i < end; {
+		if i+8 > end {
+			return nil, errMalformedImage
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		// 块的数据长度为奇数时补齐一个字节
+		next := i + 8 + size + size&1
+		if next > end {
+			if i+8+size != end {
+				return nil, errMalformedImage
+			}
+			next = end
+		}
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:next]...)
+			if size > 0 {
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:next]...)
+		}
+		i = next
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
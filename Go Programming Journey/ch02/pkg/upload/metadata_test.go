@@ -0,0 +1,224 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// 构造只包含 Orientation 一项的 EXIF 数据
+func exifData(order binary.ByteOrder, orientation uint16) []byte {
+	b := []byte("Exif\x00\x00")
+	if order == binary.LittleEndian {
+		b = append(b, "II"...)
+	} else {
+		b = append(b, "MM"...)
+	}
+	tiff := make([]byte, 2+4+2+12+4)
+	order.PutUint16(tiff[0:], 42)
+	order.PutUint32(tiff[2:], 8)
+	order.PutUint16(tiff[6:], 1)
+	order.PutUint16(tiff[8:], 0x0112)
+	order.PutUint16(tiff[10:], 3) // SHORT
+	order.PutUint32(tiff[12:], 1)
+	order.PutUint16(tiff[16:], orientation)
+	return append(b, tiff...)
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	b := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(b[2:], uint16(len(payload)+2))
+	return append(b, payload...)
+}
+
+func testImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 10)
+	}
+	return img
+}
+
+func TestExifOrientation(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "little endian", data: exifData(binary.LittleEndian, 6), want: 6},
+		{name: "big endian", data: exifData(binary.BigEndian, 8), want: 8},
+		{name: "out of range", data: exifData(binary.LittleEndian, 9), want: 0},
+		{name: "xmp", data: []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"), want: 0},
+		{name: "truncated", data: exifData(binary.BigEndian, 3)[:20], want: 0},
+		{name: "bad byte order", data: append([]byte("Exif\x00\x00XX"), make([]byte, 20)...), want: 0},
+	}
+	for _, tt := range tests {
+		if got := exifOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: exifOrientation = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStripJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(), nil); err != nil {
+		t.Fatalf("jpeg.Encode err: %v", err)
+	}
+	clean := buf.Bytes()
+
+	var withMetadata []byte
+	withMetadata = append(withMetadata, clean[:2]...)
+	// 标记前的填充字节
+	withMetadata = append(withMetadata, 0xFF)
+	withMetadata = append(withMetadata, jpegSegment(0xE1, exifData(binary.BigEndian, 6))...)
+	withMetadata = append(withMetadata, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x/>"))...)
+	withMetadata = append(withMetadata, jpegSegment(0xED, []byte("Photoshop 3.0\x00"))...)
+	withMetadata = append(withMetadata, jpegSegment(0xFE, []byte("comment"))...)
+	withMetadata = append(withMetadata, clean[2:]...)
+
+	tests := []struct {
+		name            string
+		data            []byte
+		want            []byte
+		wantOrientation int
+		wantErr         bool
+	}{
+		{name: "clean", data: clean, want: clean, wantOrientation: 1},
+		{name: "metadata", data: withMetadata, want: clean, wantOrientation: 6},
+		{name: "not jpeg", data: []byte("GIF89a"), wantErr: true},
+		{name: "truncated segment", data: append([]byte{0xFF, 0xD8}, jpegSegment(0xE0, make([]byte, 10))[:8]...), wantErr: true},
+		{name: "missing marker", data: []byte{0xFF, 0xD8, 0x00, 0x00}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, orientation, err := stripJPEG(tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: stripJPEG err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !bytes.Equal(got, tt.want) || orientation != tt.wantOrientation {
+			t.Errorf("%s: stripJPEG = %d bytes, orientation %d, want %d bytes, orientation %d",
+				tt.name, len(got), orientation, len(tt.want), tt.wantOrientation)
+		}
+	}
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	b := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(b, uint32(len(data)))
+	b = append(b, chunkType...)
+	b = append(b, data...)
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(b[4:]))
+	return append(b, crc...)
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage()); err != nil {
+		t.Fatalf("png.Encode err: %v", err)
+	}
+	clean := buf.Bytes()
+	// 签名与 IHDR 共 33 个字节
+	var withMetadata []byte
+	withMetadata = append(withMetadata, clean[:33]...)
+	withMetadata = append(withMetadata, pngChunk("tEXt", []byte("Author\x00someone"))...)
+	withMetadata = append(withMetadata, pngChunk("eXIf", exifData(binary.BigEndian, 6)[6:])...)
+	withMetadata = append(withMetadata, clean[33:]...)
+	withMetadata = append(withMetadata, "trailing data"...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{name: "clean", data: clean, want: clean},
+		{name: "metadata", data: withMetadata, want: clean},
+		{name: "not png", data: []byte("\x89PNX\r\n\x1a\n"), wantErr: true},
+		{name: "missing IEND", data: clean[:len(clean)-12], wantErr: true},
+		{name: "chunk overflow", data: append(append([]byte(nil), clean[:8]...), 0xFF, 0xFF, 0xFF, 0xF0, 'I', 'D', 'A', 'T'), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := stripPNG(tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: stripPNG err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: stripPNG = %d bytes, want %d bytes", tt.name, len(got), len(tt.want))
+		}
+	}
+}
+
+func webpChunk(chunkType string, data []byte) []byte {
+	b := append([]byte(chunkType), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(data)))
+	b = append(b, data...)
+	if len(data)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func webpFile(chunks ...[]byte) []byte {
+	b := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, chunk := range chunks {
+		b = append(b, chunk...)
+	}
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)-8))
+	return b
+}
+
+func TestStripWebP(t *testing.T) {
+	// VP8X 的标志位: 0x10 alpha、0x08 EXIF、0x04 XMP
+	vp8x := func(flags byte) []byte {
+		return webpChunk("VP8X", []byte{flags, 0, 0, 0, 2, 0, 0, 1, 0, 0})
+	}
+	vp8l := webpChunk("VP8L", []byte{0x2F, 0x01, 0x02})
+	withMetadata := webpFile(vp8x(0x10|0x08|0x04), vp8l, webpChunk("EXIF", exifData(binary.LittleEndian, 6)[6:]), webpChunk("XMP ", []byte("<x/>")))
+	// 最后一个块缺少补齐字节
+	unpadded := webpFile(vp8x(0x10), vp8l)
+	unpadded = unpadded[:len(unpadded)-1]
+	binary.LittleEndian.PutUint32(unpadded[4:], uint32(len(unpadded)-8))
+	overflow := webpFile(vp8x(0))
+	binary.LittleEndian.PutUint32(overflow[4:], uint32(len(overflow)))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{name: "metadata", data: withMetadata, want: webpFile(vp8x(0x10), vp8l)},
+		{name: "simple", data: webpFile(vp8l), want: webpFile(vp8l)},
+		{name: "unpadded last chunk", data: unpadded, want: unpadded},
+		{name: "not webp", data: []byte("RIFF\x04\x00\x00\x00WAVE"), wantErr: true},
+		{name: "riff overflow", data: overflow, wantErr: true},
+		{name: "truncated chunk header", data: webpFile([]byte("VP8")), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := stripWebP(tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: stripWebP err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: stripWebP = %x, want %x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStripMetadataOtherTypes(t *testing.T) {
+	data := []byte("GIF89a")
+	got, orientation, err := stripMetadata("image/gif", data)
+	if err != nil || !bytes.Equal(got, data) || orientation != 1 {
+		t.Errorf("stripMetadata(gif) = %q, %d, %v", got, orientation, err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build cgo
+
+package upload
+
+import (
+	"image"
+	"io"
+
+	"github.com/chai2010/webp"
+)
+
+// 启用 cgo 时使用 libwebp 编码 WebP
+const webpSupported = true
+
+func encodeWebP(w io.Writer, img image.Image) error {
+	return webp.Encode(w, img, &webp.Options{Quality: webpQuality})
+}
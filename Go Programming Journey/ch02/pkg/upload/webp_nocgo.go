@@ -0,0 +1,16 @@
+//go:build !cgo
+
+package upload
+
+import (
+	"errors"
+	"image"
+	"io"
+)
+
+// golang.org/x/image/webp 仅支持解码，未启用 cgo 时不生成 WebP 格式的版本
+const webpSupported = false
+
+func encodeWebP(w io.Writer, img image.Image) error {
+	return errors.New("upload: webp encoding requires cgo")
+}
@@ -1,3 +1,4 @@
+-- 表结构已纳入 migrations 目录下的数据库迁移管理，可通过 -migrate=up 执行
 CREATE TABLE `blog_article` (
                                 `id` int unsigned NOT NULL AUTO_INCREMENT,
                                 `title` varchar(100) DEFAULT '' COMMENT '文章标题',